@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+	"github.com/spf13/viper"
+)
+
+const defaultMongoServerVersion = armcosmos.ServerVersionSeven0
+
+// loadAccountKindSettings reads Kind (GlobalDocumentDB for NoSQL, the default, or MongoDB) and, for MongoDB,
+// MongoServerVersion. The kind is fixed when the account is created.
+func loadAccountKindSettings() error {
+	accountKind = armcosmos.DatabaseAccountKindGlobalDocumentDB
+	switch raw := strings.TrimSpace(viper.GetString("Kind")); {
+	case raw == "" || strings.EqualFold(raw, string(armcosmos.DatabaseAccountKindGlobalDocumentDB)):
+	case strings.EqualFold(raw, string(armcosmos.DatabaseAccountKindMongoDB)):
+		accountKind = armcosmos.DatabaseAccountKindMongoDB
+	default:
+		return fmt.Errorf("Kind must be GlobalDocumentDB or MongoDB (got %q)", raw)
+	}
+
+	raw := strings.TrimSpace(viper.GetString("MongoServerVersion"))
+	if accountKind != armcosmos.DatabaseAccountKindMongoDB {
+		if raw != "" {
+			return fmt.Errorf("MongoServerVersion only applies when Kind is MongoDB")
+		}
+		return nil
+	}
+	mongoServerVersion = defaultMongoServerVersion
+	if raw != "" {
+		mongoServerVersion = ""
+		for _, v := range armcosmos.PossibleServerVersionValues() {
+			if raw == string(v) {
+				mongoServerVersion = v
+			}
+		}
+		if mongoServerVersion == "" {
+			return fmt.Errorf("MongoServerVersion must be one of %v (got %q)", armcosmos.PossibleServerVersionValues(), raw)
+		}
+	}
+	return nil
+}
+
+// isMongoAccount reports whether the sample manages an API for MongoDB account rather than a NoSQL one.
+func isMongoAccount() bool {
+	return accountKind == armcosmos.DatabaseAccountKindMongoDB
+}
+
+// applyAccountKind sets the kind and the API-specific properties and capabilities on the account parameters.
+func applyAccountKind(params *armcosmos.DatabaseAccountCreateUpdateParameters) {
+	params.Kind = to.Ptr(accountKind)
+	if !isMongoAccount() {
+		params.Properties.Capabilities = []*armcosmos.Capability{{Name: to.Ptr("EnableNoSQLVectorSearch")}}
+		params.Properties.DisableLocalAuth = to.Ptr(true)
+		return
+	}
+
+	params.Properties.APIProperties = &armcosmos.APIProperties{ServerVersion: to.Ptr(mongoServerVersion)}
+	// MongoDB drivers authenticate with the account keys (connection string), so local auth stays enabled.
+	params.Properties.DisableLocalAuth = to.Ptr(false)
+}
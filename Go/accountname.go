@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+const (
+	accountNameSuffixLength    = 6
+	accountNameMaxLength       = 44
+	accountNameGenerateRetries = 5
+)
+
+var accountNamePrefixPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// validateAccountNamePrefix checks that prefix + "-" + suffix will be a valid account name (lower-case letters,
+// digits, and hyphens; 3-44 characters).
+func validateAccountNamePrefix(prefix string) error {
+	if !accountNamePrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("AccountNamePrefix may only contain lower-case letters, digits, and hyphens, and must start with a letter or digit (got %q)", prefix)
+	}
+	if maxPrefix := accountNameMaxLength - accountNameSuffixLength - 1; len(prefix) > maxPrefix {
+		return fmt.Errorf("AccountNamePrefix must be at most %d characters (got %d)", maxPrefix, len(prefix))
+	}
+	return nil
+}
+
+// generateUniqueAccountName picks AccountNamePrefix plus a random suffix that no existing account uses, and makes it
+// the account name for the run. Account names are global DNS names, so availability is checked with CheckNameExists.
+func generateUniqueAccountName(ctx context.Context) {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+
+	for range accountNameGenerateRetries {
+		candidate := accountNamePrefix + "-" + randomAccountNameSuffix()
+		resp, err := accountClient.CheckNameExists(ctx, candidate, nil)
+		if err != nil {
+			log.Fatalf("failed to check account name availability: %v", err)
+		}
+		if resp.Success {
+			log.Printf("Generated account name %s is taken; trying another", candidate)
+			continue
+		}
+
+		accountName = candidate
+		applyAccountNameDefaults()
+		fmt.Printf("Generated account name: %s (set AccountName to this value to reuse the account in later runs)\n", accountName)
+		return
+	}
+	log.Fatalf("failed to generate an available account name with prefix %q after %d attempts", accountNamePrefix, accountNameGenerateRetries)
+}
+
+// randomAccountNameSuffix returns random lower-case letters and digits.
+func randomAccountNameSuffix() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, accountNameSuffixLength)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// runActivityLogCommand lists recent management operations on the account and its child resources.
+func runActivityLogCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("activity-log")
+	window := fs.Duration("window", 24*time.Hour, "How far back to read the activity log (max 90 days)")
+	status := fs.String("status", "", "Only show events with this status (for example Succeeded, Failed, Started)")
+	_ = fs.Parse(args)
+
+	activityLogsClient, err := armmonitor.NewActivityLogsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create activity logs client: %v", err)
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-*window)
+	// The activity log only supports one resource filter at a time. Filtering by resource group and then by
+	// resource ID prefix keeps operations on child resources (databases, containers, role assignments).
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and resourceGroupName eq '%s'", start.Format(time.RFC3339), end.Format(time.RFC3339), resourceGroupName)
+	options := &armmonitor.ActivityLogsClientListOptions{
+		Select: to.Ptr("eventTimestamp,caller,operationName,status,resourceId,correlationId"),
+	}
+
+	// Events for the account or its child resources; a sibling account whose name starts with this one is another
+	// resource.
+	accountID := strings.ToLower(getAssignableScope(Account))
+	events := make([]*armmonitor.EventData, 0)
+	pager := activityLogsClient.NewListPager(filter, options)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list activity log events: %v", err)
+		}
+		for _, e := range page.Value {
+			if e == nil || e.ResourceID == nil {
+				continue
+			}
+			if id := strings.ToLower(*e.ResourceID); id != accountID && !strings.HasPrefix(id, accountID+"/") {
+				continue
+			}
+			if *status != "" && !strings.EqualFold(localizable(e.Status), *status) {
+				continue
+			}
+			events = append(events, e)
+		}
+	}
+
+	if len(events) == 0 {
+		fmt.Printf("No activity log events for account %s in the last %s.\n", accountName, *window)
+		return
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].EventTimestamp == nil || events[j].EventTimestamp == nil {
+			return events[j].EventTimestamp != nil
+		}
+		return events[i].EventTimestamp.Before(*events[j].EventTimestamp)
+	})
+
+	fmt.Printf("Activity log for account %s over the last %s\n", accountName, *window)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME (UTC)\tCALLER\tOPERATION\tSTATUS\tRESOURCE")
+	for _, e := range events {
+		timestamp := ""
+		if e.EventTimestamp != nil {
+			timestamp = e.EventTimestamp.UTC().Format(time.DateTime)
+		}
+		caller := ""
+		if e.Caller != nil {
+			caller = *e.Caller
+		}
+		resource := strings.TrimPrefix(strings.ToLower(*e.ResourceID), accountID)
+		if resource == "" {
+			resource = accountName
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", timestamp, caller, localizable(e.OperationName), localizable(e.Status), resource)
+	}
+	_ = tw.Flush()
+}
+
+// localizable returns the localized value of an Azure Monitor string, falling back to its invariant value.
+func localizable(s *armmonitor.LocalizableString) string {
+	if s == nil {
+		return ""
+	}
+	if s.LocalizedValue != nil && *s.LocalizedValue != "" {
+		return *s.LocalizedValue
+	}
+	if s.Value != nil {
+		return *s.Value
+	}
+	return ""
+}
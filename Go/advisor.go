@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/advisor/armadvisor"
+)
+
+// runAdvisorCommand prints Azure Advisor cost and performance recommendations for the account.
+func runAdvisorCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("advisor")
+	_ = fs.Parse(args)
+
+	if err := printAdvisorRecommendations(ctx); err != nil {
+		log.Fatalf("failed to list Advisor recommendations: %v", err)
+	}
+}
+
+// printAdvisorRecommendationsBestEffort prints Advisor recommendations without failing the run.
+func printAdvisorRecommendationsBestEffort(ctx context.Context) {
+	if err := printAdvisorRecommendations(ctx); err != nil {
+		log.Printf("Skipping Advisor recommendations: %v", err)
+	}
+}
+
+// printAdvisorRecommendations lists cost and performance recommendations that target the account or its child resources.
+func printAdvisorRecommendations(ctx context.Context) error {
+	recommendationsClient, err := armadvisor.NewRecommendationsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create Advisor recommendations client: %w", err)
+	}
+
+	accountID := strings.ToLower(getAssignableScope(Account))
+	filter := fmt.Sprintf("ResourceGroup eq '%s'", resourceGroupName)
+	pager := recommendationsClient.NewListPager(&armadvisor.RecommendationsClientListOptions{Filter: to.Ptr(filter)})
+
+	count := 0
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list recommendations: %w", err)
+		}
+
+		for _, r := range page.Value {
+			if r == nil || r.Properties == nil || r.Properties.Category == nil {
+				continue
+			}
+			p := r.Properties
+			if *p.Category != armadvisor.CategoryCost && *p.Category != armadvisor.CategoryPerformance {
+				continue
+			}
+			if p.ResourceMetadata == nil || p.ResourceMetadata.ResourceID == nil {
+				continue
+			}
+			// The account or one of its child resources, not a sibling account whose name starts with this one.
+			if id := strings.ToLower(*p.ResourceMetadata.ResourceID); id != accountID && !strings.HasPrefix(id, accountID+"/") {
+				continue
+			}
+
+			count++
+			impact := ""
+			if p.Impact != nil {
+				impact = string(*p.Impact)
+			}
+			fmt.Printf("[%s/%s] %s\n", *p.Category, impact, advisorText(p.ShortDescription, true))
+			if solution := advisorText(p.ShortDescription, false); solution != "" {
+				fmt.Printf("  Solution: %s\n", solution)
+			}
+			if p.ImpactedValue != nil {
+				fmt.Printf("  Resource: %s\n", *p.ImpactedValue)
+			}
+			if p.PotentialBenefits != nil {
+				fmt.Printf("  Benefit:  %s\n", *p.PotentialBenefits)
+			}
+			if p.LearnMoreLink != nil {
+				fmt.Printf("  Learn more: %s\n", *p.LearnMoreLink)
+			}
+		}
+	}
+
+	if count == 0 {
+		fmt.Printf("No Advisor cost or performance recommendations for account %s (new accounts can take up to 24 hours to be assessed).\n", accountName)
+	}
+	return nil
+}
+
+// advisorText returns the problem (or solution) text of a recommendation's short description.
+func advisorText(d *armadvisor.ShortDescription, problem bool) string {
+	if d == nil {
+		return ""
+	}
+	if problem && d.Problem != nil {
+		return *d.Problem
+	}
+	if !problem && d.Solution != nil {
+		return *d.Solution
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// createOrUpdateThrottlingAlert creates an action group and a metric alert that fires when the account returns too many 429s.
+func createOrUpdateThrottlingAlert(ctx context.Context) {
+	actionGroupID := createOrUpdateAlertActionGroup(ctx)
+
+	metricAlertsClient, err := armmonitor.NewMetricAlertsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create metric alerts client: %v", err)
+	}
+
+	ruleName := accountName + "-throttling"
+	properties := armmonitor.MetricAlertResource{
+		Location: to.Ptr("global"),
+		Tags:     sampleTags(ctx),
+		Properties: &armmonitor.MetricAlertProperties{
+			Description:         to.Ptr(fmt.Sprintf("Fires when %s returns more than %d throttled (429) requests in 5 minutes.", accountName, throttleAlertThreshold)),
+			Enabled:             to.Ptr(true),
+			Severity:            to.Ptr[int32](2),
+			Scopes:              to.SliceOfPtrs(getAssignableScope(Account)),
+			EvaluationFrequency: to.Ptr("PT1M"),
+			WindowSize:          to.Ptr("PT5M"),
+			AutoMitigate:        to.Ptr(true),
+			Criteria: &armmonitor.MetricAlertSingleResourceMultipleMetricCriteria{
+				ODataType: to.Ptr(armmonitor.OdatatypeMicrosoftAzureMonitorSingleResourceMultipleMetricCriteria),
+				AllOf: []*armmonitor.MetricCriteria{{
+					Name:            to.Ptr("ThrottledRequests"),
+					CriterionType:   to.Ptr(armmonitor.CriterionTypeStaticThresholdCriterion),
+					MetricNamespace: to.Ptr("Microsoft.DocumentDB/databaseAccounts"),
+					MetricName:      to.Ptr("TotalRequests"),
+					TimeAggregation: to.Ptr(armmonitor.AggregationTypeEnumCount),
+					Operator:        to.Ptr(armmonitor.OperatorGreaterThan),
+					Threshold:       to.Ptr(float64(throttleAlertThreshold)),
+					Dimensions: []*armmonitor.MetricDimension{{
+						Name:     to.Ptr("StatusCode"),
+						Operator: to.Ptr("Include"),
+						Values:   to.SliceOfPtrs("429"),
+					}},
+				}},
+			},
+			Actions: []*armmonitor.MetricAlertAction{{ActionGroupID: to.Ptr(actionGroupID)}},
+		},
+	}
+
+	resp, err := metricAlertsClient.CreateOrUpdate(ctx, resourceGroupName, ruleName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update throttling metric alert: %v", err)
+	}
+
+	recordResource("Microsoft.Insights/metricAlerts", resp.ID)
+	fmt.Printf("Created/updated Metric Alert: %s\n", *resp.ID)
+}
+
+// createOrUpdateAlertActionGroup creates or updates the action group notified by the sample's alerts and returns its resource ID.
+func createOrUpdateAlertActionGroup(ctx context.Context) string {
+	actionGroupsClient, err := armmonitor.NewActionGroupsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create action groups client: %v", err)
+	}
+
+	actionGroup := &armmonitor.ActionGroup{
+		Enabled: to.Ptr(true),
+		// Short names are limited to 12 characters and appear in SMS/email notifications.
+		GroupShortName: to.Ptr("cosmosalerts"),
+	}
+	if alertEmailAddress != "" {
+		actionGroup.EmailReceivers = []*armmonitor.EmailReceiver{{
+			Name:                 to.Ptr("owner"),
+			EmailAddress:         to.Ptr(alertEmailAddress),
+			UseCommonAlertSchema: to.Ptr(true),
+		}}
+	} else {
+		fmt.Println("AlertEmailAddress is not configured; the action group is created without receivers.")
+	}
+
+	actionGroupName := accountName + "-alerts"
+	resp, err := actionGroupsClient.CreateOrUpdate(ctx, resourceGroupName, actionGroupName, armmonitor.ActionGroupResource{
+		Location:   to.Ptr("Global"),
+		Tags:       sampleTags(ctx),
+		Properties: actionGroup,
+	}, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update action group: %v", err)
+	}
+
+	recordResource("Microsoft.Insights/actionGroups", resp.ID)
+	fmt.Printf("Created/updated Action Group: %s\n", *resp.ID)
+	return *resp.ID
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	armRestModuleName    = "github.com/AzureCosmosDB/management-sdk-samples/Go"
+	armRestModuleVersion = "v0.1.0"
+)
+
+// armRestClient calls Cosmos DB resource provider APIs that the armcosmos module doesn't expose yet.
+// It uses the same ARM pipeline (authentication, retries, logging) as the generated clients.
+type armRestClient struct {
+	internal   *arm.Client
+	apiVersion string
+}
+
+// newARMRestClient creates a client that sends requests with the given api-version.
+func newARMRestClient(apiVersion string) (*armRestClient, error) {
+	client, err := arm.NewClient(armRestModuleName, armRestModuleVersion, credential, armClientOptions())
+	if err != nil {
+		return nil, err
+	}
+	return &armRestClient{internal: client, apiVersion: apiVersion}, nil
+}
+
+// do sends a request to an ARM resource path and decodes the JSON response into out (when non-nil).
+// Responses with a status code other than okStatus are returned as *azcore.ResponseError.
+func (c *armRestClient) do(ctx context.Context, method string, path string, body any, out any, okStatus ...int) error {
+	resp, err := c.send(ctx, method, path, body, okStatus...)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		runtime.Drain(resp)
+		return nil
+	}
+	return runtime.UnmarshalAsJSON(resp, out)
+}
+
+// send sends a request to an ARM resource path, or to an absolute URL such as a list's nextLink, and returns the raw
+// response when its status code is one of okStatus (200 by default). Like a generated client method, each call gets a
+// span when tracing is on.
+func (c *armRestClient) send(ctx context.Context, method string, path string, body any, okStatus ...int) (resp *http.Response, err error) {
+	ctx, endSpan := runtime.StartSpan(ctx, "armRestClient."+method, c.internal.Tracer(), nil)
+	defer func() { endSpan(err) }()
+	endpoint := path
+	if !strings.HasPrefix(path, "https://") {
+		endpoint = runtime.JoinPaths(c.internal.Endpoint(), path)
+	}
+	req, err := runtime.NewRequest(ctx, method, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	// A nextLink already carries its api-version and skip token; leave its query as is.
+	if query := req.Raw().URL.Query(); !query.Has("api-version") {
+		query.Set("api-version", c.apiVersion)
+		req.Raw().URL.RawQuery = query.Encode()
+	}
+	req.Raw().Header["Accept"] = []string{"application/json"}
+	if body != nil {
+		if err := runtime.MarshalAsJSON(req, body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	resp, err = c.internal.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(okStatus) == 0 {
+		okStatus = []int{http.StatusOK}
+	}
+	if !runtime.HasStatusCode(resp, okStatus...) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	return resp, nil
+}
+
+// beginARMOperation starts a long-running ARM operation and returns a poller for it, like the generated Begin* methods.
+func beginARMOperation[T any](ctx context.Context, c *armRestClient, method string, path string, body any) (*runtime.Poller[T], error) {
+	resp, err := c.send(ctx, method, path, body, http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewPoller[T](resp, c.internal.Pipeline(), nil)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runBackupInfoCommand prints the restorable window of each SQL container: the earliest time (the account's oldest
+// restorable time, or the container's creation if later) and the latest restorable timestamp the service reports for
+// the region, so a point-in-time restore can pick a timestamp that is known to work.
+func runBackupInfoCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("backup-info")
+	region := fs.String("region", "", "Region whose backups to query (default: the account's write region)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: backup-info [-region <region>] [<database>/<container> ...]")
+		fmt.Fprintln(fs.Output(), "Shows the earliest and latest restorable time of each container (default: the configured containers). Requires continuous backup.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	type target struct{ database, container string }
+	targets := make([]target, 0)
+	for _, arg := range fs.Args() {
+		database, container, ok := strings.Cut(arg, "/")
+		if !ok || database == "" || container == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		targets = append(targets, target{database, container})
+	}
+	if len(targets) == 0 {
+		for _, d := range databases {
+			for _, c := range d.Containers {
+				targets = append(targets, target{d.Name, c.Name})
+			}
+		}
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	if account.Properties == nil || account.Properties.InstanceID == nil {
+		log.Fatalf("Account %s has no instance ID", accountName)
+	}
+	if _, ok := account.Properties.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy); !ok {
+		log.Fatalf("Account %s uses periodic backup; restorable times are only available with continuous backup.", accountName)
+	}
+	if *region == "" {
+		*region = writeRegion(account.DatabaseAccountGetResults)
+	}
+
+	oldest, err := getOldestRestorableTime(ctx, *account.Properties.InstanceID)
+	if err != nil {
+		log.Printf("Could not read the account's restorable window: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "CONTAINER\tEARLIEST RESTORABLE\tLATEST RESTORABLE (%s)\n", *region)
+	for _, t := range targets {
+		earliest := oldest
+		if created, err := getContainerCreationTime(ctx, sqlClient, *account.Properties.InstanceID, stringValue(account.Location), t.database, t.container); err != nil {
+			log.Printf("Could not read the creation time of %s/%s: %v", t.database, t.container, err)
+		} else if created != nil && (earliest == nil || created.After(*earliest)) {
+			earliest = created
+		}
+
+		latest, err := getLatestRestorableTime(ctx, sqlClient, t.database, t.container, *region)
+		if err != nil {
+			log.Printf("Could not read the latest restorable time of %s/%s: %v", t.database, t.container, err)
+			latest = "unknown"
+		}
+
+		earliestText := "-"
+		if earliest != nil {
+			earliestText = earliest.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s/%s\t%s\t%s\n", t.database, t.container, earliestText, latest)
+	}
+	_ = tw.Flush()
+}
+
+// getLatestRestorableTime runs the RetrieveContinuousBackupInformation operation for the container in region and
+// returns the latest restorable timestamp as RFC 3339.
+func getLatestRestorableTime(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, database string, container string, region string) (string, error) {
+	poller, err := sqlClient.BeginRetrieveContinuousBackupInformation(ctx, resourceGroupName, accountName, database, container, armcosmos.ContinuousBackupRestoreLocation{Location: to.Ptr(region)}, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return "", err
+	}
+	if resp.ContinuousBackupInformation == nil || resp.ContinuousBackupInformation.LatestRestorableTimestamp == nil {
+		return "-", nil
+	}
+	return formatRestorableTimestamp(*resp.ContinuousBackupInformation.LatestRestorableTimestamp), nil
+}
+
+// formatRestorableTimestamp normalizes the service's timestamp, which may be RFC 3339 or Unix seconds, to RFC 3339.
+func formatRestorableTimestamp(raw string) string {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+	}
+	return raw
+}
+
+// getContainerCreationTime returns when the container was (last) created, from the restorable container event feed.
+// A container can't be restored to a time before it existed.
+func getContainerCreationTime(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, instanceID string, accountLocation string, database string, container string) (*time.Time, error) {
+	db, err := sqlClient.GetSQLDatabase(ctx, resourceGroupName, accountName, database, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	if db.Properties == nil || db.Properties.Resource == nil || db.Properties.Resource.Rid == nil {
+		return nil, nil
+	}
+
+	restorableClient, err := armcosmos.NewRestorableSQLContainersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable sql containers client: %w", err)
+	}
+	var created *time.Time
+	pager := restorableClient.NewListPager(accountLocation, instanceID, &armcosmos.RestorableSQLContainersClientListOptions{RestorableSQLDatabaseRid: db.Properties.Resource.Rid})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list container events: %w", err)
+		}
+		for _, event := range page.Value {
+			if event == nil || event.Properties == nil || event.Properties.Resource == nil {
+				continue
+			}
+			r := event.Properties.Resource
+			if stringValue(r.OwnerID) != container || r.OperationType == nil || *r.OperationType != armcosmos.OperationTypeCreate {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, stringValue(r.EventTimestamp))
+			if err != nil {
+				continue
+			}
+			if created == nil || t.After(*created) {
+				created = &t
+			}
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/spf13/viper"
+)
+
+var defaultBudgetThresholds = []float64{80, 100}
+
+var (
+	budgetAmount     float64
+	budgetThresholds []float64
+)
+
+// loadBudgetSettings reads BudgetAmount and BudgetThresholds. A zero amount disables the budget.
+func loadBudgetSettings() error {
+	budgetAmount = viper.GetFloat64("BudgetAmount")
+	if budgetAmount < 0 {
+		return fmt.Errorf("BudgetAmount must be >= 0 (got %g)", budgetAmount)
+	}
+
+	budgetThresholds = defaultBudgetThresholds
+	if viper.IsSet("BudgetThresholds") {
+		var configured []float64
+		if err := viper.UnmarshalKey("BudgetThresholds", &configured); err != nil {
+			return fmt.Errorf("BudgetThresholds must be a list of percentages: %w", err)
+		}
+		// A budget has at most 5 notifications, each at a different threshold.
+		if len(configured) == 0 || len(configured) > 5 {
+			return fmt.Errorf("BudgetThresholds must have 1 to 5 entries (got %d)", len(configured))
+		}
+		seen := map[float64]bool{}
+		for _, t := range configured {
+			if t <= 0 || t > 1000 {
+				return fmt.Errorf("BudgetThresholds entries must be between 0 and 1000 percent (got %g)", t)
+			}
+			if seen[t] {
+				return fmt.Errorf("BudgetThresholds has %g more than once", t)
+			}
+			seen[t] = true
+		}
+		sort.Float64s(configured)
+		budgetThresholds = configured
+	}
+	return nil
+}
+
+// createOrUpdateResourceGroupBudget creates or updates a monthly cost budget on the resource group that notifies the
+// alert action group, and AlertEmailAddress, at each of BudgetThresholds percent of BudgetAmount.
+func createOrUpdateResourceGroupBudget(ctx context.Context) {
+	if budgetAmount == 0 {
+		fmt.Println("BudgetAmount is not configured; skipping the resource group budget.")
+		return
+	}
+	actionGroupID := createOrUpdateAlertActionGroup(ctx)
+
+	// Budgets belong to the Microsoft.Consumption resource provider and are created at the resource group scope.
+	client, err := armconsumption.NewBudgetsClient(credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create budgets client: %v", err)
+	}
+	budgetName := accountName + "-budget"
+	scope := getAssignableScope(ResourceGroup)
+
+	// A monthly budget starts on the first day of a month. Keep the start date of an existing budget, which can't
+	// move once the budget has begun.
+	now := time.Now().UTC()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	existing, err := client.Get(ctx, scope, budgetName, nil)
+	var respErr *azcore.ResponseError
+	switch {
+	case err == nil:
+		if p := existing.Properties; p != nil && p.TimePeriod != nil && p.TimePeriod.StartDate != nil {
+			startDate = *p.TimePeriod.StartDate
+		}
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound:
+	default:
+		log.Fatalf("failed to get budget %s: %v", budgetName, err)
+	}
+
+	// Budgets need at least one contact; the action group always exists, with or without receivers.
+	emails := []*string{}
+	if alertEmailAddress != "" {
+		emails = append(emails, to.Ptr(alertEmailAddress))
+	}
+	notifications := map[string]*armconsumption.Notification{}
+	for _, threshold := range budgetThresholds {
+		notifications[fmt.Sprintf("Actual_GreaterThanOrEqualTo_%g_Percent", threshold)] = &armconsumption.Notification{
+			Enabled:       to.Ptr(true),
+			Operator:      to.Ptr(armconsumption.OperatorTypeGreaterThanOrEqualTo),
+			Threshold:     to.Ptr(threshold),
+			ThresholdType: to.Ptr(armconsumption.ThresholdTypeActual),
+			ContactEmails: emails,
+			ContactGroups: []*string{to.Ptr(actionGroupID)},
+		}
+	}
+
+	budget := armconsumption.Budget{Properties: &armconsumption.BudgetProperties{
+		Category:      to.Ptr(armconsumption.CategoryTypeCost),
+		Amount:        to.Ptr(budgetAmount),
+		TimeGrain:     to.Ptr(armconsumption.TimeGrainTypeMonthly),
+		TimePeriod:    &armconsumption.BudgetTimePeriod{StartDate: &startDate},
+		Notifications: notifications,
+	}}
+	resp, err := client.CreateOrUpdate(ctx, scope, budgetName, budget, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update budget %s: %v", budgetName, err)
+	}
+
+	recordResource("Microsoft.Consumption/budgets", resp.ID)
+	fmt.Printf("Created/updated Budget: %s (%g per month, notifying at %v%%)\n", stringValue(resp.ID), budgetAmount, budgetThresholds)
+}
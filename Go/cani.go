@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// sqlDataActions are the Cosmos DB NoSQL data actions a role definition can grant.
+var sqlDataActions = []string{
+	"Microsoft.DocumentDB/databaseAccounts/readMetadata",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/create",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/read",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/replace",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/upsert",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/delete",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeQuery",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/readChangeFeed",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeStoredProcedure",
+	"Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/manageConflicts",
+}
+
+// runCanICommand evaluates the account's SQL role definitions and assignments locally to answer whether a principal
+// may perform a data action at a scope, and explains which assignment grants it (or why none does).
+func runCanICommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("can-i")
+	groups := fs.Bool("groups", true, "Include assignments to the principal's Entra ID groups (reads memberships from Microsoft Graph)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: can-i [-groups=false] (<principal object id> | me) <data action> [<scope>]")
+		fmt.Fprintln(fs.Output(), "Reports whether the principal's SQL role assignments allow the data action at the scope (default /, the account).")
+		fmt.Fprintln(fs.Output(), "The action can be shortened to its last segments, for example items/read or executeQuery; the scope is relative to the account, for example /dbs/orders/colls/items.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	principalID := fs.Arg(0)
+	if strings.EqualFold(principalID, "me") {
+		id, err := getCurrentPrincipalObjectID(ctx)
+		if err != nil {
+			log.Fatalf("failed to get current user principal ID: %v", err)
+		}
+		principalID = id
+	}
+	action, err := resolveSQLDataAction(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	accountID := getAssignableScope(Account)
+	scope := fs.Arg(2)
+	switch {
+	case scope == "" || scope == "/":
+		scope = accountID
+	case strings.HasPrefix(scope, "/dbs/"):
+		scope = accountID + strings.TrimSuffix(scope, "/")
+	case !strings.HasPrefix(strings.ToLower(scope), strings.ToLower(accountID)):
+		log.Fatalf("Invalid scope %q: expected /, /dbs/<database>, /dbs/<database>/colls/<container>, or a resource ID in %s", scope, accountName)
+	}
+
+	// Assignments to any group the principal belongs to, including nested groups, apply to it as well.
+	principals := map[string]string{strings.ToLower(principalID): "direct"}
+	if *groups {
+		graph, err := newGraphClient()
+		if err == nil {
+			var ids []string
+			if ids, err = graph.memberGroupIDs(ctx, principalID); err == nil {
+				for _, id := range ids {
+					principals[id] = "group " + id
+				}
+			}
+		}
+		if err != nil {
+			log.Printf("Could not read the group memberships of %s; only its direct assignments are evaluated: %v", principalID, err)
+		}
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role assignment client: %v", err)
+	}
+	definitions := map[string]*armcosmos.SQLRoleDefinitionGetResults{}
+	pager := sqlClient.NewListSQLRoleDefinitionsPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list SQL role definitions: %v", err)
+		}
+		for _, d := range page.Value {
+			if d != nil && d.ID != nil && d.Properties != nil {
+				definitions[strings.ToLower(*d.ID)] = d
+			}
+		}
+	}
+	assignments, err := listSQLRoleAssignments(ctx, sqlClient)
+	if err != nil {
+		log.Fatalf("failed to list SQL role assignments: %v", err)
+	}
+
+	allowed := false
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE\tSCOPE\tVIA\tRESULT")
+	considered := 0
+	for _, a := range assignments {
+		via, ok := principals[strings.ToLower(stringValue(a.Properties.PrincipalID))]
+		if !ok {
+			continue
+		}
+		considered++
+		definition := definitions[strings.ToLower(stringValue(a.Properties.RoleDefinitionID))]
+		role := stringValue(a.Properties.RoleDefinitionID)
+		if definition != nil {
+			role = stringValue(definition.Properties.RoleName)
+		}
+		result := evaluateSQLRoleAssignment(definition, stringValue(a.Properties.Scope), action, scope)
+		if strings.HasPrefix(result, "allows") {
+			allowed = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", role, relativeSQLScope(stringValue(a.Properties.Scope)), via, result)
+	}
+	if considered > 0 {
+		_ = tw.Flush()
+	} else {
+		fmt.Printf("Principal %s has no SQL role assignments on %s.\n", principalID, accountName)
+	}
+
+	if allowed {
+		fmt.Printf("yes: %s may perform %s at %s.\n", principalID, action, relativeSQLScope(scope))
+		return
+	}
+	fmt.Printf("no: %s may not perform %s at %s.\n", principalID, action, relativeSQLScope(scope))
+	os.Exit(1)
+}
+
+// evaluateSQLRoleAssignment explains whether one assignment of a role definition grants action at target. The
+// assignment applies at its scope and everything below it. The action must match one of the role's data actions, where
+// * matches anything, and none of the same permission's not-data actions. It is excluded only when no permission
+// allows it.
+func evaluateSQLRoleAssignment(definition *armcosmos.SQLRoleDefinitionGetResults, assignmentScope string, action string, target string) string {
+	if !sqlScopeCovers(assignmentScope, target) {
+		return "scope doesn't cover the target"
+	}
+	if definition == nil {
+		return "role definition not found"
+	}
+	exclusion := ""
+	for _, p := range definition.Properties.Permissions {
+		if p == nil {
+			continue
+		}
+		granted := ""
+		for _, pattern := range p.DataActions {
+			if pattern != nil && dataActionMatches(*pattern, action) {
+				granted = *pattern
+				break
+			}
+		}
+		if granted == "" {
+			continue
+		}
+		excluded := ""
+		for _, pattern := range p.NotDataActions {
+			if pattern != nil && dataActionMatches(*pattern, action) {
+				excluded = *pattern
+				break
+			}
+		}
+		if excluded == "" {
+			return "allows (" + granted + ")"
+		}
+		// Another permission of the role can still allow the action.
+		if exclusion == "" {
+			exclusion = excluded
+		}
+	}
+	if exclusion != "" {
+		return "excluded by not-data action " + exclusion
+	}
+	return "action not in role"
+}
+
+// sqlScopeCovers reports whether an assignment at scope applies to target: the same resource or one inside it.
+func sqlScopeCovers(scope string, target string) bool {
+	scope, target = strings.ToLower(strings.TrimSuffix(scope, "/")), strings.ToLower(strings.TrimSuffix(target, "/"))
+	return target == scope || strings.HasPrefix(target, scope+"/")
+}
+
+// dataActionMatches compares a data action with a pattern from a role definition, case-insensitively; * in the
+// pattern matches any characters, including /.
+func dataActionMatches(pattern string, action string) bool {
+	expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expression, action)
+	return err == nil && matched
+}
+
+// resolveSQLDataAction accepts a full data action, or the last segments of a known one such as items/read.
+func resolveSQLDataAction(action string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(action), "microsoft.documentdb/") {
+		for _, known := range sqlDataActions {
+			if strings.EqualFold(known, action) {
+				return known, nil
+			}
+		}
+		log.Printf("%s is not one of the known NoSQL data actions; evaluating it anyway", action)
+		return action, nil
+	}
+	var matches []string
+	for _, known := range sqlDataActions {
+		if strings.HasSuffix(strings.ToLower(known), "/"+strings.ToLower(strings.TrimPrefix(action, "/"))) {
+			matches = append(matches, known)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return "", fmt.Errorf("unknown data action %q; expected one of:\n  %s", action, strings.Join(sqlDataActions, "\n  "))
+	}
+	return "", fmt.Errorf("data action %q is ambiguous: %s", action, strings.Join(matches, ", "))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runCassandraThroughputCommand shows or switches the throughput mode (autoscale or manual) of a Cassandra keyspace
+// with shared throughput, or of a table, on an API for Cassandra account.
+func runCassandraThroughputCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("cassandra-throughput")
+	keyspace := fs.String("keyspace", "", "Keyspace name (required)")
+	table := fs.String("table", "", "Table name; without it, the keyspace's shared throughput is used")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cassandra-throughput -keyspace <name> [-table <name>] [autoscale | manual]")
+		fmt.Fprintln(fs.Output(), "Shows the current throughput, or migrates it to autoscale or manual throughput. The account must use the API for Cassandra.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	mode := strings.ToLower(fs.Arg(0))
+	if *keyspace == "" || fs.NArg() > 1 || (mode != "" && mode != "autoscale" && mode != "manual") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cassandraClient, err := armcosmos.NewCassandraResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db cassandra client: %v", err)
+	}
+
+	resource := "keyspace " + *keyspace
+	if *table != "" {
+		resource = "table " + *keyspace + "." + *table
+	}
+
+	current, err := getCassandraThroughput(ctx, cassandraClient, *keyspace, *table)
+	if err != nil {
+		log.Fatalf("failed to get throughput of %s: %v", resource, err)
+	}
+	fmt.Printf("Current throughput of %s: %s\n", resource, describeThroughputSettings(current.Properties))
+	if mode == "" {
+		return
+	}
+
+	isAutoscale := current.Properties != nil && current.Properties.Resource != nil && current.Properties.Resource.AutoscaleSettings != nil
+	if (mode == "autoscale") == isAutoscale {
+		fmt.Printf("%s already uses %s throughput; nothing to do.\n", resource, mode)
+		return
+	}
+	if *createOnly {
+		fmt.Printf("Leaving the throughput of %s unchanged (-create-only).\n", resource)
+		return
+	}
+
+	fmt.Printf("Migrating %s to %s throughput...\n", resource, mode)
+	updated, err := migrateCassandraThroughput(ctx, cassandraClient, *keyspace, *table, mode == "autoscale")
+	if err != nil {
+		log.Fatalf("failed to migrate %s to %s throughput: %v", resource, mode, err)
+	}
+	fmt.Printf("Migrated %s: %s\n", resource, describeThroughputSettings(updated.Properties))
+}
+
+// getCassandraThroughput reads the throughput of a table or, when table is empty, of the keyspace.
+func getCassandraThroughput(ctx context.Context, client *armcosmos.CassandraResourcesClient, keyspace string, table string) (armcosmos.ThroughputSettingsGetResults, error) {
+	if table == "" {
+		resp, err := client.GetCassandraKeyspaceThroughput(ctx, resourceGroupName, accountName, keyspace, nil)
+		return resp.ThroughputSettingsGetResults, err
+	}
+	resp, err := client.GetCassandraTableThroughput(ctx, resourceGroupName, accountName, keyspace, table, nil)
+	return resp.ThroughputSettingsGetResults, err
+}
+
+// migrateCassandraThroughput switches a table or keyspace to autoscale or manual throughput and waits for it. Cosmos DB
+// picks the new value: the autoscale max is derived from the current manual RU/s, and manual RU/s from the autoscale max.
+func migrateCassandraThroughput(ctx context.Context, client *armcosmos.CassandraResourcesClient, keyspace string, table string, toAutoscale bool) (armcosmos.ThroughputSettingsGetResults, error) {
+	switch {
+	case table == "" && toAutoscale:
+		poller, err := client.BeginMigrateCassandraKeyspaceToAutoscale(ctx, resourceGroupName, accountName, keyspace, nil)
+		if err != nil {
+			return armcosmos.ThroughputSettingsGetResults{}, err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return resp.ThroughputSettingsGetResults, err
+	case table == "":
+		poller, err := client.BeginMigrateCassandraKeyspaceToManualThroughput(ctx, resourceGroupName, accountName, keyspace, nil)
+		if err != nil {
+			return armcosmos.ThroughputSettingsGetResults{}, err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return resp.ThroughputSettingsGetResults, err
+	case toAutoscale:
+		poller, err := client.BeginMigrateCassandraTableToAutoscale(ctx, resourceGroupName, accountName, keyspace, table, nil)
+		if err != nil {
+			return armcosmos.ThroughputSettingsGetResults{}, err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return resp.ThroughputSettingsGetResults, err
+	default:
+		poller, err := client.BeginMigrateCassandraTableToManualThroughput(ctx, resourceGroupName, accountName, keyspace, table, nil)
+		if err != nil {
+			return armcosmos.ThroughputSettingsGetResults{}, err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return resp.ThroughputSettingsGetResults, err
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Managed Cassandra backups are only available in preview api-versions of the Cosmos DB resource provider.
+const cassandraBackupsAPIVersion = "2024-12-01-preview"
+
+// cassandraBackup is one backup of a managed Cassandra cluster.
+type cassandraBackup struct {
+	ID         *string                    `json:"id,omitempty"`
+	Name       *string                    `json:"name,omitempty"`
+	Properties *cassandraBackupProperties `json:"properties,omitempty"`
+}
+
+type cassandraBackupProperties struct {
+	BackupID              *string    `json:"backupId,omitempty"`
+	BackupState           *string    `json:"backupState,omitempty"`
+	BackupStartTimestamp  *time.Time `json:"backupStartTimestamp,omitempty"`
+	BackupStopTimestamp   *time.Time `json:"backupStopTimestamp,omitempty"`
+	BackupExpiryTimestamp *time.Time `json:"backupExpiryTimestamp,omitempty"`
+}
+
+type cassandraBackupList struct {
+	Value []*cassandraBackup `json:"value"`
+}
+
+// cassandraBackupsClient reads the backups of managed Cassandra clusters in the configured resource group.
+type cassandraBackupsClient struct {
+	rest *armRestClient
+}
+
+func newCassandraBackupsClient() (*cassandraBackupsClient, error) {
+	rest, err := newARMRestClient(cassandraBackupsAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &cassandraBackupsClient{rest: rest}, nil
+}
+
+func (c *cassandraBackupsClient) backupsPath(cluster string) string {
+	return getAssignableScope(ResourceGroup) + "/providers/Microsoft.DocumentDB/cassandraClusters/" + cluster + "/backups"
+}
+
+// list returns the cluster's backups that haven't expired.
+func (c *cassandraBackupsClient) list(ctx context.Context, cluster string) ([]*cassandraBackup, error) {
+	var resp cassandraBackupList
+	if err := c.rest.do(ctx, http.MethodGet, c.backupsPath(cluster), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Value, nil
+}
+
+// get reads one backup by its backup ID.
+func (c *cassandraBackupsClient) get(ctx context.Context, cluster string, backupID string) (cassandraBackup, error) {
+	var backup cassandraBackup
+	if err := c.rest.do(ctx, http.MethodGet, c.backupsPath(cluster)+"/"+backupID, nil, &backup); err != nil {
+		return cassandraBackup{}, err
+	}
+	return backup, nil
+}
+
+// listManagedCassandraBackups prints the cluster's backups, newest first. With maxAge, it exits with status 1 unless a
+// backup succeeded within that time, so it can run as a scheduled backup check.
+func listManagedCassandraBackups(ctx context.Context, cluster string, maxAge time.Duration) {
+	client, err := newCassandraBackupsClient()
+	if err != nil {
+		log.Fatalf("failed to create managed cassandra backups client: %v", err)
+	}
+	backups, err := client.list(ctx, cluster)
+	if err != nil {
+		log.Fatalf("failed to list backups of %s: %v", cluster, err)
+	}
+	sort.Slice(backups, func(i, j int) bool { return cassandraBackupStart(backups[i]).After(cassandraBackupStart(backups[j])) })
+
+	var lastSucceeded *time.Time
+	if len(backups) == 0 {
+		fmt.Printf("Cluster %s has no backups.\n", cluster)
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "BACKUP ID\tSTATE\tSTARTED\tFINISHED\tEXPIRES")
+		for _, b := range backups {
+			if b == nil || b.Properties == nil {
+				continue
+			}
+			p := b.Properties
+			if stringValue(p.BackupState) == "Succeeded" && p.BackupStopTimestamp != nil && (lastSucceeded == nil || p.BackupStopTimestamp.After(*lastSucceeded)) {
+				lastSucceeded = p.BackupStopTimestamp
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", stringValue(p.BackupID), orDash(stringValue(p.BackupState)), orDash(timeValue(p.BackupStartTimestamp)), orDash(timeValue(p.BackupStopTimestamp)), orDash(timeValue(p.BackupExpiryTimestamp)))
+		}
+		_ = tw.Flush()
+	}
+
+	if maxAge <= 0 {
+		return
+	}
+	if lastSucceeded == nil {
+		fmt.Printf("No successful backup of %s.\n", cluster)
+		os.Exit(1)
+	}
+	if age := time.Since(*lastSucceeded); age > maxAge {
+		fmt.Printf("The last successful backup of %s finished %s ago, more than -max-age %s.\n", cluster, age.Round(time.Minute), maxAge)
+		os.Exit(1)
+	}
+	fmt.Printf("The last successful backup of %s finished at %s.\n", cluster, lastSucceeded.UTC().Format(time.RFC3339))
+}
+
+// printManagedCassandraBackup prints the details of one backup, including the resource ID a restore refers to.
+func printManagedCassandraBackup(ctx context.Context, cluster string, backupID string) {
+	client, err := newCassandraBackupsClient()
+	if err != nil {
+		log.Fatalf("failed to create managed cassandra backups client: %v", err)
+	}
+	backup, err := client.get(ctx, cluster, backupID)
+	if err != nil {
+		log.Fatalf("failed to get backup %s of %s: %v", backupID, cluster, err)
+	}
+
+	p := backup.Properties
+	if p == nil {
+		p = &cassandraBackupProperties{}
+	}
+	fmt.Printf("Backup ID: %s\n", stringValue(p.BackupID))
+	fmt.Printf("Resource ID: %s\n", stringValue(backup.ID))
+	fmt.Printf("State: %s\n", orDash(stringValue(p.BackupState)))
+	fmt.Printf("Started: %s\n", orDash(timeValue(p.BackupStartTimestamp)))
+	fmt.Printf("Finished: %s\n", orDash(timeValue(p.BackupStopTimestamp)))
+	if p.BackupStartTimestamp != nil && p.BackupStopTimestamp != nil {
+		fmt.Printf("Duration: %s\n", p.BackupStopTimestamp.Sub(*p.BackupStartTimestamp).Round(time.Second))
+	}
+	fmt.Printf("Expires: %s\n", orDash(timeValue(p.BackupExpiryTimestamp)))
+}
+
+func cassandraBackupStart(b *cassandraBackup) time.Time {
+	if b == nil || b.Properties == nil || b.Properties.BackupStartTimestamp == nil {
+		return time.Time{}
+	}
+	return *b.Properties.BackupStartTimestamp
+}
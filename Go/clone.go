@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runCloneCommand copies the configured account's structure to another account: its databases and containers with
+// their partition keys, indexing, TTL, and other container policies, and their throughput. No data is copied. The
+// target account is created, in one region, when it doesn't exist; databases and containers it already has are left
+// as they are, so a partial clone can be re-run.
+func runCloneCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("clone")
+	targetAccount := fs.String("target-account", "", "Account to clone into; created if it doesn't exist (required)")
+	targetResourceGroup := fs.String("target-resource-group", resourceGroupName, "Existing resource group of the target account")
+	targetLocation := fs.String("target-location", "", "Region for a new target account (default: the source's write region)")
+	database := fs.String("database", "", "Clone only this database (default: all databases)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: clone -target-account <name> [-target-resource-group <name>] [-target-location <region>] [-database <name>]")
+		fmt.Fprintf(fs.Output(), "Creates the databases and containers of %s, without their data, in the target account.\n", accountName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *targetAccount == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *targetAccount == accountName && *targetResourceGroup == resourceGroupName {
+		log.Fatalf("-target-account must be a different account than %s", accountName)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	source, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	if api := accountAPI(source.DatabaseAccountGetResults); api != "NoSQL" {
+		log.Fatalf("clone copies NoSQL accounts; %s is a %s account", accountName, api)
+	}
+	if *targetLocation == "" {
+		*targetLocation = writeRegion(source.DatabaseAccountGetResults)
+	}
+	if err := createCloneAccount(ctx, accountClient, source.DatabaseAccountGetResults, *targetResourceGroup, *targetAccount, *targetLocation); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+	existing, err := listSQLInventory(ctx, sqlClient, *targetResourceGroup, *targetAccount)
+	if err != nil {
+		log.Fatalf("failed to list the databases and containers of %s: %v", *targetAccount, err)
+	}
+	exists := map[string]bool{}
+	for _, r := range existing {
+		exists[r.name] = true
+	}
+
+	resources, err := listSQLInventory(ctx, sqlClient, resourceGroupName, accountName)
+	if err != nil {
+		log.Fatalf("failed to list the databases and containers of %s: %v", accountName, err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tTHROUGHPUT\tRESULT")
+	created, skipped := 0, 0
+	// The inventory lists each database before its containers, so a container's database exists by the time it is created.
+	for _, r := range resources {
+		db, container, _ := strings.Cut(r.name, "/")
+		if *database != "" && db != *database {
+			continue
+		}
+		if r.kind == "Container" && r.container == nil {
+			continue
+		}
+		result := "created"
+		switch {
+		case exists[r.name]:
+			result = "exists"
+			skipped++
+		case r.kind == "Database":
+			body := armcosmos.SQLDatabaseCreateUpdateParameters{Properties: &armcosmos.SQLDatabaseCreateUpdateProperties{
+				Resource: &armcosmos.SQLDatabaseResource{ID: to.Ptr(db)},
+				Options:  cloneThroughputOptions(r.throughput),
+			}}
+			poller, err := sqlClient.BeginCreateUpdateSQLDatabase(withIfNoneMatch(ctx), *targetResourceGroup, *targetAccount, db, body, nil)
+			if err == nil {
+				_, err = pollUntilDone(ctx, poller)
+			}
+			if err != nil {
+				log.Fatalf("failed to create database %s in %s: %v", db, *targetAccount, describeConcurrencyError(err, "Database "+db))
+			}
+			created++
+		default:
+			body := armcosmos.SQLContainerCreateUpdateParameters{Properties: &armcosmos.SQLContainerCreateUpdateProperties{
+				Resource: cloneContainerResource(r.container),
+				Options:  cloneThroughputOptions(r.throughput),
+			}}
+			poller, err := sqlClient.BeginCreateUpdateSQLContainer(withIfNoneMatch(ctx), *targetResourceGroup, *targetAccount, db, container, body, nil)
+			if err == nil {
+				_, err = pollUntilDone(ctx, poller)
+			}
+			if err != nil {
+				log.Fatalf("failed to create container %s in %s: %v", r.name, *targetAccount, describeConcurrencyError(err, "Container "+r.name))
+			}
+			created++
+		}
+		if r.kind == "Database" {
+			fmt.Fprintf(tw, "database\t%s\t%s\t%s\n", r.name, cloneThroughputLabel(r.throughput, "-"), result)
+		} else {
+			fmt.Fprintf(tw, "container\t%s\t%s\t%s\n", r.name, cloneThroughputLabel(r.throughput, "shared"), result)
+		}
+	}
+	_ = tw.Flush()
+	fmt.Printf("Created %d database(s) and container(s) in %s; %d already existed.\n", created, *targetAccount, skipped)
+}
+
+// createCloneAccount creates the target account with the source's kind, capabilities, consistency, local auth, and
+// backup policy, in one region. An existing target account is used as it is.
+func createCloneAccount(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient, source armcosmos.DatabaseAccountGetResults, group string, name string, region string) error {
+	existing, err := accountClient.Get(ctx, group, name, nil)
+	var respErr *azcore.ResponseError
+	switch {
+	case err == nil:
+		if api := accountAPI(existing.DatabaseAccountGetResults); api != "NoSQL" {
+			return fmt.Errorf("target account %s is a %s account", name, api)
+		}
+		if isServerless(existing.DatabaseAccountGetResults) != isServerless(source) {
+			return fmt.Errorf("target account %s and %s must both be serverless or both be provisioned throughput", name, accountName)
+		}
+		fmt.Printf("Account %s already exists; adding the databases and containers it doesn't have.\n", name)
+		return nil
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound:
+	default:
+		return fmt.Errorf("failed to get account %s: %w", name, err)
+	}
+	if err := checkRestoreTarget(ctx, accountClient, group, name); err != nil {
+		return err
+	}
+
+	properties := armcosmos.DatabaseAccountCreateUpdateParameters{
+		Kind:     source.Kind,
+		Location: to.Ptr(region),
+		Tags:     sampleTags(ctx),
+		Properties: &armcosmos.DatabaseAccountCreateUpdateProperties{
+			Locations: []*armcosmos.Location{{
+				LocationName:     to.Ptr(region),
+				FailoverPriority: to.Ptr[int32](0),
+			}},
+			DatabaseAccountOfferType: to.Ptr("Standard"),
+			PublicNetworkAccess:      to.Ptr(armcosmos.PublicNetworkAccessEnabled),
+		},
+	}
+	if p := source.Properties; p != nil {
+		properties.Properties.Capabilities = p.Capabilities
+		properties.Properties.ConsistencyPolicy = p.ConsistencyPolicy
+		properties.Properties.DisableLocalAuth = p.DisableLocalAuth
+		properties.Properties.EnableAnalyticalStorage = p.EnableAnalyticalStorage
+		properties.Properties.BackupPolicy = p.BackupPolicy
+	}
+
+	log.Printf("Creating account %s/%s in %s (this can take a couple minutes)", group, name, region)
+	poller, err := accountClient.BeginCreateOrUpdate(withIfNoneMatch(ctx), group, name, properties, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin creating account %s: %w", name, describeConcurrencyError(err, "Account "+name))
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return fmt.Errorf("failed to create account %s: %w", name, err)
+	}
+	recordResource("Microsoft.DocumentDB/databaseAccounts", resp.ID)
+	fmt.Printf("Created account: %s\n", stringValue(resp.ID))
+	return nil
+}
+
+// cloneThroughputOptions provisions the same autoscale max or manual RU/s as the source; nil (shared database
+// throughput, or serverless) provisions none.
+func cloneThroughputOptions(throughput *armcosmos.ThroughputSettingsGetProperties) *armcosmos.CreateUpdateOptions {
+	ru, autoscale, _, ok := provisionedThroughput(throughput)
+	switch {
+	case !ok:
+		return nil
+	case autoscale:
+		return &armcosmos.CreateUpdateOptions{AutoscaleSettings: &armcosmos.AutoscaleSettings{MaxThroughput: to.Ptr(int32(ru))}}
+	default:
+		return &armcosmos.CreateUpdateOptions{Throughput: to.Ptr(int32(ru))}
+	}
+}
+
+// cloneThroughputLabel describes throughput for the clone table, or none when the resource has no throughput of its
+// own.
+func cloneThroughputLabel(throughput *armcosmos.ThroughputSettingsGetProperties, none string) string {
+	if ru, autoscale, _, ok := provisionedThroughput(throughput); ok {
+		return throughputLabel(ru, autoscale)
+	}
+	return none
+}
+
+// cloneContainerResource copies a container's definition, leaving out the system properties of the source.
+func cloneContainerResource(source *armcosmos.SQLContainerGetPropertiesResource) *armcosmos.SQLContainerResource {
+	resource := &armcosmos.SQLContainerResource{
+		ID:                       source.ID,
+		AnalyticalStorageTTL:     source.AnalyticalStorageTTL,
+		ClientEncryptionPolicy:   source.ClientEncryptionPolicy,
+		ComputedProperties:       source.ComputedProperties,
+		ConflictResolutionPolicy: source.ConflictResolutionPolicy,
+		DefaultTTL:               source.DefaultTTL,
+		FullTextPolicy:           source.FullTextPolicy,
+		IndexingPolicy:           source.IndexingPolicy,
+		UniqueKeyPolicy:          source.UniqueKeyPolicy,
+		VectorEmbeddingPolicy:    source.VectorEmbeddingPolicy,
+	}
+	if source.PartitionKey != nil {
+		partitionKey := *source.PartitionKey
+		partitionKey.SystemKey = nil
+		resource.PartitionKey = &partitionKey
+	}
+	return resource
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// azureCloud is the Azure cloud (public or national) that credentials and clients target. Set with the Cloud setting.
+var azureCloud = cloud.AzurePublic
+
+// parseAzureCloud maps a Cloud setting value to its azcore cloud configuration.
+func parseAzureCloud(name string) (cloud.Configuration, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "azurepublic", "azurecloud", "public":
+		return cloud.AzurePublic, nil
+	case "azurechina", "azurechinacloud", "china":
+		return cloud.AzureChina, nil
+	case "azuregovernment", "azureusgovernment", "usgovernment":
+		return cloud.AzureGovernment, nil
+	}
+	return cloud.Configuration{}, fmt.Errorf("unknown cloud %q (expected AzurePublic, AzureChina, or AzureGovernment)", name)
+}
+
+// armClientOptions returns the options every ARM client is created with, so all clients target the configured cloud
+// and share the same throttling (429) handling.
+func armClientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{ClientOptions: azcore.ClientOptions{
+		Cloud:            azureCloud,
+		PerRetryPolicies: []policy.Policy{throttlingPolicy{}, tracingPolicy{}},
+		Retry:            policy.RetryOptions{MaxRetries: armMaxRetries, MaxRetryDelay: armMaxRetryDelay},
+		Logging:          policy.LogOptions{AllowedHeaders: debugLogHeaders},
+		TracingProvider:  tracingProvider(),
+	}}
+}
+
+// credentialOptions returns the DefaultAzureCredential options for the configured cloud (its Entra ID authority).
+func credentialOptions() *azidentity.DefaultAzureCredentialOptions {
+	return &azidentity.DefaultAzureCredentialOptions{ClientOptions: azcore.ClientOptions{Cloud: azureCloud}}
+}
+
+// armTokenScope returns the token scope for Azure Resource Manager in the configured cloud.
+func armTokenScope() string {
+	return strings.TrimSuffix(azureCloud.Services[cloud.ResourceManager].Audience, "/") + "/.default"
+}
+
+// microsoftGraphEndpoint returns the Microsoft Graph endpoint for the configured cloud.
+func microsoftGraphEndpoint() string {
+	switch azureCloud.ActiveDirectoryAuthorityHost {
+	case cloud.AzureChina.ActiveDirectoryAuthorityHost:
+		return "https://microsoftgraph.chinacloudapi.cn"
+	case cloud.AzureGovernment.ActiveDirectoryAuthorityHost:
+		return "https://graph.microsoft.us"
+	}
+	return "https://graph.microsoft.com"
+}
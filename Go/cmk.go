@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runRotateKeyCommand points a customer-managed key (CMK) account at a new Key Vault key version, or at a versionless
+// key URI so Cosmos DB follows the key's current version automatically, then checks that the account is still healthy.
+func runRotateKeyCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("rotate-key")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rotate-key <key uri>")
+		fmt.Fprintln(fs.Output(), "Sets the account's KeyVaultKeyUri to https://<vault>.vault.azure.net/keys/<key>[/<version>] and waits for Cosmos DB to re-wrap its data encryption key.")
+		fmt.Fprintln(fs.Output(), "Without a version, the account uses the key's latest version and follows later rotations.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	keyURI := strings.TrimRight(strings.TrimSpace(fs.Arg(0)), "/")
+	keyName, keyVersion, err := parseKeyVaultKeyURI(keyURI)
+	if err != nil {
+		log.Fatalf("Invalid key URI: %v", err)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	p := account.Properties
+	if p == nil || stringValue(p.KeyVaultKeyURI) == "" {
+		log.Fatalf("Account %s isn't encrypted with a customer-managed key; the key can only be set when the account is created.", accountName)
+	}
+	currentURI := strings.TrimRight(*p.KeyVaultKeyURI, "/")
+	fmt.Printf("Current key: %s (version in use: %s)\n", currentURI, orDash(stringValue(p.KeyVaultKeyURIVersion)))
+	if strings.EqualFold(currentURI, keyURI) {
+		fmt.Println("The account already uses this key URI; nothing to do.")
+		return
+	}
+	if currentName, _, err := parseKeyVaultKeyURI(currentURI); err == nil && !strings.EqualFold(currentName, keyName) {
+		fmt.Printf("Note: switching from key %q to a different key %q. The account's identity needs get, wrapKey, and unwrapKey permissions on it.\n", currentName, keyName)
+	}
+
+	fmt.Printf("Setting KeyVaultKeyUri to %s...\n", keyURI)
+	params := armcosmos.DatabaseAccountUpdateParameters{
+		Properties: &armcosmos.DatabaseAccountUpdateProperties{
+			KeyVaultKeyURI: to.Ptr(keyURI),
+		},
+	}
+	poller, err := accountClient.BeginUpdate(ctx, resourceGroupName, accountName, params, nil)
+	if err != nil {
+		log.Fatalf("failed to update the account key: %v", err)
+	}
+	if _, err := pollUntilDone(ctx, poller); err != nil {
+		log.Fatalf("failed to update the account key: %v", err)
+	}
+
+	// The update returns before every region has re-wrapped its data encryption key with the new key version.
+	updated, err := watchAccountUntilSteady(ctx)
+	if err != nil {
+		log.Fatalf("failed to wait for the key rotation: %v", err)
+	}
+	if keyVersion != "" {
+		updated, err = waitForKeyVersion(ctx, accountClient, keyVersion)
+		if err != nil {
+			log.Fatalf("failed to wait for the key rotation: %v", err)
+		}
+	}
+
+	if updated.Properties == nil {
+		log.Fatalf("failed to read the account after the key rotation")
+	}
+	fmt.Printf("Key version in use: %s\n", orDash(stringValue(updated.Properties.KeyVaultKeyURIVersion)))
+	status := stringValue(updated.Properties.CustomerManagedKeyStatus)
+	fmt.Printf("Customer-managed key status: %s\n", orDash(status))
+	if status != "" && !strings.Contains(strings.ToLower(status), "confirmed") {
+		log.Printf("The account reports a customer-managed key problem; check the key's permissions and that it is enabled.")
+		os.Exit(1)
+	}
+	if verifyDataPlane {
+		verifyDataPlaneAccess(ctx)
+	}
+	fmt.Println("Key rotation complete.")
+}
+
+// parseKeyVaultKeyURI returns the key name and (possibly empty) version from a Key Vault key URI.
+func parseKeyVaultKeyURI(keyURI string) (name string, version string, err error) {
+	u, err := url.Parse(keyURI)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return "", "", fmt.Errorf("expected an https Key Vault URI (got %q)", keyURI)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || len(segments) > 3 || segments[0] != "keys" || segments[1] == "" {
+		return "", "", fmt.Errorf("expected https://<vault>/keys/<key>[/<version>] (got %q)", keyURI)
+	}
+	if len(segments) == 3 {
+		version = segments[2]
+	}
+	return segments[1], version, nil
+}
+
+// waitForKeyVersion polls the account until it reports the given key version in use, bounded by OperationTimeout.
+func waitForKeyVersion(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient, version string) (armcosmos.DatabaseAccountGetResults, error) {
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+	interval := defaultWatchInterval
+	if pollFrequency > 0 {
+		interval = pollFrequency
+	}
+
+	for {
+		resp, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+		if err != nil {
+			return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("failed to get cosmos db account: %w", err)
+		}
+		inUse := ""
+		if resp.Properties != nil {
+			inUse = stringValue(resp.Properties.KeyVaultKeyURIVersion)
+		}
+		if strings.EqualFold(inUse, version) {
+			return resp.DatabaseAccountGetResults, nil
+		}
+		fmt.Printf("Waiting for key version %s (in use: %s)...\n", version, orDash(inUse))
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("key version %s was not in use within OperationTimeout (%s)", version, operationTimeout)
+			}
+			return armcosmos.DatabaseAccountGetResults{}, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+const (
+	// cosmosDBFirstPartyAppID is the application ID of the "Azure Cosmos DB" first-party service principal, which
+	// wraps and unwraps the account's data encryption key when DefaultIdentity is FirstPartyIdentity.
+	cosmosDBFirstPartyAppID = "a232010e-820c-4083-83bb-3ace5fc29d0b"
+
+	userAssignedIdentityPrefix = "UserAssignedIdentity="
+)
+
+// keyVaultCryptoRoleIDs are the built-in roles that let a principal get, wrap, and unwrap keys in an RBAC vault:
+// Key Vault Crypto Service Encryption User, Key Vault Crypto Officer, and Key Vault Administrator.
+var keyVaultCryptoRoleIDs = []string{
+	"e147488a-f6f5-4113-8e2d-b22465e65bf6",
+	"14b46e9e-c2b7-41b4-b07b-48a6ebf60603",
+	"00482a5a-887f-4fb3-b363-3b7fe8e74483",
+}
+
+// validateCMKSettings checks KeyVaultKeyUri and DefaultIdentity.
+func validateCMKSettings() error {
+	if keyVaultKeyURI == "" {
+		if defaultIdentity != "" {
+			return fmt.Errorf("DefaultIdentity only applies with KeyVaultKeyUri")
+		}
+		return nil
+	}
+	if _, _, err := parseKeyVaultKeyURI(keyVaultKeyURI); err != nil {
+		return fmt.Errorf("KeyVaultKeyUri: %w", err)
+	}
+	switch {
+	case defaultIdentity == "" || defaultIdentity == "FirstPartyIdentity":
+	case strings.HasPrefix(defaultIdentity, userAssignedIdentityPrefix) && len(defaultIdentity) > len(userAssignedIdentityPrefix):
+	case defaultIdentity == "SystemAssignedIdentity":
+		return fmt.Errorf("DefaultIdentity SystemAssignedIdentity can't be used to create an account (the identity doesn't exist yet); use FirstPartyIdentity or UserAssignedIdentity=<resource id>")
+	default:
+		return fmt.Errorf("DefaultIdentity must be FirstPartyIdentity or UserAssignedIdentity=<resource id> (got %q)", defaultIdentity)
+	}
+	return nil
+}
+
+// applyCMKSettings adds the customer-managed key and the identity that accesses it to the account parameters.
+func applyCMKSettings(params *armcosmos.DatabaseAccountCreateUpdateParameters) {
+	if keyVaultKeyURI == "" {
+		return
+	}
+	params.Properties.KeyVaultKeyURI = to.Ptr(keyVaultKeyURI)
+	params.Properties.DefaultIdentity = to.Ptr("FirstPartyIdentity")
+	if identityID, ok := strings.CutPrefix(defaultIdentity, userAssignedIdentityPrefix); ok {
+		params.Properties.DefaultIdentity = to.Ptr(defaultIdentity)
+		params.Identity = &armcosmos.ManagedServiceIdentity{
+			Type: to.Ptr(armcosmos.ResourceIdentityTypeUserAssigned),
+			UserAssignedIdentities: map[string]*armcosmos.Components1Jq1T4ISchemasManagedserviceidentityPropertiesUserassignedidentitiesAdditionalproperties{
+				identityID: {},
+			},
+		}
+	}
+}
+
+// checkCMKPrerequisites verifies what Cosmos DB needs from the Key Vault before a CMK account is created: soft delete
+// and purge protection, and get/wrapKey/unwrapKey access (an access policy, or a crypto role in an RBAC vault) for the
+// identity in DefaultIdentity. It returns one error per missing prerequisite.
+func checkCMKPrerequisites(ctx context.Context) []error {
+	vault, err := getKeyVaultForKey(ctx, keyVaultKeyURI)
+	if err != nil {
+		return []error{err}
+	}
+
+	problems := make([]error, 0)
+	vaultID := stringValue(vault.ID)
+	p := vault.Properties
+	if p == nil {
+		p = &armkeyvault.VaultProperties{}
+	}
+	if p.EnableSoftDelete != nil && !*p.EnableSoftDelete {
+		problems = append(problems, fmt.Errorf("soft delete is disabled on Key Vault %s", vaultID))
+	}
+	if p.EnablePurgeProtection == nil || !*p.EnablePurgeProtection {
+		problems = append(problems, fmt.Errorf("purge protection is not enabled on Key Vault %s (az keyvault update --enable-purge-protection true)", vaultID))
+	}
+
+	principalID, principalName, err := getCMKPrincipalID(ctx)
+	if err != nil {
+		return append(problems, err)
+	}
+
+	if p.EnableRbacAuthorization != nil && *p.EnableRbacAuthorization {
+		hasRole, err := hasKeyVaultCryptoRole(ctx, vaultID, principalID)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Errorf("could not check role assignments on Key Vault %s: %w", vaultID, err))
+		case !hasRole:
+			problems = append(problems, fmt.Errorf("%s (%s) has no Key Vault Crypto Service Encryption User (or Crypto Officer/Administrator) role assignment on Key Vault %s", principalName, principalID, vaultID))
+		}
+		return problems
+	}
+
+	granted := map[string]bool{}
+	for _, policy := range p.AccessPolicies {
+		if policy == nil || policy.Permissions == nil || !strings.EqualFold(stringValue(policy.ObjectID), principalID) {
+			continue
+		}
+		for _, permission := range policy.Permissions.Keys {
+			granted[strings.ToLower(enumValue(permission))] = true
+		}
+	}
+	missing := make([]string, 0, 3)
+	for _, permission := range []string{"get", "wrapKey", "unwrapKey"} {
+		if !granted[strings.ToLower(permission)] && !granted["all"] {
+			missing = append(missing, permission)
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Errorf("the access policies of Key Vault %s don't grant %s (%s) the key permissions: %s", vaultID, principalName, principalID, strings.Join(missing, ", ")))
+	}
+	return problems
+}
+
+// getKeyVaultForKey finds the Key Vault resource that hosts a key URI in the subscription and reads its properties.
+func getKeyVaultForKey(ctx context.Context, keyURI string) (armkeyvault.Vault, error) {
+	u, err := url.Parse(keyURI)
+	if err != nil {
+		return armkeyvault.Vault{}, err
+	}
+	vaultName, _, _ := strings.Cut(u.Hostname(), ".")
+
+	resourcesClient, err := armresources.NewClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return armkeyvault.Vault{}, fmt.Errorf("failed to create resources client: %w", err)
+	}
+	filter := fmt.Sprintf("resourceType eq 'Microsoft.KeyVault/vaults' and name eq '%s'", vaultName)
+	pager := resourcesClient.NewListPager(&armresources.ClientListOptions{Filter: to.Ptr(filter)})
+	vaultID := ""
+	for pager.More() && vaultID == "" {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return armkeyvault.Vault{}, fmt.Errorf("failed to look up Key Vault %s: %w", vaultName, err)
+		}
+		for _, r := range page.Value {
+			if r != nil && r.ID != nil && strings.EqualFold(stringValue(r.Name), vaultName) {
+				vaultID = *r.ID
+			}
+		}
+	}
+	if vaultID == "" {
+		return armkeyvault.Vault{}, fmt.Errorf("Key Vault %s was not found in subscription %s; the prerequisite checks need read access to it", vaultName, subscriptionID)
+	}
+
+	id, err := arm.ParseResourceID(vaultID)
+	if err != nil {
+		return armkeyvault.Vault{}, fmt.Errorf("invalid Key Vault ID %s: %w", vaultID, err)
+	}
+	client, err := armkeyvault.NewVaultsClient(id.SubscriptionID, credential, armClientOptions())
+	if err != nil {
+		return armkeyvault.Vault{}, fmt.Errorf("failed to create Key Vault client: %w", err)
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return armkeyvault.Vault{}, fmt.Errorf("failed to read Key Vault %s: %w", vaultID, err)
+	}
+	return resp.Vault, nil
+}
+
+// getCMKPrincipalID returns the object ID and a description of the identity Cosmos DB uses to access the key.
+func getCMKPrincipalID(ctx context.Context) (string, string, error) {
+	if identityID, ok := strings.CutPrefix(defaultIdentity, userAssignedIdentityPrefix); ok {
+		id, err := arm.ParseResourceID(identityID)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid user-assigned identity ID %s: %w", identityID, err)
+		}
+		client, err := armmsi.NewUserAssignedIdentitiesClient(id.SubscriptionID, credential, armClientOptions())
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create managed identity client: %w", err)
+		}
+		identity, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read user-assigned identity %s: %w", identityID, err)
+		}
+		if identity.Properties == nil {
+			return "", "", fmt.Errorf("user-assigned identity %s has no principal ID", identityID)
+		}
+		return stringValue(identity.Properties.PrincipalID), "user-assigned identity " + identityID, nil
+	}
+
+	graph, err := newGraphClient()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create Microsoft Graph client: %w", err)
+	}
+	objectID, err := graph.servicePrincipalObjectID(ctx, cosmosDBFirstPartyAppID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up the Azure Cosmos DB service principal (app ID %s): %w", cosmosDBFirstPartyAppID, err)
+	}
+	return objectID, "the Azure Cosmos DB service principal", nil
+}
+
+// hasKeyVaultCryptoRole reports whether the principal has a Key Vault crypto role at the vault or an enclosing scope.
+func hasKeyVaultCryptoRole(ctx context.Context, vaultID string, principalID string) (bool, error) {
+	roleAssignmentsClient, err := armauthorization.NewRoleAssignmentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return false, err
+	}
+	filter := fmt.Sprintf("principalId eq '%s'", principalID)
+	pager := roleAssignmentsClient.NewListForScopePager(vaultID, &armauthorization.RoleAssignmentsClientListForScopeOptions{Filter: to.Ptr(filter)})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, a := range page.Value {
+			if a == nil || a.Properties == nil {
+				continue
+			}
+			definitionID := strings.ToLower(stringValue(a.Properties.RoleDefinitionID))
+			for _, roleID := range keyVaultCryptoRoleIDs {
+				if strings.HasSuffix(definitionID, "/"+roleID) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// command is a sub-command that can be run non-interactively, for example `go run . metrics -window 24h`.
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string)
+}
+
+// commands returns the sub-commands available from the command line.
+func commands() []command {
+	return []command{
+		{name: "metrics", description: "Show RU consumption metrics for the account/container", run: runMetricsCommand},
+		{name: "hot-partitions", description: "Flag partitions that consume a disproportionate share of RU/s", run: runHotPartitionsCommand},
+		{name: "usage", description: "Show data size, index size, and document count per container", run: runUsageCommand},
+		{name: "activity-log", description: "List recent management operations on the account", run: runActivityLogCommand},
+		{name: "latency-percentiles", description: "Show P50/P99 replication latency per region pair", run: runLatencyPercentilesCommand},
+		{name: "advisor", description: "Show Azure Advisor cost and performance recommendations", run: runAdvisorCommand},
+		{name: "copy-container", description: "Copy a container to another container with a data transfer job", run: runCopyContainerCommand},
+		{name: "cost-estimate", description: "Estimate the monthly cost of the configured throughput", run: runCostEstimateCommand},
+		{name: "services", description: "List, show, or delete account services (dedicated gateway, data transfer, ...)", run: runServicesCommand},
+		{name: "graphs", description: "List, create, or delete Graph resources (requires the GraphAPICompute service)", run: runGraphsCommand},
+		{name: "throughput-pool", description: "Create a throughput pool (fleet), add the account, and show pool utilization", run: runThroughputPoolCommand},
+		{name: "verify-data-plane", description: "Write, read, and delete a test item with Entra ID auth (azcosmos)", run: runVerifyDataPlaneCommand},
+		{name: "endpoints", description: "Show the account's document, regional, and dedicated gateway endpoints", run: runEndpointsCommand},
+		{name: "restore-info", description: "Show the account instance ID and earliest restorable time (continuous backup)", run: runRestoreInfoCommand},
+		{name: "role-assignments", description: "List the SQL role assignments granted to a principal (object id or \"me\")", run: runRoleAssignmentsCommand},
+		{name: "orphaned-role-assignments", description: "Report (or -delete) SQL role assignments whose principal no longer exists in Entra ID", run: runOrphanedRoleAssignmentsCommand},
+		{name: "runs", description: "List sample runs, show a run's resources, or clean them up (by run ID tag)", run: runRunsCommand},
+		{name: "preflight", description: "Check account limits, name availability, and regional access before provisioning", run: runPreflightCommand},
+		{name: "status", description: "Show provisioning state, throughput, and key settings of the account and its resources", run: runStatusCommand},
+		{name: "rotate-key", description: "Point a customer-managed key account at a new Key Vault key version (or versionless URI)", run: runRotateKeyCommand},
+		{name: "restore", description: "Restore the account to a point in time as a new account, optionally in another region or resource group", run: runRestoreCommand},
+		{name: "backup-info", description: "Show the earliest and latest restorable time of each container (continuous backup)", run: runBackupInfoCommand},
+		{name: "cost-compare", description: "Compare manual and autoscale cost from recent RU consumption and recommend a mode", run: runCostCompareCommand},
+		{name: "kql", description: "Run a canned KQL query (top RU operations, hot partition keys, 429s) against the account's logs", run: runKQLCommand},
+		{name: "partition-sizes", description: "List the largest logical partitions and flag those nearing the 20 GB limit", run: runPartitionSizesCommand},
+		{name: "latency", description: "Show server-side latency per region and replication latency per region pair, with threshold warnings", run: runLatencyCommand},
+		{name: "cassandra-throughput", description: "Show or migrate a Cassandra keyspace or table between autoscale and manual throughput", run: runCassandraThroughputCommand},
+		{name: "restore-deleted", description: "Recreate a deleted database, container, or graph in the account from continuous backup", run: runRestoreDeletedCommand},
+		{name: "restorable-sql", description: "List SQL database and container create/delete events and the resources restorable at a point in time", run: runRestorableSQLCommand},
+		{name: "transfer-jobs", description: "List data transfer jobs, or get, cancel, pause, or resume one", run: runTransferJobsCommand},
+		{name: "private-link", description: "List private link group IDs and DNS zones, or list, approve, and reject private endpoint connections", run: runPrivateLinkCommand},
+		{name: "managed-cassandra", description: "Create or scale an Azure Managed Instance for Apache Cassandra data center, or show its seed nodes and backups", run: runManagedCassandraCommand},
+		{name: "mongo-vcore", description: "Create or show an Azure Cosmos DB for MongoDB (vCore) cluster", run: runMongoVCoreCommand},
+		{name: "postgres-cluster", description: "Create or show an Azure Cosmos DB for PostgreSQL (Citus) cluster", run: runPostgresClusterCommand},
+		{name: "reserved-capacity", description: "Quote or purchase reserved capacity matching the account's provisioned throughput", run: runReservedCapacityCommand},
+		{name: "policy", description: "Assign built-in Cosmos DB network hardening policies to the resource group and report compliance", run: runPolicyCommand},
+		{name: "template", description: "Export the account as an ARM template, or deploy an ARM template to the resource group", run: runTemplateCommand},
+		{name: "move", description: "Validate and move the account to another resource group or subscription", run: runMoveCommand},
+		{name: "inventory", description: "List every Cosmos DB account in the subscription with its resources and total provisioned RU/s", run: runInventoryCommand},
+		{name: "exporter", description: "Serve RU, storage, and 429 metrics of the configured accounts on /metrics for Prometheus", run: runExporterCommand},
+		{name: "events", description: "Deliver ARM change events for the account to a webhook or storage queue (Event Grid)", run: runEventsCommand},
+		{name: "scale-schedule", description: "Set the container throughput to the ThroughputSchedule window active now (run it from cron)", run: runScaleScheduleCommand},
+		{name: "recommend-throughput", description: "Suggest an autoscale max or manual RU/s per container from the last N days of RU consumption", run: runRecommendThroughputCommand},
+		{name: "scale", description: "Set the autoscale max or manual RU/s of any database or container (scale -container <name> -max-ru <RU/s>)", run: runScaleCommand},
+		{name: "scale-database", description: "Apply one throughput change (absolute or percentage) to every container in a database", run: runScaleDatabaseCommand},
+		{name: "cost-report", description: "List each container's throughput mode, RU/s, storage, and estimated monthly cost, most expensive first", run: runCostReportCommand},
+		{name: "sql-rbac", description: "Export the account's SQL role definitions and assignments to JSON, or import them into this or another account", run: runSQLRBACCommand},
+		{name: "copy-rbac", description: "Copy the custom SQL role definitions and role assignments from one account to another", run: runCopyRBACCommand},
+		{name: "clone", description: "Create the databases and containers of the account, without data, in a new account", run: runCloneCommand},
+		{name: "compare", description: "Report the configuration drift between the account and another account", run: runCompareCommand},
+		{name: "promote", description: "Provision the current profile's spec as another profile, overriding only names, throughput, regions, and tags", run: runPromoteCommand},
+		{name: "harden-local-auth", description: "Disable key-based auth and verify keys are rejected while Entra ID still works (or -revert)", run: runHardenLocalAuthCommand},
+		{name: "can-i", description: "Evaluate locally whether a principal's SQL role assignments allow a data action at a scope", run: runCanICommand},
+		{name: "roles", description: "Show the SQL role definitions (built-in, or -custom too) and the data actions each grants", run: runRolesCommand},
+	}
+}
+
+// runCommand runs the named sub-command with its remaining arguments.
+func runCommand(ctx context.Context, name string, args []string) {
+	for _, c := range commands() {
+		if c.name == name {
+			c.run(ctx, args)
+			return
+		}
+	}
+	log.Fatalf("Unknown command %q. Run with -h to list the available commands.", name)
+}
+
+// printUsage prints the global usage, including the list of sub-commands.
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command [command flags]]\n\n", os.Args[0])
+	fmt.Fprintln(out, "Without a command, the interactive menu runs (or the full sample when stdin is not a terminal).")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(out, "  %-20s %s\n", c.name, c.description)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	flag.PrintDefaults()
+}
+
+// newCommandFlagSet creates a flag set for a sub-command that exits on parse errors.
+func newCommandFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// builtInSQLRoleNames are the SQL role definitions every account has, under the same IDs.
+var builtInSQLRoleNames = map[string]string{
+	"00000000-0000-0000-0000-000000000001": "Cosmos DB Built-in Data Reader",
+	"00000000-0000-0000-0000-000000000002": "Cosmos DB Built-in Data Contributor",
+}
+
+// driftSetting is one named setting of an account, database, container, or role, as text to compare.
+type driftSetting struct {
+	name  string
+	value string
+}
+
+// accountSnapshot is what compare reads from one account.
+type accountSnapshot struct {
+	name      string
+	settings  []driftSetting
+	resources map[string][]driftSetting
+	rbac      map[string][]driftSetting
+}
+
+// runCompareCommand diffs the configured account against another account, for example staging against production,
+// and prints only what differs.
+func runCompareCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("compare")
+	other := fs.String("account", "", "Account to compare with, in the same subscription (required)")
+	otherGroup := fs.String("resource-group", resourceGroupName, "Resource group of -account")
+	report := addReportFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: compare -account <name> [-resource-group <name>] [-format text|csv|html] [-out <file>]")
+		fmt.Fprintf(fs.Output(), "Reports the configuration drift between %s and another account.\n", accountName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *other == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *other == accountName && *otherGroup == resourceGroupName {
+		log.Fatalf("-account must be a different account than %s", accountName)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+	a, err := readAccountSnapshot(ctx, accountClient, sqlClient, resourceGroupName, accountName)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	b, err := readAccountSnapshot(ctx, accountClient, sqlClient, *otherGroup, *other)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	headers := []string{"ITEM", "SETTING", strings.ToUpper(a.name), strings.ToUpper(b.name)}
+	accountTable := reportTable{title: "Account settings", headers: headers}
+	diffSettings(&accountTable, "account", a.settings, b.settings)
+	resourceTable := reportTable{title: "Databases and containers", headers: headers}
+	diffResources(&resourceTable, a.resources, b.resources)
+	rbacTable := reportTable{title: "SQL RBAC (role definitions by name, assignments by role, scope, and principal)", headers: headers}
+	diffResources(&rbacTable, a.rbac, b.rbac)
+
+	differences := 0
+	for _, t := range []*reportTable{&accountTable, &resourceTable, &rbacTable} {
+		differences += len(t.rows)
+		if len(t.rows) == 0 {
+			t.addNote("No differences.")
+		}
+	}
+	rbacTable.addNote("Scopes are compared relative to each account, so /dbs/orders in one account matches /dbs/orders in the other.")
+	title := fmt.Sprintf("Configuration drift between %s and %s", a.name, b.name)
+	if err := writeReport(report, title, accountTable, resourceTable, rbacTable); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *report.out == "" && strings.EqualFold(*report.format, "text") {
+		fmt.Printf("%d difference(s) between %s and %s.\n", differences, a.name, b.name)
+	}
+}
+
+// readAccountSnapshot reads an account's settings, its databases and containers, and its SQL RBAC.
+func readAccountSnapshot(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient, sqlClient *armcosmos.SQLResourcesClient, group string, name string) (accountSnapshot, error) {
+	snapshot := accountSnapshot{name: name, resources: map[string][]driftSetting{}, rbac: map[string][]driftSetting{}}
+	account, err := accountClient.Get(ctx, group, name, nil)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to get account %s: %w", name, err)
+	}
+	snapshot.settings = accountDriftSettings(account.DatabaseAccountGetResults)
+	if api := accountAPI(account.DatabaseAccountGetResults); api != "NoSQL" {
+		return snapshot, fmt.Errorf("compare reads NoSQL accounts; %s is a %s account", name, api)
+	}
+
+	resources, err := listSQLInventory(ctx, sqlClient, group, name)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to list the databases and containers of %s: %w", name, err)
+	}
+	for _, r := range resources {
+		if r.kind == "Database" {
+			snapshot.resources[r.name] = []driftSetting{{"throughput", cloneThroughputLabel(r.throughput, "none")}}
+			continue
+		}
+		if r.container == nil {
+			continue
+		}
+		settings := containerDriftSettings(r.container)
+		settings = append(settings, driftSetting{"throughput", cloneThroughputLabel(r.throughput, "shared")})
+		snapshot.resources[r.name] = settings
+	}
+
+	rbac, err := readSQLRBAC(ctx, sqlClient, group, name)
+	if err != nil {
+		return snapshot, err
+	}
+	accountID := sqlAccountID(group, name)
+	roleNames := map[string]string{}
+	for _, d := range rbac.RoleDefinitions {
+		roleNames[strings.ToLower(d.Name)] = d.RoleName
+		scopes := make([]string, 0, len(d.AssignableScopes))
+		for _, scope := range d.AssignableScopes {
+			scopes = append(scopes, relativeScope(scope, accountID))
+		}
+		sort.Strings(scopes)
+		var actions []string
+		for _, p := range d.Permissions {
+			actions = append(actions, p.DataActions...)
+			for _, a := range p.NotDataActions {
+				actions = append(actions, "not "+a)
+			}
+		}
+		sort.Strings(actions)
+		snapshot.rbac["role "+d.RoleName] = []driftSetting{
+			{"assignable scopes", strings.Join(scopes, ", ")},
+			{"data actions", strings.Join(actions, ", ")},
+		}
+	}
+	for id, role := range builtInSQLRoleNames {
+		roleNames[id] = role
+	}
+	for _, a := range rbac.RoleAssignments {
+		role := sqlRoleName(roleNames, a.RoleDefinitionID[strings.LastIndex(a.RoleDefinitionID, "/")+1:])
+		key := fmt.Sprintf("assignment %s at %s to %s", role, relativeScope(a.Scope, accountID), a.PrincipalID)
+		snapshot.rbac[key] = []driftSetting{}
+	}
+	return snapshot, nil
+}
+
+// accountDriftSettings returns the account-level settings compare looks at: API and capabilities, consistency,
+// regions, networking, and features.
+func accountDriftSettings(account armcosmos.DatabaseAccountGetResults) []driftSetting {
+	p := account.Properties
+	if p == nil {
+		return nil
+	}
+	var capabilities []string
+	for _, c := range p.Capabilities {
+		if c != nil {
+			capabilities = append(capabilities, stringValue(c.Name))
+		}
+	}
+	sort.Strings(capabilities)
+
+	consistency := ""
+	if c := p.ConsistencyPolicy; c != nil {
+		consistency = enumValue(c.DefaultConsistencyLevel)
+		if c.DefaultConsistencyLevel != nil && *c.DefaultConsistencyLevel == armcosmos.DefaultConsistencyLevelBoundedStaleness {
+			prefix := int64(0)
+			if c.MaxStalenessPrefix != nil {
+				prefix = *c.MaxStalenessPrefix
+			}
+			consistency += fmt.Sprintf(" (%d operations, %ss)", prefix, int32Value(c.MaxIntervalInSeconds))
+		}
+	}
+
+	regions := make([]string, 0, len(p.Locations))
+	for _, l := range p.Locations {
+		if l == nil {
+			continue
+		}
+		region := fmt.Sprintf("%s (%s)", stringValue(l.LocationName), int32Value(l.FailoverPriority))
+		if l.IsZoneRedundant != nil && *l.IsZoneRedundant {
+			region = fmt.Sprintf("%s (%s, zone redundant)", stringValue(l.LocationName), int32Value(l.FailoverPriority))
+		}
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var ipRules []string
+	for _, r := range p.IPRules {
+		if r != nil {
+			ipRules = append(ipRules, stringValue(r.IPAddressOrRange))
+		}
+	}
+	sort.Strings(ipRules)
+	var subnets []string
+	for _, r := range p.VirtualNetworkRules {
+		if r != nil {
+			subnets = append(subnets, relativeScope(stringValue(r.ID), "/subscriptions/"+subscriptionID))
+		}
+	}
+	sort.Strings(subnets)
+
+	backup := "Periodic"
+	if _, ok := p.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy); ok {
+		backup = "Continuous"
+	}
+	serverless := "provisioned"
+	if isServerless(account) {
+		serverless = "serverless"
+	}
+
+	return []driftSetting{
+		{"API", accountAPI(account)},
+		{"capacity mode", serverless},
+		{"capabilities", strings.Join(capabilities, ", ")},
+		{"consistency", consistency},
+		{"regions (failover priority)", strings.Join(regions, ", ")},
+		{"multi-region writes", fmt.Sprint(p.EnableMultipleWriteLocations != nil && *p.EnableMultipleWriteLocations)},
+		{"automatic failover", fmt.Sprint(p.EnableAutomaticFailover != nil && *p.EnableAutomaticFailover)},
+		{"public network access", enumValue(p.PublicNetworkAccess)},
+		{"IP rules", strings.Join(ipRules, ", ")},
+		{"virtual network filter", fmt.Sprint(p.IsVirtualNetworkFilterEnabled != nil && *p.IsVirtualNetworkFilterEnabled)},
+		{"virtual network rules", strings.Join(subnets, ", ")},
+		{"network ACL bypass", enumValue(p.NetworkACLBypass)},
+		{"private endpoints", fmt.Sprint(len(p.PrivateEndpointConnections))},
+		{"minimal TLS version", enumValue(p.MinimalTLSVersion)},
+		{"local auth disabled", fmt.Sprint(p.DisableLocalAuth != nil && *p.DisableLocalAuth)},
+		{"key-based metadata writes disabled", fmt.Sprint(p.DisableKeyBasedMetadataWriteAccess != nil && *p.DisableKeyBasedMetadataWriteAccess)},
+		{"customer-managed key", fmt.Sprint(stringValue(p.KeyVaultKeyURI) != "")},
+		{"backup", backup},
+		{"analytical storage", fmt.Sprint(p.EnableAnalyticalStorage != nil && *p.EnableAnalyticalStorage)},
+		{"burst capacity", fmt.Sprint(p.EnableBurstCapacity != nil && *p.EnableBurstCapacity)},
+		{"partition merge", fmt.Sprint(p.EnablePartitionMerge != nil && *p.EnablePartitionMerge)},
+		{"free tier", fmt.Sprint(p.EnableFreeTier != nil && *p.EnableFreeTier)},
+	}
+}
+
+// containerDriftSettings returns a container's definition as comparable settings; policies are compared as JSON.
+func containerDriftSettings(r *armcosmos.SQLContainerGetPropertiesResource) []driftSetting {
+	asJSON := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil || string(data) == "null" {
+			return ""
+		}
+		return string(data)
+	}
+	partitionKey := ""
+	if r.PartitionKey != nil {
+		partitionKey = fmt.Sprintf("%s (%s, v%s)", strings.Join(stringSlice(r.PartitionKey.Paths), ", "), enumValue(r.PartitionKey.Kind), int32Value(r.PartitionKey.Version))
+	}
+	ttl := "off"
+	if r.DefaultTTL != nil {
+		ttl = fmt.Sprint(*r.DefaultTTL)
+	}
+	analyticalTTL := "off"
+	if r.AnalyticalStorageTTL != nil {
+		analyticalTTL = fmt.Sprint(*r.AnalyticalStorageTTL)
+	}
+	var uniqueKeys []string
+	if r.UniqueKeyPolicy != nil {
+		for _, k := range r.UniqueKeyPolicy.UniqueKeys {
+			if k != nil {
+				uniqueKeys = append(uniqueKeys, "["+strings.Join(stringSlice(k.Paths), ", ")+"]")
+			}
+		}
+	}
+	sort.Strings(uniqueKeys)
+
+	return []driftSetting{
+		{"partition key", partitionKey},
+		{"default TTL", ttl},
+		{"analytical TTL", analyticalTTL},
+		{"unique keys", strings.Join(uniqueKeys, " ")},
+		{"indexing policy", asJSON(r.IndexingPolicy)},
+		{"conflict resolution", asJSON(r.ConflictResolutionPolicy)},
+		{"computed properties", asJSON(r.ComputedProperties)},
+		{"vector embedding policy", asJSON(r.VectorEmbeddingPolicy)},
+		{"full-text policy", asJSON(r.FullTextPolicy)},
+	}
+}
+
+// diffResources adds a row for every item only one side has, and the differing settings of items both have.
+func diffResources(table *reportTable, a map[string][]driftSetting, b map[string][]driftSetting) {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		settingsA, inA := a[name]
+		settingsB, inB := b[name]
+		switch {
+		case !inA:
+			table.addRow(name, "exists", "(missing)", "yes")
+		case !inB:
+			table.addRow(name, "exists", "yes", "(missing)")
+		default:
+			diffSettings(table, name, settingsA, settingsB)
+		}
+	}
+}
+
+// diffSettings adds a row for each setting whose value differs between the two sides.
+func diffSettings(table *reportTable, item string, a []driftSetting, b []driftSetting) {
+	values := make(map[string]string, len(b))
+	for _, s := range b {
+		values[s.name] = s.value
+	}
+	for _, s := range a {
+		if other := values[s.name]; other != s.value {
+			table.addRow(item, s.name, orDash(s.value), orDash(other))
+		}
+	}
+}
+
+// stringSlice dereferences a slice of string pointers, skipping nils.
+func stringSlice(values []*string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			result = append(result, *v)
+		}
+	}
+	return result
+}
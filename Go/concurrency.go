@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// withIfMatch returns a context that sends If-Match: etag with each request, so an update only applies if the
+// resource hasn't changed since it was read. Use it for the Begin* call only: the poller must not send the header
+// when it checks the operation status. A nil or empty etag leaves the request unconditional.
+func withIfMatch(ctx context.Context, etag *string) context.Context {
+	if etag == nil || *etag == "" {
+		return ctx
+	}
+	return policy.WithHTTPHeader(ctx, http.Header{"If-Match": []string{*etag}})
+}
+
+// withIfNoneMatch returns a context that sends If-None-Match: * with each request, so a PUT only creates the resource
+// and fails if it already exists. Like withIfMatch, use it for the Begin* call only.
+func withIfNoneMatch(ctx context.Context) context.Context {
+	return policy.WithHTTPHeader(ctx, http.Header{"If-None-Match": []string{"*"}})
+}
+
+// updateContext returns the context for a create-or-update PUT of a resource that was read with the given ETag (nil
+// when it doesn't exist): If-None-Match: * with -create-only, otherwise If-Match on the ETag.
+func updateContext(ctx context.Context, etag *string) context.Context {
+	if *createOnly {
+		return withIfNoneMatch(ctx)
+	}
+	return withIfMatch(ctx, etag)
+}
+
+// describeConcurrencyError turns a 412 Precondition Failed into a message saying someone else changed the resource.
+func describeConcurrencyError(err error, resource string) error {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusPreconditionFailed {
+		return fmt.Errorf("%s was created or modified by someone else after it was read (precondition failed); nothing was changed. Review the current configuration and re-run: %w", resource, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// defaultPromptAccountNamePrefix is used when the user leaves AccountName empty at the prompt.
+const defaultPromptAccountNamePrefix = "cosmos-sample"
+
+var (
+	subscriptionIDPattern    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	resourceGroupNamePattern = regexp.MustCompile(`^[-\w._()]{1,90}$`)
+	accountNamePattern       = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,42}[a-z0-9]$`)
+	locationPattern          = regexp.MustCompile(`^[a-z0-9]+$`)
+	resourceNamePattern      = regexp.MustCompile(`^[^/\\#?]{1,255}$`)
+)
+
+// requiredSetting is a required config.json setting that can be entered interactively when it is missing.
+type requiredSetting struct {
+	label        string
+	defaultValue func() string
+	validate     func(string) error
+}
+
+// requiredSettings maps each required config.json key to how it is prompted for.
+var requiredSettings = map[string]requiredSetting{
+	"SubscriptionId": {
+		label:        "Azure subscription ID",
+		defaultValue: func() string { return os.Getenv("AZURE_SUBSCRIPTION_ID") },
+		validate:     patternValidator(subscriptionIDPattern, "a GUID such as 00000000-0000-0000-0000-000000000000"),
+	},
+	"ResourceGroupName": {
+		label:        "Resource group name",
+		defaultValue: func() string { return "" },
+		validate:     patternValidator(resourceGroupNamePattern, "1-90 letters, digits, underscores, hyphens, periods, or parentheses"),
+	},
+	"AccountName": {
+		label:        "Cosmos DB account name (leave empty to generate a unique one)",
+		defaultValue: func() string { return "" },
+		validate: func(v string) error {
+			if v == "" {
+				return nil
+			}
+			return patternValidator(accountNamePattern, "3-44 lower-case letters, digits, and hyphens, not starting or ending with a hyphen")(v)
+		},
+	},
+	"Location": {
+		label:        "Azure region",
+		defaultValue: func() string { return "eastus" },
+		validate:     patternValidator(locationPattern, "a region name such as eastus or westeurope"),
+	},
+	"DatabaseName": {
+		label:        "Database name",
+		defaultValue: func() string { return "database1" },
+		validate:     patternValidator(resourceNamePattern, "1-255 characters, without /, \\, #, or ?"),
+	},
+	"ContainerName": {
+		label:        "Container name",
+		defaultValue: func() string { return "container1" },
+		validate:     patternValidator(resourceNamePattern, "1-255 characters, without /, \\, #, or ?"),
+	},
+	"MaxAutoScaleThroughput": {
+		label:        "Container autoscale max throughput (RU/s)",
+		defaultValue: func() string { return strconv.Itoa(minAutoscaleMaxThroughput) },
+		validate: func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("enter a number")
+			}
+			return validateMaxAutoScaleThroughput(n)
+		},
+	},
+}
+
+// promptForMissingSettings asks for each missing required setting on the terminal, re-prompting until the value is
+// valid, and stores the answers in viper so the rest of loadConfiguration reads them like config.json values. It
+// then prints the answers as JSON so they can be added to config.json.
+func promptForMissingSettings(missing []string) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println("Some required settings are missing from config.json. Enter them now (press Enter to accept the default).")
+
+	entered := make([]string, 0, len(missing))
+	for _, key := range missing {
+		setting := requiredSettings[key]
+		value, err := promptSetting(reader, setting)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+
+		switch {
+		case key == "AccountName" && value == "":
+			viper.Set("AccountNamePrefix", defaultPromptAccountNamePrefix)
+			entered = append(entered, fmt.Sprintf("  %q: %q", "AccountNamePrefix", defaultPromptAccountNamePrefix))
+		case key == "MaxAutoScaleThroughput":
+			n, _ := strconv.Atoi(value)
+			viper.Set(key, n)
+			entered = append(entered, fmt.Sprintf("  %q: %d", key, n))
+		default:
+			viper.Set(key, value)
+			entered = append(entered, fmt.Sprintf("  %q: %q", key, value))
+		}
+	}
+
+	fmt.Println("To skip these prompts next time, add the following to config.json:")
+	fmt.Printf("{\n%s\n}\n", strings.Join(entered, ",\n"))
+	return nil
+}
+
+// promptSetting reads one value, falling back to the default on empty input and asking again until it validates.
+func promptSetting(reader *bufio.Reader, setting requiredSetting) (string, error) {
+	defaultValue := setting.defaultValue()
+	for {
+		if defaultValue != "" {
+			fmt.Printf("%s (default %s): ", setting.label, defaultValue)
+		} else {
+			fmt.Printf("%s: ", setting.label)
+		}
+		raw, err := reader.ReadString('\n')
+		value := strings.TrimSpace(raw)
+		if value == "" {
+			value = defaultValue
+		}
+		if validateErr := setting.validate(value); validateErr != nil {
+			if err != nil {
+				// Out of input: there is no point asking again.
+				return "", validateErr
+			}
+			fmt.Printf("  Invalid value: %v\n", validateErr)
+			continue
+		}
+		return value, nil
+	}
+}
+
+func patternValidator(pattern *regexp.Regexp, description string) func(string) error {
+	return func(v string) error {
+		if !pattern.MatchString(v) {
+			return fmt.Errorf("expected %s (got %q)", description, v)
+		}
+		return nil
+	}
+}
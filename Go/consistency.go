@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+	"github.com/spf13/viper"
+)
+
+// Bounded staleness limits. Accounts with more than one region need a larger minimum lag, so a regional outage can't
+// stall writes while the other regions catch up.
+const (
+	singleRegionMinStalenessPrefix   = 10
+	multiRegionMinStalenessPrefix    = 100000
+	maxStalenessPrefixLimit          = math.MaxInt32
+	singleRegionMinIntervalInSeconds = 5
+	multiRegionMinIntervalInSeconds  = 300
+	maxIntervalInSecondsLimit        = 86400
+
+	// Used when DefaultConsistencyLevel is BoundedStaleness and the bounds aren't set; valid for any number of regions.
+	defaultMaxStalenessPrefix   = multiRegionMinStalenessPrefix
+	defaultMaxIntervalInSeconds = multiRegionMinIntervalInSeconds
+)
+
+// loadConsistencySettings reads DefaultConsistencyLevel, MaxStalenessPrefix, and MaxIntervalInSeconds. With an empty
+// level no consistency policy is sent (new accounts get Session).
+func loadConsistencySettings() error {
+	consistencyLevel = ""
+	if raw := strings.TrimSpace(viper.GetString("DefaultConsistencyLevel")); raw != "" {
+		for _, level := range armcosmos.PossibleDefaultConsistencyLevelValues() {
+			if strings.EqualFold(raw, string(level)) {
+				consistencyLevel = level
+			}
+		}
+		if consistencyLevel == "" {
+			return fmt.Errorf("DefaultConsistencyLevel must be one of %v (got %q)", armcosmos.PossibleDefaultConsistencyLevelValues(), raw)
+		}
+	}
+
+	boundsSet := viper.IsSet("MaxStalenessPrefix") || viper.IsSet("MaxIntervalInSeconds")
+	if consistencyLevel != armcosmos.DefaultConsistencyLevelBoundedStaleness {
+		if boundsSet {
+			return fmt.Errorf("MaxStalenessPrefix and MaxIntervalInSeconds only apply when DefaultConsistencyLevel is BoundedStaleness")
+		}
+		return nil
+	}
+
+	viper.SetDefault("MaxStalenessPrefix", defaultMaxStalenessPrefix)
+	viper.SetDefault("MaxIntervalInSeconds", defaultMaxIntervalInSeconds)
+	maxStalenessPrefix = viper.GetInt64("MaxStalenessPrefix")
+	maxIntervalInSeconds = viper.GetInt64("MaxIntervalInSeconds")
+	return nil
+}
+
+// validateConsistencySettings checks the bounded staleness bounds against the limits for an account with the given
+// number of regions.
+func validateConsistencySettings(regions int) error {
+	if consistencyLevel != armcosmos.DefaultConsistencyLevelBoundedStaleness {
+		return nil
+	}
+
+	minPrefix, minInterval, kind := int64(singleRegionMinStalenessPrefix), int64(singleRegionMinIntervalInSeconds), "single-region"
+	if regions > 1 {
+		minPrefix, minInterval, kind = multiRegionMinStalenessPrefix, multiRegionMinIntervalInSeconds, "multi-region"
+	}
+	if maxStalenessPrefix < minPrefix || maxStalenessPrefix > maxStalenessPrefixLimit {
+		return fmt.Errorf("MaxStalenessPrefix must be between %d and %d for a %s account (got %d)", minPrefix, maxStalenessPrefixLimit, kind, maxStalenessPrefix)
+	}
+	if maxIntervalInSeconds < minInterval || maxIntervalInSeconds > maxIntervalInSecondsLimit {
+		return fmt.Errorf("MaxIntervalInSeconds must be between %d and %d for a %s account (got %d)", minInterval, maxIntervalInSecondsLimit, kind, maxIntervalInSeconds)
+	}
+	return nil
+}
+
+// accountConsistencyPolicy returns the consistency policy to send with the account, or nil when none is configured.
+func accountConsistencyPolicy() *armcosmos.ConsistencyPolicy {
+	if consistencyLevel == "" {
+		return nil
+	}
+	policy := &armcosmos.ConsistencyPolicy{DefaultConsistencyLevel: to.Ptr(consistencyLevel)}
+	if consistencyLevel == armcosmos.DefaultConsistencyLevelBoundedStaleness {
+		policy.MaxStalenessPrefix = to.Ptr(maxStalenessPrefix)
+		policy.MaxIntervalInSeconds = to.Ptr(int32(maxIntervalInSeconds))
+	}
+	return policy
+}
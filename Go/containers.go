@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/spf13/viper"
+)
+
+// containerConfig is one entry of the Containers setting.
+type containerConfig struct {
+	Name                   string
+	PartitionKeyPaths      []string
+	DefaultTTL             *int32
+	IncludedPaths          []string
+	ExcludedPaths          []string
+	IndexingPolicyPath     string
+	UniqueKeyPaths         []string
+	UniqueKeys             [][]string
+	MaxAutoScaleThroughput int
+	Throughput             int
+
+	indexingPolicy *armcosmos.IndexingPolicy
+}
+
+// uniqueKeys returns the container's unique key constraints: each entry of UniqueKeys, or UniqueKeyPaths as a single
+// (possibly composite) key.
+func (c containerConfig) uniqueKeys() [][]string {
+	if len(c.UniqueKeyPaths) > 0 {
+		return [][]string{c.UniqueKeyPaths}
+	}
+	return c.UniqueKeys
+}
+
+// defaultContainerConfig is the container created when Containers isn't set: ContainerName with a hierarchical
+// partition key that matches the sample's documents.
+func defaultContainerConfig() containerConfig {
+	return containerConfig{
+		Name:                   containerName,
+		PartitionKeyPaths:      []string{"/companyId", "/departmentId", "/userId"},
+		DefaultTTL:             to.Ptr[int32](-1),
+		UniqueKeyPaths:         []string{"/userId"},
+		MaxAutoScaleThroughput: maxAutoScaleThroughput,
+	}
+}
+
+// loadContainerConfigs reads the top-level Containers setting. Without it, the sample creates the single default
+// container (ContainerName).
+func loadContainerConfigs() ([]containerConfig, error) {
+	if !viper.IsSet("Containers") {
+		if containerName == "" {
+			return nil, fmt.Errorf("set ContainerName or Containers")
+		}
+		list := []containerConfig{defaultContainerConfig()}
+		if err := loadContainerIndexingPolicies("ContainerName", list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	var list []containerConfig
+	if err := viper.UnmarshalKey("Containers", &list); err != nil {
+		return nil, fmt.Errorf("failed to read Containers: %w", err)
+	}
+	if err := normalizeContainerConfigs("Containers", list, maxAutoScaleThroughput); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// normalizeContainerConfigs validates a list of containers and gives the ones without throughput settings an
+// autoscale max of defaultAutoscaleMax (0 leaves them on the database's shared throughput).
+func normalizeContainerConfigs(setting string, list []containerConfig, defaultAutoscaleMax int) error {
+	if len(list) == 0 {
+		return fmt.Errorf("%s must contain at least one container", setting)
+	}
+
+	seen := map[string]bool{}
+	for i := range list {
+		c := &list[i]
+		c.Name = strings.TrimSpace(c.Name)
+		if err := validateContainerConfig(*c); err != nil {
+			return fmt.Errorf("%s[%d]: %w", setting, i, err)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("%s[%d]: duplicate container name %q", setting, i, c.Name)
+		}
+		seen[c.Name] = true
+		if c.MaxAutoScaleThroughput == 0 && c.Throughput == 0 {
+			c.MaxAutoScaleThroughput = defaultAutoscaleMax
+		}
+	}
+	return loadContainerIndexingPolicies(setting, list)
+}
+
+// loadContainerIndexingPolicies loads each container's IndexingPolicyPath file, falling back to the top-level
+// IndexingPolicyPath for containers that don't set their own indexing paths.
+func loadContainerIndexingPolicies(setting string, list []containerConfig) error {
+	for i := range list {
+		c := &list[i]
+		path := c.IndexingPolicyPath
+		if path == "" && len(c.IncludedPaths) == 0 && len(c.ExcludedPaths) == 0 {
+			path = indexingPolicyPath
+		}
+		if path == "" {
+			continue
+		}
+		policy, err := loadIndexingPolicy(path)
+		if err != nil {
+			return fmt.Errorf("%s[%d]: %w", setting, i, err)
+		}
+		c.indexingPolicy = policy
+	}
+	return nil
+}
+
+// selectPrimaryContainer defaults ContainerName to the first container of the primary database and checks that it is
+// one of them. The primary container is the one the rest of the sample (throughput update, data-plane check, seeding,
+// metrics) works with.
+func selectPrimaryContainer(list []containerConfig) error {
+	if containerName == "" {
+		containerName = list[0].Name
+		return nil
+	}
+	for _, c := range list {
+		if c.Name == containerName {
+			return nil
+		}
+	}
+	return fmt.Errorf("ContainerName %q is not one of the containers of database %s", containerName, databaseName)
+}
+
+func validateContainerConfig(c containerConfig) error {
+	if c.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if len(c.PartitionKeyPaths) == 0 || len(c.PartitionKeyPaths) > 3 {
+		return fmt.Errorf("PartitionKeyPaths must have 1 to 3 paths (got %d)", len(c.PartitionKeyPaths))
+	}
+	for _, p := range c.PartitionKeyPaths {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("partition key path %q must start with /", p)
+		}
+	}
+	if c.DefaultTTL != nil && (*c.DefaultTTL == 0 || *c.DefaultTTL < -1) {
+		return fmt.Errorf("DefaultTtl must be -1 (no default expiry) or a positive number of seconds (got %d)", *c.DefaultTTL)
+	}
+	if c.IndexingPolicyPath != "" && (len(c.IncludedPaths) > 0 || len(c.ExcludedPaths) > 0) {
+		return fmt.Errorf("set IndexingPolicyPath or IncludedPaths/ExcludedPaths, not both")
+	}
+	if len(c.UniqueKeyPaths) > 0 && len(c.UniqueKeys) > 0 {
+		return fmt.Errorf("set UniqueKeyPaths or UniqueKeys, not both")
+	}
+	seenKeys := map[string]bool{}
+	for i, key := range c.uniqueKeys() {
+		if len(key) == 0 {
+			return fmt.Errorf("UniqueKeys[%d] must have at least one path", i)
+		}
+		for _, p := range key {
+			if !strings.HasPrefix(p, "/") {
+				return fmt.Errorf("unique key path %q must start with /", p)
+			}
+		}
+		joined := strings.Join(key, ",")
+		if seenKeys[joined] {
+			return fmt.Errorf("unique key %v is listed more than once", key)
+		}
+		seenKeys[joined] = true
+	}
+	if c.MaxAutoScaleThroughput != 0 && c.Throughput != 0 {
+		return fmt.Errorf("set MaxAutoScaleThroughput or Throughput, not both")
+	}
+	if c.MaxAutoScaleThroughput != 0 {
+		if err := validateMaxAutoScaleThroughput(c.MaxAutoScaleThroughput); err != nil {
+			return err
+		}
+	}
+	if c.Throughput != 0 && (c.Throughput < minManualThroughput || c.Throughput%100 != 0) {
+		return fmt.Errorf("Throughput must be a multiple of 100 and at least %d RU/s (got %d)", minManualThroughput, c.Throughput)
+	}
+	return nil
+}
+
+// sqlContainerCreateUpdateParameters builds the create/update request for a configured container.
+func sqlContainerCreateUpdateParameters(c containerConfig) armcosmos.SQLContainerCreateUpdateParameters {
+	partitionKey := &armcosmos.ContainerPartitionKey{
+		Paths:   to.SliceOfPtrs(c.PartitionKeyPaths...),
+		Kind:    to.Ptr(armcosmos.PartitionKindHash),
+		Version: to.Ptr[int32](2),
+	}
+	if len(c.PartitionKeyPaths) > 1 {
+		partitionKey.Kind = to.Ptr(armcosmos.PartitionKindMultiHash)
+	}
+
+	resource := &armcosmos.SQLContainerResource{
+		ID:             to.Ptr(c.Name),
+		DefaultTTL:     c.DefaultTTL,
+		PartitionKey:   partitionKey,
+		IndexingPolicy: containerIndexingPolicy(c),
+		ConflictResolutionPolicy: &armcosmos.ConflictResolutionPolicy{
+			Mode:                   to.Ptr(armcosmos.ConflictResolutionModeLastWriterWins),
+			ConflictResolutionPath: to.Ptr("/_ts"),
+		},
+	}
+	if keys := c.uniqueKeys(); len(keys) > 0 {
+		resource.UniqueKeyPolicy = &armcosmos.UniqueKeyPolicy{}
+		for _, key := range keys {
+			resource.UniqueKeyPolicy.UniqueKeys = append(resource.UniqueKeyPolicy.UniqueKeys, &armcosmos.UniqueKey{Paths: to.SliceOfPtrs(key...)})
+		}
+	}
+
+	options := &armcosmos.CreateUpdateOptions{}
+	if c.MaxAutoScaleThroughput > 0 {
+		options.AutoscaleSettings = &armcosmos.AutoscaleSettings{MaxThroughput: to.Ptr(int32(c.MaxAutoScaleThroughput))}
+	} else if c.Throughput > 0 {
+		options.Throughput = to.Ptr(int32(c.Throughput))
+	}
+
+	return armcosmos.SQLContainerCreateUpdateParameters{
+		Location: &location,
+		Properties: &armcosmos.SQLContainerCreateUpdateProperties{
+			Resource: resource,
+			Options:  options,
+		},
+	}
+}
+
+// getSQLContainerEtag returns the container's current ETag, or nil when it doesn't exist yet.
+func getSQLContainerEtag(ctx context.Context, containerClient *armcosmos.SQLResourcesClient, database string, container string) (*string, error) {
+	existing, err := containerClient.GetSQLContainer(ctx, resourceGroupName, accountName, database, container, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if existing.Properties == nil || existing.Properties.Resource == nil {
+		return nil, nil
+	}
+	return existing.Properties.Resource.Etag, nil
+}
+
+// containerIndexingPolicy returns the policy loaded from IndexingPolicyPath, or a consistent policy built from
+// IncludedPaths and ExcludedPaths.
+func containerIndexingPolicy(c containerConfig) *armcosmos.IndexingPolicy {
+	if c.indexingPolicy != nil {
+		return c.indexingPolicy
+	}
+
+	includedPaths := c.IncludedPaths
+	if len(includedPaths) == 0 {
+		includedPaths = []string{"/*"}
+	}
+	excludedPaths := c.ExcludedPaths
+	if len(excludedPaths) == 0 {
+		excludedPaths = []string{"/\"_etag\"/?"}
+	}
+	indexingPolicy := &armcosmos.IndexingPolicy{
+		Automatic:    to.Ptr(true),
+		IndexingMode: to.Ptr(armcosmos.IndexingModeConsistent),
+	}
+	for _, p := range includedPaths {
+		indexingPolicy.IncludedPaths = append(indexingPolicy.IncludedPaths, &armcosmos.IncludedPath{Path: to.Ptr(p)})
+	}
+	for _, p := range excludedPaths {
+		indexingPolicy.ExcludedPaths = append(indexingPolicy.ExcludedPaths, &armcosmos.ExcludedPath{Path: to.Ptr(p)})
+	}
+	return indexingPolicy
+}
+
+// createOrUpdateCosmosDBContainer creates or updates the configured NoSQL containers (in every configured database)
+// and their throughput.
+func createOrUpdateCosmosDBContainer(ctx context.Context) {
+	containerClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db container client: %v", err)
+	}
+
+	// NOTE: Containers get the configured partition key, TTL, indexing policy (which can include vector and full-text
+	// indexes from IndexingPolicyPath), and unique keys. The sample doesn't configure computed properties or vector
+	// embedding and full-text policies, though armcosmos supports them (clone copies them from the source container).
+
+	ledger, err := newThroughputLedger(ctx, containerClient)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for _, db := range databases {
+		if _, err := containerClient.GetSQLDatabase(ctx, resourceGroupName, accountName, db.Name, nil); err != nil {
+			log.Fatalf("failed to get cosmos db database: %v", err)
+		}
+
+		for _, c := range db.Containers {
+			etag, err := getSQLContainerEtag(ctx, containerClient, db.Name, c.Name)
+			if err != nil {
+				log.Fatalf("failed to get cosmos db container %s/%s: %v", db.Name, c.Name, err)
+			}
+
+			if etag != nil && *createOnly {
+				fmt.Printf("Container %s/%s already exists; leaving it unchanged (-create-only).\n", db.Name, c.Name)
+				continue
+			}
+			if etag == nil {
+				ledger.reserveNew(db.Name+"/"+c.Name, int64(max(c.MaxAutoScaleThroughput, c.Throughput)), c.MaxAutoScaleThroughput > 0)
+			}
+
+			resource := fmt.Sprintf("Container %s/%s", db.Name, c.Name)
+			pollerResp, err := containerClient.BeginCreateUpdateSQLContainer(updateContext(ctx, etag), resourceGroupName, accountName, db.Name, c.Name, sqlContainerCreateUpdateParameters(c), nil)
+			if err != nil {
+				log.Fatalf("failed to begin create or update cosmos db container %s/%s: %v", db.Name, c.Name, describeConcurrencyError(err, resource))
+			}
+
+			resp, err := pollUntilDone(ctx, pollerResp)
+			if err != nil {
+				log.Fatalf("failed to poll the result: %v", describeConcurrencyError(err, resource))
+			}
+
+			recordResource("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers", resp.ID)
+			fmt.Printf("Created/updated Collection: %s\n", *resp.ID)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runCopyRBACCommand recreates one account's custom SQL role definitions and role assignments on another, for example
+// the green account of a blue/green deployment or the replacement of an account in a new region.
+func runCopyRBACCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("copy-rbac")
+	sourceAccount := fs.String("source-account", accountName, "Account to copy from")
+	sourceResourceGroup := fs.String("source-rg", resourceGroupName, "Resource group of -source-account")
+	targetAccount := fs.String("target-account", "", "Account to copy to, in the same subscription (required)")
+	targetResourceGroup := fs.String("target-rg", resourceGroupName, "Resource group of -target-account")
+	yes := fs.Bool("yes", false, "Make the changes; without it, only print what would change")
+	var mappings scopeMappings
+	fs.Var(&mappings, "map-scope", "Replace a scope prefix, as <old>=<new> relative to the account (repeatable), for example /dbs/orders=/dbs/orders-v2")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: copy-rbac [-source-account <name>] [-source-rg <name>] -target-account <name> [-target-rg <name>] [-map-scope <old>=<new>]... [-yes]")
+		fmt.Fprintln(fs.Output(), "Copies the custom SQL role definitions and every SQL role assignment from one account to another.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 || *targetAccount == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if strings.EqualFold(sqlAccountID(*sourceResourceGroup, *sourceAccount), sqlAccountID(*targetResourceGroup, *targetAccount)) {
+		log.Fatalf("-target-account must be a different account than %s", *sourceAccount)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role assignment client: %v", err)
+	}
+	export, err := readSQLRBAC(ctx, sqlClient, *sourceResourceGroup, *sourceAccount)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("Read %d custom role definition(s) and %d role assignment(s) from %s", len(export.RoleDefinitions), len(export.RoleAssignments), *sourceAccount)
+	applySQLRBAC(ctx, sqlClient, export, *targetResourceGroup, *targetAccount, mappings, *yes)
+}
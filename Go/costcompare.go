@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// throughputModeCosts compares what observed hourly peaks would cost under manual and autoscale throughput.
+type throughputModeCosts struct {
+	hours              int
+	peakRU             float64
+	manualRU           int
+	autoscaleMaxRU     int
+	manualMonthly      float64
+	autoscaleMonthly   float64
+	averageUtilization float64
+}
+
+// runCostCompareCommand estimates the container's monthly throughput cost under manual and autoscale provisioning
+// from its recent hourly peak RU/s, and recommends the cheaper mode.
+func runCostCompareCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("cost-compare")
+	window := fs.Duration("window", 7*24*time.Hour, "How far back to read RU consumption (a week or more covers weekly peaks)")
+	_ = fs.Parse(args)
+	if *window < time.Hour {
+		log.Fatalf("-window must be at least 1h (got %s)", *window)
+	}
+
+	provisionedRU, autoscale, err := getProvisionedThroughput(ctx)
+	if err != nil {
+		log.Fatalf("failed to read the container's throughput: %v", err)
+	}
+	regions, err := getAccountRegionCount(ctx)
+	if err != nil {
+		log.Fatalf("failed to read the account's regions: %v", err)
+	}
+
+	// NormalizedRUConsumption is the busiest partition's share of the provisioned (or autoscale max) RU/s, so scaling
+	// it by the provisioned RU/s gives the RU/s the container needed in that hour.
+	metric, err := queryAccountMetric(ctx, metricQuery{name: "NormalizedRUConsumption", aggregation: "Maximum"}, containerMetricFilter(databaseName, containerName), *window, time.Hour)
+	if err != nil {
+		log.Fatalf("failed to query RU consumption: %v", err)
+	}
+	hourlyPeaks := make([]float64, 0)
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		for _, point := range series.Data {
+			if value, ok := metricValue(point, "Maximum"); ok {
+				hourlyPeaks = append(hourlyPeaks, value/100*float64(provisionedRU))
+			}
+		}
+	}
+	if len(hourlyPeaks) == 0 {
+		log.Fatalf("No RU consumption data for %s/%s in the last %s; run some traffic first.", databaseName, containerName, *window)
+	}
+
+	prices := getRUPricesBestEffort(ctx, location)
+	costs := compareThroughputModeCosts(hourlyPeaks, regions, prices)
+
+	current := "manual"
+	if autoscale {
+		current = "autoscale"
+	}
+	fmt.Printf("Throughput cost comparison for %s/%s (%d hour(s) of data, %d region(s), prices from %s)\n", databaseName, containerName, costs.hours, regions, prices.source)
+	fmt.Printf("  Current: %s %d RU/s. Highest hourly peak: %.0f RU/s. Average hourly peak: %.0f%% of that.\n", current, provisionedRU, costs.peakRU, costs.averageUtilization*100)
+	fmt.Printf("  Manual %d RU/s:        ~%.2f %s/month\n", costs.manualRU, costs.manualMonthly, prices.currency)
+	fmt.Printf("  Autoscale max %d RU/s: ~%.2f %s/month\n", costs.autoscaleMaxRU, costs.autoscaleMonthly, prices.currency)
+	if costs.autoscaleMonthly < costs.manualMonthly {
+		fmt.Printf("Recommendation: autoscale (saves ~%.2f %s/month). Usage is spiky enough that paying 1.5x only for the hours that need it is cheaper.\n", costs.manualMonthly-costs.autoscaleMonthly, prices.currency)
+	} else {
+		fmt.Printf("Recommendation: manual (saves ~%.2f %s/month). Usage is steady enough that fixed throughput is cheaper.\n", costs.autoscaleMonthly-costs.manualMonthly, prices.currency)
+	}
+	fmt.Println("  Based on past peaks sized to the busiest partition; storage, backup, and multi-region write charges are not included.")
+}
+
+// compareThroughputModeCosts prices the observed hourly peaks. Manual throughput must be provisioned for the highest
+// peak all month; autoscale bills each hour for the highest RU/s it scaled to, but never less than 10% of the max.
+func compareThroughputModeCosts(hourlyPeaks []float64, regions int, prices ruPrices) throughputModeCosts {
+	costs := throughputModeCosts{hours: len(hourlyPeaks)}
+	for _, peak := range hourlyPeaks {
+		costs.peakRU = math.Max(costs.peakRU, peak)
+	}
+	costs.manualRU = max(minManualThroughput, int(math.Ceil(costs.peakRU/100))*100)
+	costs.autoscaleMaxRU = max(minAutoscaleMaxThroughput, int(math.Ceil(costs.peakRU/1000))*1000)
+
+	autoscaleHourlyRU := 0.0
+	for _, peak := range hourlyPeaks {
+		autoscaleHourlyRU += math.Max(float64(costs.autoscaleMaxRU)/10, math.Ceil(peak/100)*100)
+		if costs.peakRU > 0 {
+			costs.averageUtilization += peak / costs.peakRU
+		}
+	}
+	costs.averageUtilization /= float64(costs.hours)
+
+	// Scale the observed hours to a month.
+	costs.manualMonthly = float64(costs.manualRU) / 100 * prices.manualPer100RU * hoursPerMonth * float64(regions)
+	costs.autoscaleMonthly = autoscaleHourlyRU / float64(costs.hours) / 100 * prices.autoscalePer100RU * hoursPerMonth * float64(regions)
+	return costs
+}
+
+// getProvisionedThroughput returns the container's autoscale max or manual RU/s, falling back to the database's
+// shared throughput when the container has none of its own.
+func getProvisionedThroughput(ctx context.Context) (int, bool, error) {
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create cosmos db sql client: %w", err)
+	}
+
+	var properties *armcosmos.ThroughputSettingsGetProperties
+	container, err := sqlClient.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, databaseName, containerName, nil)
+	var respErr *azcore.ResponseError
+	switch {
+	case err == nil:
+		properties = container.Properties
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound:
+		database, err := sqlClient.GetSQLDatabaseThroughput(ctx, resourceGroupName, accountName, databaseName, nil)
+		if err != nil {
+			return 0, false, err
+		}
+		properties = database.Properties
+	default:
+		return 0, false, err
+	}
+
+	if properties == nil || properties.Resource == nil {
+		return 0, false, fmt.Errorf("no throughput settings returned")
+	}
+	r := properties.Resource
+	if r.AutoscaleSettings != nil && r.AutoscaleSettings.MaxThroughput != nil {
+		return int(*r.AutoscaleSettings.MaxThroughput), true, nil
+	}
+	if r.Throughput != nil {
+		return int(*r.Throughput), false, nil
+	}
+	return 0, false, fmt.Errorf("no throughput settings returned")
+}
+
+// getAccountRegionCount returns how many regions the account is replicated to.
+func getAccountRegionCount(ctx context.Context) (int, error) {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cosmos db account client: %w", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		return 0, err
+	}
+	if account.Properties == nil || len(account.Properties.Locations) == 0 {
+		return 1, nil
+	}
+	return len(account.Properties.Locations), nil
+}
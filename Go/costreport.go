@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// costReportRow is one container, or one database with shared throughput, in the cost report.
+type costReportRow struct {
+	name string
+	// mode is autoscale or manual once priced, or shared (or none, in a serverless account) for a container without
+	// its own throughput.
+	mode        string
+	ru          int
+	autoscale   bool
+	hourlyUse   map[time.Time]float64
+	storageGB   float64
+	throughput  float64
+	storage     float64
+	averageUse  float64
+	peakUse     float64
+	measuredUse bool
+}
+
+func (r costReportRow) total() float64 {
+	return r.throughput + r.storage
+}
+
+// runCostReportCommand lists every container in the account with its throughput, storage, and estimated monthly
+// cost, most expensive first.
+func runCostReportCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("cost-report")
+	window := fs.Duration("window", 7*24*time.Hour, "How far back to read RU consumption for the utilization and autoscale cost (1h-720h)")
+	report := addReportFlags(fs)
+	_ = fs.Parse(args)
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *window < time.Hour || *window > 30*24*time.Hour {
+		log.Fatalf("-window must be between 1h and 720h (got %s)", *window)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+	resources, err := listSQLInventory(ctx, sqlClient, resourceGroupName, accountName)
+	if err != nil {
+		log.Fatalf("failed to list databases and containers: %v", err)
+	}
+	regions, err := getAccountRegionCount(ctx)
+	if err != nil {
+		log.Fatalf("failed to read the account's regions: %v", err)
+	}
+	prices := getRUPricesBestEffort(ctx, location)
+
+	var rows []*costReportRow
+	sharedDatabases := map[string]*costReportRow{}
+	for _, r := range resources {
+		ru, autoscale, _, dedicated := provisionedThroughput(r.throughput)
+		if r.kind == "Database" {
+			if dedicated {
+				row := &costReportRow{name: r.name + " (shared by its containers)", ru: ru, autoscale: autoscale, hourlyUse: map[time.Time]float64{}}
+				sharedDatabases[r.name] = row
+				rows = append(rows, row)
+			}
+			continue
+		}
+
+		database, container, _ := strings.Cut(r.name, "/")
+		row := &costReportRow{name: r.name, ru: ru, autoscale: autoscale}
+		if !dedicated {
+			row.mode = "shared"
+		}
+		if row.hourlyUse, err = readHourlyUtilization(ctx, database, container, *window); err != nil {
+			log.Printf("Could not read RU consumption for %s: %v", r.name, err)
+		}
+		if row.storageGB, err = readContainerStorageGB(ctx, database, container); err != nil {
+			log.Printf("Could not read storage for %s: %v", r.name, err)
+		}
+		if !dedicated {
+			// Shared throughput is billed once, on the database; its busiest container each hour is its utilization.
+			if db := sharedDatabases[database]; db != nil {
+				for hour, use := range row.hourlyUse {
+					db.hourlyUse[hour] = math.Max(db.hourlyUse[hour], use)
+				}
+			} else {
+				row.mode = "none"
+			}
+			row.hourlyUse = nil
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("Account %s has no databases or containers.\n", accountName)
+		return
+	}
+
+	var totalMonthly float64
+	for _, row := range rows {
+		row.price(prices, regions)
+		totalMonthly += row.total()
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].total() > rows[j].total() })
+
+	currency := prices.currency
+	table := reportTable{
+		title:   fmt.Sprintf("Estimated monthly cost per container in %s (%d region(s), utilization over the last %s)", accountName, regions, *window),
+		headers: []string{"CONTAINER", "MODE", "RU/S", "AVG USE", "PEAK USE", "STORAGE GB", "THROUGHPUT " + currency, "STORAGE " + currency, "TOTAL " + currency},
+	}
+	for _, row := range rows {
+		ru, avg, peak, throughput := "-", "-", "-", "-"
+		if row.mode != "shared" && row.mode != "none" {
+			ru, throughput = fmt.Sprintf("%d", row.ru), fmt.Sprintf("%.2f", row.throughput)
+		}
+		if row.measuredUse {
+			avg, peak = fmt.Sprintf("%.0f%%", row.averageUse), fmt.Sprintf("%.0f%%", row.peakUse)
+		}
+		table.addRow(row.name, row.mode, ru, avg, peak, fmt.Sprintf("%.2f", row.storageGB), throughput, fmt.Sprintf("%.2f", row.storage), fmt.Sprintf("%.2f", row.total()))
+	}
+	table.addNote("Total: ~%.2f %s/month (prices from %s).", totalMonthly, currency, prices.source)
+	table.addNote("AVG USE and PEAK USE are the busiest partition's hourly NormalizedRUConsumption. Autoscale is priced from it (at least 10%% of the max each hour); without data, at the max every hour.")
+	table.addNote("Containers sharing their database's throughput show only their storage cost; the shared throughput is billed on the database row.")
+	table.addNote("A low AVG USE on an expensive row is a candidate for recommend-throughput. Backup, analytical storage, and multi-region write charges are not included.")
+	if err := writeReport(report, "Container cost report for "+accountName, table); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// price sets the row's mode, utilization, and monthly costs. Manual throughput bills its RU/s every hour; autoscale
+// bills each hour for the highest RU/s it scaled to, never less than 10% of the max.
+func (r *costReportRow) price(prices ruPrices, regions int) {
+	r.storage = r.storageGB * prices.storagePerGB * float64(regions)
+	if r.mode == "shared" || r.mode == "none" {
+		return
+	}
+
+	billedShare := 1.0
+	if len(r.hourlyUse) > 0 {
+		r.measuredUse = true
+		billedShare = 0
+		for _, use := range r.hourlyUse {
+			r.averageUse += use
+			r.peakUse = math.Max(r.peakUse, use)
+			billedShare += math.Max(0.1, math.Min(use, 100)/100)
+		}
+		r.averageUse /= float64(len(r.hourlyUse))
+		billedShare /= float64(len(r.hourlyUse))
+	}
+
+	units := float64(r.ru) / 100 * hoursPerMonth * float64(regions)
+	if r.autoscale {
+		r.mode = "autoscale"
+		r.throughput = units * billedShare * prices.autoscalePer100RU
+	} else {
+		r.mode = "manual"
+		r.throughput = units * prices.manualPer100RU
+	}
+}
+
+// readHourlyUtilization returns the container's busiest-partition NormalizedRUConsumption (0-100) for each hour of
+// the window that has data.
+func readHourlyUtilization(ctx context.Context, database string, container string, window time.Duration) (map[time.Time]float64, error) {
+	metric, err := queryAccountMetric(ctx, metricQuery{name: "NormalizedRUConsumption", aggregation: "Maximum"}, containerMetricFilter(database, container), window, time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	hourly := map[time.Time]float64{}
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		for _, point := range series.Data {
+			if value, ok := metricValue(point, "Maximum"); ok && point.TimeStamp != nil {
+				hourly[*point.TimeStamp] = math.Max(hourly[*point.TimeStamp], value)
+			}
+		}
+	}
+	return hourly, nil
+}
+
+// readContainerStorageGB returns the container's latest DataUsage plus IndexUsage, in GB.
+func readContainerStorageGB(ctx context.Context, database string, container string) (float64, error) {
+	var bytes float64
+	for _, name := range []string{"DataUsage", "IndexUsage"} {
+		metric, err := queryAccountMetric(ctx, metricQuery{name: name, aggregation: "Total"}, containerMetricFilter(database, container), 2*time.Hour, 5*time.Minute)
+		if err != nil {
+			return 0, err
+		}
+		value, _ := latestMetricValue(metric, "Total")
+		bytes += value
+	}
+	return bytes / (1 << 30), nil
+}
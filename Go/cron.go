@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression (minute hour day-of-month month day-of-week).
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// anyDay and anyWeekday record a "*" field: as in cron, when both day fields are restricted a time matches if
+	// either does.
+	anyDay, anyWeekday bool
+}
+
+// cronField is the allowed range and names of one cron field.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// parseCron parses a cron expression with *, lists (1,5), ranges (1-5), steps (*/15, 8-18/2), and three-letter month
+// and day names. Day of week 0 and 7 are both Sunday.
+func parseCron(expr string) (cronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return cronSchedule{}, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+	sets := make([]map[int]bool, len(cronFields))
+	for i, field := range cronFields {
+		set, err := parseCronField(parts[i], field)
+		if err != nil {
+			return cronSchedule{}, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+	return cronSchedule{
+		minutes: sets[0], hours: sets[1], days: sets[2], months: sets[3], weekdays: sets[4],
+		anyDay: strings.HasPrefix(parts[2], "*"), anyWeekday: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(text string, field cronField) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s step %q", field.name, stepText)
+			}
+			step = n
+		}
+
+		low, high := field.min, field.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = cronValue(lowText, field); err != nil {
+				return nil, err
+			}
+			high = low
+			if isRange {
+				if high, err = cronValue(highText, field); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end of the range, every 15.
+				high = field.max
+			}
+			if high < low {
+				return nil, fmt.Errorf("invalid %s range %q", field.name, rangeText)
+			}
+		}
+		for v := low; v <= high; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a number or name; names start at the field's minimum (Sunday is 0, January is 1).
+func cronValue(text string, field cronField) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(text, name) {
+			return field.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("invalid %s %q (expected %d-%d)", field.name, text, field.min, field.max)
+	}
+	return v, nil
+}
+
+// matches reports whether the schedule fires in the minute of t.
+func (c cronSchedule) matches(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := c.days[t.Day()], c.weekdays[int(t.Weekday())]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// previous returns the latest time at or before t when the schedule fired, looking back at most a year.
+func (c cronSchedule) previous(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	for earliest := t.AddDate(-1, 0, 0); !t.Before(earliest); {
+		if !c.months[int(t.Month())] {
+			// Jump to the last minute of the previous month.
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(-time.Minute)
+			continue
+		}
+		if c.matches(t) {
+			return t, true
+		}
+		t = t.Add(-time.Minute)
+	}
+	return time.Time{}, false
+}
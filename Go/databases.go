@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/spf13/viper"
+)
+
+// databaseConfig is one entry of the Databases setting. Setting MaxAutoScaleThroughput or Throughput provisions
+// shared throughput on the database; its containers without their own throughput settings then share it.
+type databaseConfig struct {
+	Name                   string
+	MaxAutoScaleThroughput int
+	Throughput             int
+	Containers             []containerConfig
+}
+
+// sharedThroughput reports whether the database has shared (database-level) throughput.
+func (d databaseConfig) sharedThroughput() bool {
+	return d.MaxAutoScaleThroughput > 0 || d.Throughput > 0
+}
+
+// loadDatabaseConfigs reads the Databases setting. Without it, the sample creates DatabaseName with the top-level
+// containers. With it, DatabaseName defaults to the first entry; that primary database uses its own Containers or,
+// when it has none, the top-level Containers (or ContainerName).
+func loadDatabaseConfigs() error {
+	if !viper.IsSet("Databases") {
+		list, err := loadContainerConfigs()
+		if err != nil {
+			return err
+		}
+		databases = []databaseConfig{{Name: databaseName, Containers: list}}
+		return selectPrimaryContainer(list)
+	}
+
+	// Start from an empty list: promote loads the configuration a second time.
+	databases = nil
+	if err := viper.UnmarshalKey("Databases", &databases); err != nil {
+		return fmt.Errorf("failed to read Databases: %w", err)
+	}
+	if len(databases) == 0 {
+		return fmt.Errorf("Databases must contain at least one database")
+	}
+
+	seen := map[string]bool{}
+	for i := range databases {
+		db := &databases[i]
+		db.Name = strings.TrimSpace(db.Name)
+		if err := validateDatabaseConfig(*db); err != nil {
+			return fmt.Errorf("Databases[%d]: %w", i, err)
+		}
+		if seen[db.Name] {
+			return fmt.Errorf("Databases[%d]: duplicate database name %q", i, db.Name)
+		}
+		seen[db.Name] = true
+
+		if len(db.Containers) > 0 {
+			defaultAutoscaleMax := maxAutoScaleThroughput
+			if db.sharedThroughput() {
+				defaultAutoscaleMax = 0
+			}
+			if err := normalizeContainerConfigs(fmt.Sprintf("Databases[%d].Containers", i), db.Containers, defaultAutoscaleMax); err != nil {
+				return err
+			}
+		}
+	}
+
+	if databaseName == "" {
+		databaseName = databases[0].Name
+	}
+	for i := range databases {
+		if databases[i].Name != databaseName {
+			continue
+		}
+		primary := &databases[i]
+		if len(primary.Containers) == 0 {
+			list, err := loadContainerConfigs()
+			if err != nil {
+				return err
+			}
+			primary.Containers = list
+		} else if viper.IsSet("Containers") {
+			return fmt.Errorf("set Containers at the top level or in the %s entry of Databases, not both", databaseName)
+		}
+		return selectPrimaryContainer(primary.Containers)
+	}
+	return fmt.Errorf("DatabaseName %q is not one of the Databases", databaseName)
+}
+
+func validateDatabaseConfig(d databaseConfig) error {
+	if d.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if d.MaxAutoScaleThroughput != 0 && d.Throughput != 0 {
+		return fmt.Errorf("set MaxAutoScaleThroughput or Throughput, not both")
+	}
+	if d.MaxAutoScaleThroughput != 0 {
+		if err := validateMaxAutoScaleThroughput(d.MaxAutoScaleThroughput); err != nil {
+			return err
+		}
+	}
+	if d.Throughput != 0 && (d.Throughput < minManualThroughput || d.Throughput%100 != 0) {
+		return fmt.Errorf("Throughput must be a multiple of 100 and at least %d RU/s (got %d)", minManualThroughput, d.Throughput)
+	}
+	return nil
+}
+
+// createOrUpdateCosmosDBDatabase creates or updates the configured SQL databases, with shared throughput where set.
+func createOrUpdateCosmosDBDatabase(ctx context.Context) {
+	databaseClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db database client: %v", err)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	if _, err := accountClient.Get(ctx, resourceGroupName, accountName, nil); err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	ledger, err := newThroughputLedger(ctx, databaseClient)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	for _, db := range databases {
+		properties := armcosmos.SQLDatabaseCreateUpdateParameters{
+			Location: &location,
+			Properties: &armcosmos.SQLDatabaseCreateUpdateProperties{
+				Resource: &armcosmos.SQLDatabaseResource{ID: to.Ptr(db.Name)},
+			},
+		}
+		if db.MaxAutoScaleThroughput > 0 {
+			properties.Properties.Options = &armcosmos.CreateUpdateOptions{
+				AutoscaleSettings: &armcosmos.AutoscaleSettings{MaxThroughput: to.Ptr(int32(db.MaxAutoScaleThroughput))},
+			}
+		} else if db.Throughput > 0 {
+			properties.Properties.Options = &armcosmos.CreateUpdateOptions{Throughput: to.Ptr(int32(db.Throughput))}
+		}
+
+		etag, err := getSQLDatabaseEtag(ctx, databaseClient, db.Name)
+		if err != nil {
+			log.Fatalf("failed to get cosmos db database %s: %v", db.Name, err)
+		}
+
+		if etag != nil && *createOnly {
+			fmt.Printf("Database %s already exists; leaving it unchanged (-create-only).\n", db.Name)
+			continue
+		}
+		if etag == nil {
+			ledger.reserveNew(db.Name, int64(max(db.MaxAutoScaleThroughput, db.Throughput)), db.MaxAutoScaleThroughput > 0)
+		}
+
+		resource := "Database " + db.Name
+		pollerResp, err := databaseClient.BeginCreateUpdateSQLDatabase(updateContext(ctx, etag), resourceGroupName, accountName, db.Name, properties, nil)
+		if err != nil {
+			log.Fatalf("failed to begin create or update cosmos db database %s: %v", db.Name, describeConcurrencyError(err, resource))
+		}
+
+		resp, err := pollUntilDone(ctx, pollerResp)
+		if err != nil {
+			log.Fatalf("failed to poll the result: %v", describeConcurrencyError(err, resource))
+		}
+
+		recordResource("Microsoft.DocumentDB/databaseAccounts/sqlDatabases", resp.ID)
+		fmt.Printf("Created/updated Database: %s\n", *resp.ID)
+	}
+}
+
+// getSQLDatabaseEtag returns the database's current ETag, or nil when it doesn't exist yet.
+func getSQLDatabaseEtag(ctx context.Context, databaseClient *armcosmos.SQLResourcesClient, database string) (*string, error) {
+	existing, err := databaseClient.GetSQLDatabase(ctx, resourceGroupName, accountName, database, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if existing.Properties == nil || existing.Properties.Resource == nil {
+		return nil, nil
+	}
+	return existing.Properties.Resource.Etag, nil
+}
@@ -0,0 +1,584 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// Data transfer jobs are only available in preview api-versions of the Cosmos DB resource provider.
+const dataTransferAPIVersion = "2024-12-01-preview"
+
+// cosmosDataReaderRoleDefinitionID is the name of the built-in Cosmos DB Built-in Data Reader SQL role definition.
+const cosmosDataReaderRoleDefinitionID = "00000000-0000-0000-0000-000000000001"
+
+// dataTransferJob is a container copy job on the account.
+type dataTransferJob struct {
+	ID         *string                    `json:"id,omitempty"`
+	Name       *string                    `json:"name,omitempty"`
+	Properties *dataTransferJobProperties `json:"properties,omitempty"`
+}
+
+type dataTransferJobProperties struct {
+	Source             *dataTransferDataSource `json:"source,omitempty"`
+	Destination        *dataTransferDataSource `json:"destination,omitempty"`
+	Mode               *string                 `json:"mode,omitempty"`
+	JobName            *string                 `json:"jobName,omitempty"`
+	Status             *string                 `json:"status,omitempty"`
+	ProcessedCount     *int64                  `json:"processedCount,omitempty"`
+	TotalCount         *int64                  `json:"totalCount,omitempty"`
+	LastUpdatedUTCTime *time.Time              `json:"lastUpdatedUtcTime,omitempty"`
+	Duration           *string                 `json:"duration,omitempty"`
+	Error              *dataTransferJobError   `json:"error,omitempty"`
+}
+
+// dataTransferDataSource is the source or destination of a copy job.
+// RemoteAccountName is set on the source of a cross-account copy; the job itself runs on the destination account.
+type dataTransferDataSource struct {
+	Component         string `json:"component"`
+	RemoteAccountName string `json:"remoteAccountName,omitempty"`
+	DatabaseName      string `json:"databaseName,omitempty"`
+	ContainerName     string `json:"containerName,omitempty"`
+}
+
+type dataTransferJobError struct {
+	Code    *string `json:"code,omitempty"`
+	Message *string `json:"message,omitempty"`
+}
+
+type dataTransferJobList struct {
+	Value    []*dataTransferJob `json:"value"`
+	NextLink string             `json:"nextLink,omitempty"`
+}
+
+type dataTransferJobCreateParameters struct {
+	Properties dataTransferJobProperties `json:"properties"`
+}
+
+// dataTransferJobsClient manages data transfer jobs on the configured account.
+type dataTransferJobsClient struct {
+	rest *armRestClient
+}
+
+func newDataTransferJobsClient() (*dataTransferJobsClient, error) {
+	rest, err := newARMRestClient(dataTransferAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &dataTransferJobsClient{rest: rest}, nil
+}
+
+func (c *dataTransferJobsClient) jobPath(jobName string) string {
+	return getAssignableScope(Account) + "/dataTransferJobs/" + jobName
+}
+
+// create submits a new data transfer job. Job names can't be reused on an account.
+func (c *dataTransferJobsClient) create(ctx context.Context, jobName string, params dataTransferJobCreateParameters) (dataTransferJob, error) {
+	var job dataTransferJob
+	if err := c.rest.do(ctx, http.MethodPut, c.jobPath(jobName), params, &job, http.StatusOK, http.StatusCreated, http.StatusAccepted); err != nil {
+		return dataTransferJob{}, err
+	}
+	return job, nil
+}
+
+// get reads the current state of a data transfer job.
+func (c *dataTransferJobsClient) get(ctx context.Context, jobName string) (dataTransferJob, error) {
+	var job dataTransferJob
+	if err := c.rest.do(ctx, http.MethodGet, c.jobPath(jobName), nil, &job); err != nil {
+		return dataTransferJob{}, err
+	}
+	return job, nil
+}
+
+// list returns every data transfer job on the account, including finished ones.
+func (c *dataTransferJobsClient) list(ctx context.Context) ([]*dataTransferJob, error) {
+	var jobs []*dataTransferJob
+	for path := getAssignableScope(Account) + "/dataTransferJobs"; path != ""; {
+		var page dataTransferJobList
+		if err := c.rest.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, page.Value...)
+		path = page.NextLink
+	}
+	return jobs, nil
+}
+
+// cancel stops a job that hasn't finished. Documents already copied stay in the destination container.
+func (c *dataTransferJobsClient) cancel(ctx context.Context, jobName string) (dataTransferJob, error) {
+	var job dataTransferJob
+	if err := c.rest.do(ctx, http.MethodPost, c.jobPath(jobName)+"/cancel", nil, &job); err != nil {
+		return dataTransferJob{}, err
+	}
+	return job, nil
+}
+
+// pause suspends a running job. A paused job keeps its progress and can be resumed.
+func (c *dataTransferJobsClient) pause(ctx context.Context, jobName string) (dataTransferJob, error) {
+	var job dataTransferJob
+	if err := c.rest.do(ctx, http.MethodPost, c.jobPath(jobName)+"/pause", nil, &job); err != nil {
+		return dataTransferJob{}, err
+	}
+	return job, nil
+}
+
+// resume continues a paused job from where it stopped.
+func (c *dataTransferJobsClient) resume(ctx context.Context, jobName string) (dataTransferJob, error) {
+	var job dataTransferJob
+	if err := c.rest.do(ctx, http.MethodPost, c.jobPath(jobName)+"/resume", nil, &job); err != nil {
+		return dataTransferJob{}, err
+	}
+	return job, nil
+}
+
+// runCopyContainerCommand copies one container to another container, in the same account or from another account, and
+// waits for completion.
+func runCopyContainerCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("copy-container")
+	source := fs.String("source", containerName, "Source container")
+	sourceDatabase := fs.String("source-db", databaseName, "Source database")
+	dest := fs.String("dest", "", "Destination container (must already exist) (required)")
+	destDatabase := fs.String("dest-db", databaseName, "Destination database")
+	mode := fs.String("mode", "Offline", "Copy mode: Offline or Online (Online requires the account's online container copy capability)")
+	sourceAccount := fs.String("source-account", "", "Copy from this account (in the same subscription) into the configured account")
+	sourceResourceGroup := fs.String("source-rg", resourceGroupName, "Resource group of -source-account")
+	jobName := fs.String("job", "", "Job name (default: generated from the container names)")
+	wait := fs.Bool("wait", true, "Wait for the job to finish, printing progress")
+	interval := fs.Duration("interval", 15*time.Second, "Progress polling interval")
+	_ = fs.Parse(args)
+
+	if strings.TrimSpace(*dest) == "" {
+		log.Fatalf("-dest is required")
+	}
+	if *jobName == "" {
+		*jobName = fmt.Sprintf("copy-%s-to-%s-%s", *source, *dest, time.Now().UTC().Format("20060102150405"))
+	}
+
+	client, err := newDataTransferJobsClient()
+	if err != nil {
+		log.Fatalf("failed to create data transfer jobs client: %v", err)
+	}
+
+	sourceName := *sourceDatabase + "/" + *source
+	if *sourceAccount != "" {
+		if err := prepareCrossAccountCopy(ctx, *sourceResourceGroup, *sourceAccount); err != nil {
+			log.Fatalf("failed to prepare cross-account copy from %s: %v", *sourceAccount, err)
+		}
+		sourceName = *sourceAccount + ":" + sourceName
+	}
+
+	params := dataTransferJobCreateParameters{
+		Properties: dataTransferJobProperties{
+			Source:      &dataTransferDataSource{Component: "CosmosDBSql", RemoteAccountName: *sourceAccount, DatabaseName: *sourceDatabase, ContainerName: *source},
+			Destination: &dataTransferDataSource{Component: "CosmosDBSql", DatabaseName: *destDatabase, ContainerName: *dest},
+			Mode:        mode,
+		},
+	}
+
+	job, err := client.create(ctx, *jobName, params)
+	if err != nil {
+		log.Fatalf("failed to create data transfer job: %v", err)
+	}
+	fmt.Printf("Created Data Transfer Job: %s (%s -> %s/%s)\n", *jobName, sourceName, *destDatabase, *dest)
+
+	if !*wait {
+		printDataTransferJobStatus(job)
+		return
+	}
+
+	job, err = waitForDataTransferJob(ctx, client, *jobName, *interval)
+	if err != nil {
+		log.Fatalf("failed to wait for data transfer job: %v", err)
+	}
+	printDataTransferJobStatus(job)
+	if status := dataTransferJobStatus(job); status != "Completed" {
+		log.Fatalf("data transfer job %s finished with status %s", *jobName, status)
+	}
+}
+
+// runTransferJobsCommand shows or controls data transfer jobs started on the account, such as copy-container jobs.
+func runTransferJobsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("transfer-jobs")
+	interval := fs.Duration("interval", 5*time.Second, "Polling interval while waiting for a job to pause or resume")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: transfer-jobs [list | (get | cancel | pause | resume) <job name>]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	action := fs.Arg(0)
+	if (action == "" || action == "list") != (fs.NArg() <= 1) {
+		fs.Usage()
+		os.Exit(2)
+	}
+	jobName := fs.Arg(1)
+
+	client, err := newDataTransferJobsClient()
+	if err != nil {
+		log.Fatalf("failed to create data transfer jobs client: %v", err)
+	}
+
+	switch action {
+	case "", "list":
+		listDataTransferJobs(ctx, client)
+	case "get":
+		job, err := client.get(ctx, jobName)
+		if err != nil {
+			log.Fatalf("failed to get data transfer job %s: %v", jobName, err)
+		}
+		printDataTransferJobStatus(job)
+	case "cancel":
+		cancelDataTransferJob(ctx, client, jobName)
+	case "pause":
+		pauseDataTransferJob(ctx, client, jobName, *interval)
+	case "resume":
+		resumeDataTransferJob(ctx, client, jobName, *interval)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// listDataTransferJobs prints every job on the account with its mode, status, and progress, followed by the error
+// details of jobs that failed.
+func listDataTransferJobs(ctx context.Context, client *dataTransferJobsClient) {
+	jobs, err := client.list(ctx)
+	if err != nil {
+		log.Fatalf("failed to list data transfer jobs: %v", err)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No data transfer jobs on the account.")
+		return
+	}
+
+	failed := make([]*dataTransferJob, 0)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMODE\tSTATUS\tPROCESSED\tTOTAL\tSOURCE\tDESTINATION\tLAST UPDATED")
+	for _, job := range jobs {
+		if job == nil || job.Properties == nil {
+			continue
+		}
+		p := job.Properties
+		status := dataTransferJobStatus(*job)
+		if dataTransferJobErrorMessage(*job) != "" && (status == "Failed" || status == "Faulted") {
+			failed = append(failed, job)
+		}
+		updated := "-"
+		if p.LastUpdatedUTCTime != nil {
+			updated = p.LastUpdatedUTCTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", stringValue(job.Name), orDash(stringValue(p.Mode)), status,
+			countValue(p.ProcessedCount), countValue(p.TotalCount), dataTransferEndpoint(p.Source), dataTransferEndpoint(p.Destination), updated)
+	}
+	_ = tw.Flush()
+
+	for _, job := range failed {
+		fmt.Printf("%s failed: %s\n", stringValue(job.Name), dataTransferJobErrorMessage(*job))
+	}
+}
+
+// dataTransferEndpoint formats the source or destination of a job as component:database/container.
+func dataTransferEndpoint(source *dataTransferDataSource) string {
+	if source == nil {
+		return "-"
+	}
+	return source.Component + ":" + source.DatabaseName + "/" + source.ContainerName
+}
+
+func countValue(v *int64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+// cancelDataTransferJob cancels a job unless it has already finished.
+func cancelDataTransferJob(ctx context.Context, client *dataTransferJobsClient, jobName string) {
+	job, err := client.get(ctx, jobName)
+	if err != nil {
+		log.Fatalf("failed to get data transfer job %s: %v", jobName, err)
+	}
+	if status := dataTransferJobStatus(job); isDataTransferJobFinished(status) {
+		fmt.Printf("Data transfer job %s already finished with status %s; nothing to cancel.\n", jobName, status)
+		return
+	}
+
+	job, err = client.cancel(ctx, jobName)
+	if err != nil {
+		log.Fatalf("failed to cancel data transfer job %s: %v", jobName, err)
+	}
+	fmt.Printf("Cancelled data transfer job %s.\n", jobName)
+	printDataTransferJobStatus(job)
+}
+
+// pauseDataTransferJob pauses a pending or running job, waits until the service reports it as paused, and prints how
+// many documents had been processed.
+func pauseDataTransferJob(ctx context.Context, client *dataTransferJobsClient, jobName string, interval time.Duration) {
+	job, err := client.get(ctx, jobName)
+	if err != nil {
+		log.Fatalf("failed to get data transfer job %s: %v", jobName, err)
+	}
+	switch status := dataTransferJobStatus(job); {
+	case status == "Paused":
+		fmt.Printf("Data transfer job %s is already paused (%s).\n", jobName, dataTransferJobProgress(job))
+		return
+	case isDataTransferJobFinished(status):
+		log.Fatalf("data transfer job %s finished with status %s and can't be paused", jobName, status)
+	}
+
+	if _, err := client.pause(ctx, jobName); err != nil {
+		log.Fatalf("failed to pause data transfer job %s: %v", jobName, err)
+	}
+	job, err = waitForDataTransferJobStatus(ctx, client, jobName, interval, "Paused")
+	if err != nil {
+		log.Fatalf("failed to wait for data transfer job %s to pause: %v", jobName, err)
+	}
+	if status := dataTransferJobStatus(job); status != "Paused" {
+		log.Fatalf("data transfer job %s finished with status %s before it paused", jobName, status)
+	}
+	fmt.Printf("Paused data transfer job %s at %s.\n", jobName, dataTransferJobProgress(job))
+}
+
+// resumeDataTransferJob resumes a paused job and waits until it is running again.
+func resumeDataTransferJob(ctx context.Context, client *dataTransferJobsClient, jobName string, interval time.Duration) {
+	job, err := client.get(ctx, jobName)
+	if err != nil {
+		log.Fatalf("failed to get data transfer job %s: %v", jobName, err)
+	}
+	if status := dataTransferJobStatus(job); status != "Paused" {
+		log.Fatalf("data transfer job %s is %s; only paused jobs can be resumed", jobName, status)
+	}
+
+	if _, err := client.resume(ctx, jobName); err != nil {
+		log.Fatalf("failed to resume data transfer job %s: %v", jobName, err)
+	}
+	// A resumed job can finish before the next poll, so a terminal status also ends the wait.
+	job, err = waitForDataTransferJobStatus(ctx, client, jobName, interval, "Running")
+	if err != nil {
+		log.Fatalf("failed to wait for data transfer job %s to resume: %v", jobName, err)
+	}
+	fmt.Printf("Resumed data transfer job %s.\n", jobName)
+	printDataTransferJobStatus(job)
+}
+
+// waitForDataTransferJobStatus polls a job until it reaches want or a terminal state, bounded by OperationTimeout.
+func waitForDataTransferJobStatus(ctx context.Context, client *dataTransferJobsClient, jobName string, interval time.Duration, want string) (dataTransferJob, error) {
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+	status := ""
+	for {
+		job, err := client.get(ctx, jobName)
+		if err != nil {
+			return dataTransferJob{}, dataTransferWaitError(ctx, err, status)
+		}
+		if status = dataTransferJobStatus(job); status == want || isDataTransferJobFinished(status) {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return dataTransferJob{}, dataTransferWaitError(ctx, ctx.Err(), status)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// prepareCrossAccountCopy sets up what a copy from another account needs. The job runs on the destination (configured)
+// account and reads the source with the destination's system-assigned managed identity, so that identity must be enabled,
+// be the account's default identity, and hold the built-in Data Reader role on the source account.
+func prepareCrossAccountCopy(ctx context.Context, sourceResourceGroup string, sourceAccount string) error {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create cosmos db account client: %w", err)
+	}
+	if _, err := accountClient.Get(ctx, sourceResourceGroup, sourceAccount, nil); err != nil {
+		return fmt.Errorf("failed to get source account %s/%s: %w", sourceResourceGroup, sourceAccount, err)
+	}
+
+	principalID, err := ensureCopyJobIdentity(ctx, accountClient)
+	if err != nil {
+		return err
+	}
+	return grantSourceAccountReader(ctx, sourceResourceGroup, sourceAccount, principalID)
+}
+
+// ensureCopyJobIdentity enables the configured account's system-assigned identity (keeping any user-assigned ones),
+// makes it the default identity, and returns its principal ID.
+func ensureCopyJobIdentity(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient) (string, error) {
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cosmos db account: %w", err)
+	}
+	identity := account.Identity
+	hasSystemIdentity := identity != nil && identity.PrincipalID != nil &&
+		(enumValue(identity.Type) == string(armcosmos.ResourceIdentityTypeSystemAssigned) || enumValue(identity.Type) == string(armcosmos.ResourceIdentityTypeSystemAssignedUserAssigned))
+	isDefault := account.Properties != nil && stringValue(account.Properties.DefaultIdentity) == "SystemAssignedIdentity"
+	if hasSystemIdentity && isDefault {
+		return *identity.PrincipalID, nil
+	}
+
+	// The default identity also unwraps the customer-managed key; switching it could lock the account out of its data.
+	if account.Properties != nil && account.Properties.KeyVaultKeyURI != nil {
+		return "", fmt.Errorf("account %s uses a customer-managed key with default identity %s; set it to SystemAssignedIdentity yourself after granting that identity access to the key", accountName, stringValue(account.Properties.DefaultIdentity))
+	}
+	if *createOnly {
+		return "", fmt.Errorf("account %s needs a system-assigned identity as its default identity; not updating it (-create-only)", accountName)
+	}
+
+	update := armcosmos.DatabaseAccountUpdateParameters{
+		Identity:   &armcosmos.ManagedServiceIdentity{Type: to.Ptr(armcosmos.ResourceIdentityTypeSystemAssigned)},
+		Properties: &armcosmos.DatabaseAccountUpdateProperties{DefaultIdentity: to.Ptr("SystemAssignedIdentity")},
+	}
+	if identity != nil && len(identity.UserAssignedIdentities) > 0 {
+		update.Identity.Type = to.Ptr(armcosmos.ResourceIdentityTypeSystemAssignedUserAssigned)
+		update.Identity.UserAssignedIdentities = make(map[string]*armcosmos.Components1Jq1T4ISchemasManagedserviceidentityPropertiesUserassignedidentitiesAdditionalproperties, len(identity.UserAssignedIdentities))
+		for id := range identity.UserAssignedIdentities {
+			update.Identity.UserAssignedIdentities[id] = &armcosmos.Components1Jq1T4ISchemasManagedserviceidentityPropertiesUserassignedidentitiesAdditionalproperties{}
+		}
+	}
+
+	fmt.Printf("Enabling the system-assigned identity of %s as its default identity...\n", accountName)
+	poller, err := accountClient.BeginUpdate(ctx, resourceGroupName, accountName, update, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to update account identity: %w", err)
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return "", fmt.Errorf("failed to update account identity: %w", err)
+	}
+	if resp.Identity == nil || resp.Identity.PrincipalID == nil {
+		return "", fmt.Errorf("account %s reported no system-assigned principal ID", accountName)
+	}
+	return *resp.Identity.PrincipalID, nil
+}
+
+// grantSourceAccountReader assigns the built-in Cosmos DB Data Reader role on the source account to principalID.
+// The assignment name is derived from its scope, role, and principal, so re-running the copy reuses it.
+func grantSourceAccountReader(ctx context.Context, sourceResourceGroup string, sourceAccount string, principalID string) error {
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create role assignment client: %w", err)
+	}
+
+	scope := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s", subscriptionID, sourceResourceGroup, sourceAccount)
+	roleDefinitionID := scope + "/sqlRoleDefinitions/" + cosmosDataReaderRoleDefinitionID
+	params := armcosmos.SQLRoleAssignmentCreateUpdateParameters{Properties: &armcosmos.SQLRoleAssignmentResource{RoleDefinitionID: &roleDefinitionID, Scope: &scope, PrincipalID: to.Ptr(principalID)}}
+	poller, err := sqlClient.BeginCreateUpdateSQLRoleAssignment(ctx, uuid5Name(fmt.Sprintf("%s|%s|%s", scope, roleDefinitionID, principalID)), sourceResourceGroup, sourceAccount, params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to assign Data Reader on %s: %w", sourceAccount, err)
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return fmt.Errorf("failed to assign Data Reader on %s: %w", sourceAccount, err)
+	}
+	recordRoleAssignment("CosmosDBSqlRBAC", stringValue(resp.ID), roleDefinitionID, principalID, scope)
+	fmt.Printf("Granted %s's identity the Data Reader role on %s.\n", accountName, sourceAccount)
+	return nil
+}
+
+// waitForDataTransferJob polls a job until it reaches a terminal state, printing processed/total counts. The wait is
+// bounded by OperationTimeout; the job keeps running when the wait gives up.
+func waitForDataTransferJob(ctx context.Context, client *dataTransferJobsClient, jobName string, interval time.Duration) (dataTransferJob, error) {
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+	status := ""
+	for {
+		job, err := client.get(ctx, jobName)
+		if err != nil {
+			return dataTransferJob{}, dataTransferWaitError(ctx, err, status)
+		}
+
+		status = dataTransferJobStatus(job)
+		if isDataTransferJobFinished(status) {
+			return job, nil
+		}
+		log.Printf("Data transfer job %s: %s, %s", jobName, status, dataTransferJobProgress(job))
+
+		select {
+		case <-ctx.Done():
+			return dataTransferJob{}, dataTransferWaitError(ctx, ctx.Err(), status)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// dataTransferWaitError reports a wait that ran past OperationTimeout with the last job status seen.
+func dataTransferWaitError(ctx context.Context, err error, status string) error {
+	if ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return fmt.Errorf("stopped waiting after OperationTimeout (%s); last status: %s: %w", operationTimeout, orDash(status), err)
+}
+
+// printDataTransferJobStatus prints the final (or current) state of a job.
+func printDataTransferJobStatus(job dataTransferJob) {
+	name := ""
+	if job.Name != nil {
+		name = *job.Name
+	}
+	fmt.Printf("Data transfer job %s: %s, %s\n", name, dataTransferJobStatus(job), dataTransferJobProgress(job))
+	if job.Properties != nil && job.Properties.Duration != nil {
+		fmt.Printf("  Duration: %s\n", *job.Properties.Duration)
+	}
+	if msg := dataTransferJobErrorMessage(job); msg != "" {
+		fmt.Printf("  Error: %s\n", msg)
+	}
+}
+
+// isDataTransferJobFinished reports whether a job status is terminal; such jobs can't be cancelled, paused, or resumed.
+func isDataTransferJobFinished(status string) bool {
+	switch status {
+	case "Completed", "Failed", "Cancelled", "Faulted":
+		return true
+	}
+	return false
+}
+
+func dataTransferJobStatus(job dataTransferJob) string {
+	if job.Properties == nil || job.Properties.Status == nil {
+		return "Unknown"
+	}
+	return *job.Properties.Status
+}
+
+// dataTransferJobProgress formats the processed/total document counts of a job.
+func dataTransferJobProgress(job dataTransferJob) string {
+	if job.Properties == nil || job.Properties.ProcessedCount == nil {
+		return "no progress reported yet"
+	}
+	if job.Properties.TotalCount == nil || *job.Properties.TotalCount == 0 {
+		return fmt.Sprintf("%d documents processed", *job.Properties.ProcessedCount)
+	}
+	processed, total := *job.Properties.ProcessedCount, *job.Properties.TotalCount
+	return fmt.Sprintf("%d of %d documents processed (%.1f%%)", processed, total, float64(processed)/float64(total)*100)
+}
+
+func dataTransferJobErrorMessage(job dataTransferJob) string {
+	if job.Properties == nil || job.Properties.Error == nil {
+		return ""
+	}
+	e := job.Properties.Error
+	switch {
+	case e.Code != nil && e.Message != nil:
+		return *e.Code + ": " + *e.Message
+	case e.Message != nil:
+		return *e.Message
+	case e.Code != nil:
+		return *e.Code
+	}
+	return ""
+}
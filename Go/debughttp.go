@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+	"regexp"
+
+	azlog "github.com/Azure/azure-sdk-for-go/sdk/azcore/log"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// debugLogHeaders are response headers worth seeing in -debug-http output (and quoting in support tickets) that the
+// SDK would otherwise print as REDACTED.
+var debugLogHeaders = []string{
+	"x-ms-correlation-request-id",
+	"x-ms-routing-request-id",
+	"x-ms-activity-id",
+	"x-ms-ratelimit-remaining-subscription-reads",
+	"x-ms-ratelimit-remaining-subscription-writes",
+	"x-ms-failure-cause",
+	"Azure-AsyncOperation",
+	"Location",
+	"Retry-After",
+}
+
+// The SDK already redacts Authorization and unknown headers and query parameters; these patterns catch secrets that
+// can still appear in message text, such as bearer tokens, SAS signatures, and account keys.
+var debugLogSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_.~+/]+=*`),
+	regexp.MustCompile(`(?i)("?(?:access_token|refresh_token|id_token|client_secret|primaryMasterKey|secondaryMasterKey|primaryReadonlyMasterKey|secondaryReadonlyMasterKey)"?\s*[:=]\s*"?)[^"&\s,}]+`),
+	regexp.MustCompile(`(?i)([?&]sig=)[^&\s]+`),
+	regexp.MustCompile(`(?i)(AccountKey=)[^;\s]+`),
+}
+
+// configureHTTPLogging routes the SDK's request, response, retry, long-running operation, and authentication log
+// events through the standard logger when -debug-http is set.
+func configureHTTPLogging() {
+	if !*debugHTTP {
+		return
+	}
+
+	azlog.SetEvents(
+		azlog.EventRequest,
+		azlog.EventResponse,
+		azlog.EventResponseError,
+		azlog.EventRetryPolicy,
+		azlog.EventLRO,
+		azidentity.EventAuthentication,
+	)
+	azlog.SetListener(func(event azlog.Event, msg string) {
+		log.Printf("[%s] %s", event, redactSecrets(msg))
+	})
+}
+
+// redactSecrets masks anything in msg that looks like a credential.
+func redactSecrets(msg string) string {
+	for _, re := range debugLogSecretPatterns {
+		msg = re.ReplaceAllString(msg, "${1}REDACTED")
+	}
+	return msg
+}
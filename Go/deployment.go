@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// runTemplateCommand exports the account as an ARM template, or previews or deploys an ARM template to the resource
+// group, so the imperative SDK calls of the full run can be compared with a template deployment of the same resources.
+func runTemplateCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("template")
+	templatePath := fs.String("template", "", "what-if, deploy: ARM template JSON file (default: export the account's current template)")
+	parametersPath := fs.String("parameters", "", "what-if, deploy: ARM parameters JSON file")
+	showUnchanged := fs.Bool("show-unchanged", false, "what-if: also list resources the deployment wouldn't change")
+	out := fs.String("out", "", "export: write the template to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: template [-out <file>] export | template [-template <file>] [-parameters <file>] [-show-unchanged] (what-if | deploy)")
+		fmt.Fprintln(fs.Output(), "Bicep files must be compiled to ARM JSON first (az bicep build).")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch fs.Arg(0) {
+	case "export":
+		template, err := exportAccountTemplate(ctx)
+		if err != nil {
+			log.Fatalf("failed to export template: %v", err)
+		}
+		data, err := json.MarshalIndent(template, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to encode template: %v", err)
+		}
+		if *out == "" {
+			fmt.Println(string(data))
+			return
+		}
+		if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", *out, err)
+		}
+		fmt.Printf("Wrote the template of %s to %s\n", accountName, *out)
+	case "what-if":
+		template, parameters, err := loadDeploymentTemplate(ctx, *templatePath, *parametersPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		printTemplateWhatIf(ctx, template, parameters, *showUnchanged)
+	case "deploy":
+		template, parameters, err := loadDeploymentTemplate(ctx, *templatePath, *parametersPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		deployTemplate(ctx, template, parameters)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// loadDeploymentTemplate reads the template and parameters files. Without a template file it exports the account's
+// current template, which takes no parameters.
+func loadDeploymentTemplate(ctx context.Context, templatePath string, parametersPath string) (any, any, error) {
+	if templatePath == "" {
+		if parametersPath != "" {
+			return nil, nil, fmt.Errorf("-parameters needs -template; the exported template takes no parameters")
+		}
+		template, err := exportAccountTemplate(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to export template: %w", err)
+		}
+		return template, nil, nil
+	}
+
+	var template map[string]any
+	if err := readJSONFile(templatePath, &template); err != nil {
+		return nil, nil, err
+	}
+	if _, ok := template["resources"]; !ok {
+		return nil, nil, fmt.Errorf("%s is not an ARM template (no resources); compile Bicep files with `az bicep build` first", templatePath)
+	}
+	if parametersPath == "" {
+		return template, nil, nil
+	}
+
+	// Accept both a deployment parameters file ({"$schema": ..., "parameters": {...}}) and a bare parameters object.
+	var parameters map[string]any
+	if err := readJSONFile(parametersPath, &parameters); err != nil {
+		return nil, nil, err
+	}
+	if inner, ok := parameters["parameters"].(map[string]any); ok {
+		return template, inner, nil
+	}
+	return template, parameters, nil
+}
+
+func readJSONFile(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// exportAccountTemplate exports the account and its NoSQL databases and containers from the resource group as an ARM
+// template with the current names and settings written in, rather than turned into parameters.
+func exportAccountTemplate(ctx context.Context) (any, error) {
+	resourceIDs, err := getAccountTemplateResourceIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource group client: %w", err)
+	}
+	log.Printf("Exporting the template of %d resource(s)...", len(resourceIDs))
+	poller, err := resourceGroupClient.BeginExportTemplate(ctx, resourceGroupName, armresources.ExportTemplateRequest{
+		Options:   to.Ptr("SkipAllParameterization"),
+		Resources: to.SliceOfPtrs(resourceIDs...),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return nil, err
+	}
+	// Resources that can't be exported are reported alongside a partial template.
+	if resp.Error != nil && resp.Error.Message != nil {
+		log.Printf("The export is incomplete: %s", *resp.Error.Message)
+	}
+	if resp.Template == nil {
+		return nil, fmt.Errorf("no template returned")
+	}
+	return resp.Template, nil
+}
+
+// getAccountTemplateResourceIDs returns the IDs of the account and, for NoSQL accounts, its databases and containers.
+// Exporting only the account would leave them out, because child resources are exported separately.
+func getAccountTemplateResourceIDs(ctx context.Context) ([]string, error) {
+	accountID := getAssignableScope(Account)
+	ids := []string{accountID}
+	if isMongoAccount() {
+		return ids, nil
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cosmos db sql client: %w", err)
+	}
+	databases := sqlClient.NewListSQLDatabasesPager(resourceGroupName, accountName, nil)
+	for databases.More() {
+		page, err := databases.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			ids = append(ids, accountID+"/sqlDatabases/"+*db.Name)
+			containers := sqlClient.NewListSQLContainersPager(resourceGroupName, accountName, *db.Name, nil)
+			for containers.More() {
+				page, err := containers.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list containers of %s: %w", *db.Name, err)
+				}
+				for _, c := range page.Value {
+					if c != nil && c.Name != nil {
+						ids = append(ids, accountID+"/sqlDatabases/"+*db.Name+"/containers/"+*c.Name)
+					}
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// templateDeploymentName names the sample's deployments after the run ID, so each run shows up separately in the
+// resource group's deployment history.
+func templateDeploymentName() string {
+	name := "cosmos-sample-" + runID
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// deployTemplate deploys the template to the resource group in Incremental mode, which leaves resources that aren't in
+// the template alone, and prints the resources it touched.
+func deployTemplate(ctx context.Context, template any, parameters any) {
+	deploymentsClient, err := armresources.NewDeploymentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create deployments client: %v", err)
+	}
+
+	name := templateDeploymentName()
+	fmt.Printf("Starting template deployment %s to %s...\n", name, resourceGroupName)
+	poller, err := deploymentsClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, armresources.Deployment{
+		Properties: &armresources.DeploymentProperties{
+			Mode:       to.Ptr(armresources.DeploymentModeIncremental),
+			Template:   template,
+			Parameters: parameters,
+		},
+		Tags: sampleTags(ctx),
+	}, nil)
+	if err != nil {
+		log.Fatalf("failed to start template deployment: %v", err)
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		log.Fatalf("failed to deploy template: %v", err)
+	}
+
+	recordResource("Microsoft.Resources/deployments", resp.ID)
+	fmt.Printf("Created/updated Deployment: %s\n", stringValue(resp.ID))
+	if p := resp.Properties; p != nil {
+		fmt.Printf("State: %s, duration %s\n", orDash(enumValue(p.ProvisioningState)), orDash(stringValue(p.Duration)))
+		for _, r := range p.OutputResources {
+			if r != nil && r.ID != nil {
+				fmt.Printf("  %s\n", strings.TrimPrefix(*r.ID, getAssignableScope(ResourceGroup)+"/providers/"))
+			}
+		}
+	}
+}
+
+// whatIfSymbols mark each predicted resource change, like the Azure CLI's what-if output.
+var whatIfSymbols = map[armresources.ChangeType]string{
+	armresources.ChangeTypeCreate:      "+",
+	armresources.ChangeTypeDelete:      "-",
+	armresources.ChangeTypeModify:      "~",
+	armresources.ChangeTypeDeploy:      "!",
+	armresources.ChangeTypeNoChange:    "=",
+	armresources.ChangeTypeIgnore:      "*",
+	armresources.ChangeTypeUnsupported: "x",
+}
+
+// printTemplateWhatIf asks ARM to predict what deploying the template would change, without changing anything, and
+// prints each resource's change and, for modified resources, the properties that would change. ARM evaluates the
+// template against the resources as they exist now.
+func printTemplateWhatIf(ctx context.Context, template any, parameters any, showUnchanged bool) {
+	deploymentsClient, err := armresources.NewDeploymentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create deployments client: %v", err)
+	}
+
+	fmt.Printf("Predicting the changes of deploying the template to %s...\n", resourceGroupName)
+	poller, err := deploymentsClient.BeginWhatIf(ctx, resourceGroupName, templateDeploymentName(), armresources.DeploymentWhatIf{
+		Properties: &armresources.DeploymentWhatIfProperties{
+			Mode:       to.Ptr(armresources.DeploymentModeIncremental),
+			Template:   template,
+			Parameters: parameters,
+		},
+	}, nil)
+	if err != nil {
+		log.Fatalf("failed to start what-if: %v", err)
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		log.Fatalf("failed to run what-if: %v", err)
+	}
+	if resp.Error != nil && resp.Error.Message != nil {
+		log.Fatalf("what-if failed: %s", *resp.Error.Message)
+	}
+	if resp.Properties == nil {
+		fmt.Println("No changes predicted.")
+		return
+	}
+
+	counts := map[armresources.ChangeType]int{}
+	for _, c := range resp.Properties.Changes {
+		if c == nil || c.ChangeType == nil {
+			continue
+		}
+		counts[*c.ChangeType]++
+		if *c.ChangeType == armresources.ChangeTypeNoChange && !showUnchanged {
+			continue
+		}
+		fmt.Printf("%s %s: %s\n", whatIfSymbols[*c.ChangeType], *c.ChangeType, strings.TrimPrefix(stringValue(c.ResourceID), getAssignableScope(ResourceGroup)+"/providers/"))
+		if c.UnsupportedReason != nil {
+			fmt.Printf("    %s\n", *c.UnsupportedReason)
+		}
+		printWhatIfPropertyChanges(c.Delta, "    ")
+	}
+	fmt.Printf("Predicted changes: %d to create, %d to modify, %d to delete, %d unchanged, %d ignored.\n",
+		counts[armresources.ChangeTypeCreate], counts[armresources.ChangeTypeModify]+counts[armresources.ChangeTypeDeploy],
+		counts[armresources.ChangeTypeDelete], counts[armresources.ChangeTypeNoChange], counts[armresources.ChangeTypeIgnore])
+}
+
+// printWhatIfPropertyChanges prints property changes and, for arrays and objects, their nested changes.
+func printWhatIfPropertyChanges(changes []*armresources.WhatIfPropertyChange, indent string) {
+	for _, p := range changes {
+		if p == nil || p.PropertyChangeType == nil {
+			continue
+		}
+		path := stringValue(p.Path)
+		switch *p.PropertyChangeType {
+		case armresources.PropertyChangeTypeCreate:
+			fmt.Printf("%s+ %s: %s\n", indent, path, whatIfValue(p.After))
+		case armresources.PropertyChangeTypeDelete:
+			fmt.Printf("%s- %s: %s\n", indent, path, whatIfValue(p.Before))
+		case armresources.PropertyChangeTypeModify:
+			if len(p.Children) > 0 {
+				fmt.Printf("%s~ %s:\n", indent, path)
+				printWhatIfPropertyChanges(p.Children, indent+"  ")
+			} else {
+				fmt.Printf("%s~ %s: %s => %s\n", indent, path, whatIfValue(p.Before), whatIfValue(p.After))
+			}
+		case armresources.PropertyChangeTypeArray:
+			fmt.Printf("%s~ %s: [\n", indent, path)
+			printWhatIfPropertyChanges(p.Children, indent+"  ")
+			fmt.Printf("%s]\n", indent)
+		case armresources.PropertyChangeTypeNoEffect:
+			fmt.Printf("%sx %s: %s (no effect)\n", indent, path, whatIfValue(p.After))
+		}
+	}
+}
+
+func whatIfValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
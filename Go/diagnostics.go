@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights/v2"
+	"github.com/spf13/viper"
+)
+
+const (
+	diagnosticSettingName = "cosmos-sample-diagnostics"
+
+	defaultDiagnosticRetentionInDays = 30
+	minDiagnosticRetentionInDays     = 30
+	maxDiagnosticRetentionInDays     = 730
+)
+
+// diagnosticLogCategories are the resource log categories a Cosmos DB account can send to a diagnostic setting.
+var diagnosticLogCategories = []string{
+	"DataPlaneRequests",
+	"QueryRuntimeStatistics",
+	"PartitionKeyStatistics",
+	"PartitionKeyRUConsumption",
+	"ControlPlaneRequests",
+	"MongoRequests",
+	"CassandraRequests",
+	"GremlinRequests",
+	"TableApiRequests",
+}
+
+// defaultDiagnosticLogCategories are streamed when DiagnosticLogCategories isn't set.
+var defaultDiagnosticLogCategories = []string{"DataPlaneRequests", "QueryRuntimeStatistics", "PartitionKeyRUConsumption"}
+
+// loadDiagnosticSettings reads DiagnosticLogCategories and DiagnosticRetentionInDays.
+func loadDiagnosticSettings() error {
+	diagnosticCategories = defaultDiagnosticLogCategories
+	if viper.IsSet("DiagnosticLogCategories") {
+		configured := viper.GetStringSlice("DiagnosticLogCategories")
+		diagnosticCategories = make([]string, 0, len(configured))
+		seen := map[string]bool{}
+		for _, raw := range configured {
+			category := ""
+			for _, known := range diagnosticLogCategories {
+				if strings.EqualFold(strings.TrimSpace(raw), known) {
+					category = known
+				}
+			}
+			if category == "" {
+				return fmt.Errorf("unknown DiagnosticLogCategories entry %q (expected one of %s)", raw, strings.Join(diagnosticLogCategories, ", "))
+			}
+			if !seen[category] {
+				seen[category] = true
+				diagnosticCategories = append(diagnosticCategories, category)
+			}
+		}
+	}
+
+	viper.SetDefault("DiagnosticRetentionInDays", defaultDiagnosticRetentionInDays)
+	diagnosticRetentionInDays = viper.GetInt("DiagnosticRetentionInDays")
+	if diagnosticRetentionInDays < minDiagnosticRetentionInDays || diagnosticRetentionInDays > maxDiagnosticRetentionInDays {
+		return fmt.Errorf("DiagnosticRetentionInDays must be between %d and %d (got %d)", minDiagnosticRetentionInDays, maxDiagnosticRetentionInDays, diagnosticRetentionInDays)
+	}
+	return nil
+}
+
+// createOrUpdateDiagnosticSettings streams the account's DiagnosticLogCategories logs and Requests metrics to a Log
+// Analytics workspace. An empty category list sends metrics only.
+func createOrUpdateDiagnosticSettings(ctx context.Context) {
+	workspaceID := createOrUpdateLogAnalyticsWorkspace(ctx)
+
+	diagnosticSettingsClient, err := armmonitor.NewDiagnosticSettingsClient(credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create diagnostic settings client: %v", err)
+	}
+
+	logs := make([]*armmonitor.LogSettings, 0, len(diagnosticCategories))
+	for _, category := range diagnosticCategories {
+		logs = append(logs, &armmonitor.LogSettings{Category: to.Ptr(category), Enabled: to.Ptr(true)})
+	}
+
+	properties := armmonitor.DiagnosticSettingsResource{
+		Properties: &armmonitor.DiagnosticSettings{
+			WorkspaceID: to.Ptr(workspaceID),
+			// "Dedicated" writes to resource-specific tables (CDBDataPlaneRequests, CDBQueryRuntimeStatistics, ...)
+			// instead of the legacy AzureDiagnostics table.
+			LogAnalyticsDestinationType: to.Ptr("Dedicated"),
+			Logs:                        logs,
+			Metrics: []*armmonitor.MetricSettings{
+				{Category: to.Ptr("Requests"), Enabled: to.Ptr(true)},
+			},
+		},
+	}
+
+	resp, err := diagnosticSettingsClient.CreateOrUpdate(ctx, getAssignableScope(Account), diagnosticSettingName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update diagnostic setting: %v", err)
+	}
+
+	recordResource("Microsoft.Insights/diagnosticSettings", resp.ID)
+	fmt.Printf("Created/updated Diagnostic Setting: %s\n", *resp.ID)
+}
+
+// createOrUpdateLogAnalyticsWorkspace creates or updates the Log Analytics workspace that receives diagnostics and returns its resource ID.
+func createOrUpdateLogAnalyticsWorkspace(ctx context.Context) string {
+	log.Printf("Starting Log Analytics workspace create/update: workspace=%s", logAnalyticsWorkspaceName)
+
+	workspacesClient, err := armoperationalinsights.NewWorkspacesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create Log Analytics workspaces client: %v", err)
+	}
+
+	properties := armoperationalinsights.Workspace{
+		Location: &location,
+		Tags:     sampleTags(ctx),
+		Properties: &armoperationalinsights.WorkspaceProperties{
+			SKU: &armoperationalinsights.WorkspaceSKU{Name: to.Ptr(armoperationalinsights.WorkspaceSKUNameEnumPerGB2018)},
+			// Log Analytics applies retention per workspace; diagnostic setting retention policies are retired.
+			RetentionInDays: to.Ptr(int32(diagnosticRetentionInDays)),
+		},
+	}
+
+	pollerResp, err := workspacesClient.BeginCreateOrUpdate(ctx, resourceGroupName, logAnalyticsWorkspaceName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to begin create or update Log Analytics workspace: %v", err)
+	}
+
+	resp, err := pollUntilDone(ctx, pollerResp)
+	if err != nil {
+		log.Fatalf("failed to poll the result: %v", err)
+	}
+
+	recordResource("Microsoft.OperationalInsights/workspaces", resp.ID)
+	fmt.Printf("Created/updated Log Analytics workspace: %s\n", *resp.ID)
+	return *resp.ID
+}
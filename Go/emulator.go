@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+)
+
+const (
+	defaultEmulatorEndpoint = "https://localhost:8081/"
+
+	// emulatorKey is the well-known, publicly documented key that every Cosmos DB emulator accepts.
+	emulatorKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
+)
+
+// runEmulatorSample runs the data-plane part of the sample against the local emulator. Management-plane steps
+// (account, RBAC, diagnostics, alerts, locks) have no emulator equivalent, so the database and container are created
+// through the data plane instead.
+func runEmulatorSample(ctx context.Context) {
+	log.Printf("Emulator mode: skipping management-plane steps and targeting %s", emulatorEndpoint)
+
+	client, err := newEmulatorClient()
+	if err != nil {
+		log.Fatalf("failed to create emulator client: %v", err)
+	}
+
+	createEmulatorDatabaseAndContainer(ctx, client)
+	verifyDataPlaneAccess(ctx)
+	if *seedCount > 0 {
+		seedSampleDocuments(ctx, *seedCount)
+	}
+}
+
+// createEmulatorDatabaseAndContainer creates the primary database and each of its configured containers (with the
+// same partition key, TTL, indexing policy, unique keys, and throughput as the ARM-created ones) if they don't exist
+// yet.
+func createEmulatorDatabaseAndContainer(ctx context.Context, client *azcosmos.Client) {
+	primary := databaseConfig{Name: databaseName, Containers: []containerConfig{defaultContainerConfig()}}
+	for _, db := range databases {
+		if db.Name == databaseName {
+			primary = db
+		}
+	}
+
+	var databaseOptions *azcosmos.CreateDatabaseOptions
+	if throughput := emulatorThroughput(primary.MaxAutoScaleThroughput, primary.Throughput); throughput != nil {
+		databaseOptions = &azcosmos.CreateDatabaseOptions{ThroughputProperties: throughput}
+	}
+	if _, err := client.CreateDatabase(ctx, azcosmos.DatabaseProperties{ID: databaseName}, databaseOptions); err != nil && !isConflict(err) {
+		log.Fatalf("failed to create emulator database: %v", err)
+	}
+	fmt.Printf("Created/verified emulator database: %s\n", databaseName)
+
+	database, err := client.NewDatabase(databaseName)
+	if err != nil {
+		log.Fatalf("failed to create emulator database client: %v", err)
+	}
+
+	for _, c := range primary.Containers {
+		properties, err := emulatorContainerProperties(c)
+		if err != nil {
+			log.Fatalf("failed to build emulator container %s: %v", c.Name, err)
+		}
+		var options *azcosmos.CreateContainerOptions
+		if throughput := emulatorThroughput(c.MaxAutoScaleThroughput, c.Throughput); throughput != nil {
+			options = &azcosmos.CreateContainerOptions{ThroughputProperties: throughput}
+		}
+		if _, err := database.CreateContainer(ctx, properties, options); err != nil && !isConflict(err) {
+			log.Fatalf("failed to create emulator container %s: %v", c.Name, err)
+		}
+		fmt.Printf("Created/verified emulator container: %s/%s\n", databaseName, c.Name)
+	}
+}
+
+// emulatorContainerProperties converts a configured container to its data-plane definition.
+func emulatorContainerProperties(c containerConfig) (azcosmos.ContainerProperties, error) {
+	kind := azcosmos.PartitionKeyKindHash
+	if len(c.PartitionKeyPaths) > 1 {
+		kind = azcosmos.PartitionKeyKindMultiHash
+	}
+	properties := azcosmos.ContainerProperties{
+		ID:                     c.Name,
+		PartitionKeyDefinition: azcosmos.PartitionKeyDefinition{Kind: kind, Paths: c.PartitionKeyPaths, Version: 2},
+		DefaultTimeToLive:      c.DefaultTTL,
+	}
+
+	// The management and data-plane SDKs serialize indexing policies in the same REST shape.
+	raw, err := json.Marshal(containerIndexingPolicy(c))
+	if err != nil {
+		return properties, fmt.Errorf("failed to encode indexing policy: %w", err)
+	}
+	properties.IndexingPolicy = &azcosmos.IndexingPolicy{}
+	if err := json.Unmarshal(raw, properties.IndexingPolicy); err != nil {
+		return properties, fmt.Errorf("failed to convert indexing policy: %w", err)
+	}
+
+	if keys := c.uniqueKeys(); len(keys) > 0 {
+		properties.UniqueKeyPolicy = &azcosmos.UniqueKeyPolicy{}
+		for _, key := range keys {
+			properties.UniqueKeyPolicy.UniqueKeys = append(properties.UniqueKeyPolicy.UniqueKeys, azcosmos.UniqueKey{Paths: key})
+		}
+	}
+	return properties, nil
+}
+
+// emulatorThroughput returns the data-plane throughput for an autoscale max or manual RU/s, or nil when neither is set.
+func emulatorThroughput(autoscaleMax int, manual int) *azcosmos.ThroughputProperties {
+	switch {
+	case autoscaleMax > 0:
+		throughput := azcosmos.NewAutoscaleThroughputProperties(int32(autoscaleMax))
+		return &throughput
+	case manual > 0:
+		throughput := azcosmos.NewManualThroughputProperties(int32(manual))
+		return &throughput
+	}
+	return nil
+}
+
+// newEmulatorClient creates an azcosmos client that authenticates to the emulator with its well-known key.
+func newEmulatorClient() (*azcosmos.Client, error) {
+	keyCredential, err := azcosmos.NewKeyCredential(emulatorKey)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &azcosmos.ClientOptions{}
+	endpoint, err := url.Parse(emulatorEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EmulatorEndpoint %q: %w", emulatorEndpoint, err)
+	}
+	// The emulator serves a self-signed certificate; only trust it for a local endpoint.
+	if endpoint.Scheme == "https" && isLocalHost(endpoint.Hostname()) {
+		options.Transport = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		}
+	}
+
+	return azcosmos.NewClientWithKey(emulatorEndpoint, keyCredential, options)
+}
+
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func isConflict(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusConflict
+}
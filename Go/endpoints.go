@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runEndpointsCommand prints the account's document endpoint, regional endpoints, and dedicated gateway endpoint.
+func runEndpointsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("endpoints")
+	_ = fs.Parse(args)
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+
+	printAccountEndpoints(ctx, account.DatabaseAccountGetResults)
+}
+
+// printAccountEndpoints prints the endpoints clients connect to, and records them in the run summary.
+func printAccountEndpoints(ctx context.Context, account armcosmos.DatabaseAccountGetResults) {
+	if account.Properties == nil {
+		return
+	}
+
+	endpoints := &summaryEndpoints{}
+	if account.Properties.DocumentEndpoint != nil {
+		endpoints.Document = *account.Properties.DocumentEndpoint
+		fmt.Printf("Document endpoint: %s\n", endpoints.Document)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REGION\tROLE\tENDPOINT")
+	for _, l := range account.Properties.WriteLocations {
+		if l != nil {
+			fmt.Fprintf(tw, "%s\twrite\t%s\n", stringValue(l.LocationName), stringValue(l.DocumentEndpoint))
+			endpoints.Write = append(endpoints.Write, summaryRegionalEndpoint{Region: stringValue(l.LocationName), Endpoint: stringValue(l.DocumentEndpoint)})
+		}
+	}
+	for _, l := range account.Properties.ReadLocations {
+		if l != nil {
+			fmt.Fprintf(tw, "%s\tread\t%s\n", stringValue(l.LocationName), stringValue(l.DocumentEndpoint))
+			endpoints.Read = append(endpoints.Read, summaryRegionalEndpoint{Region: stringValue(l.LocationName), Endpoint: stringValue(l.DocumentEndpoint)})
+		}
+	}
+	_ = tw.Flush()
+
+	gateway, err := getDedicatedGatewayEndpoint(ctx)
+	switch {
+	case err != nil:
+		log.Printf("Could not read the dedicated gateway: %v", err)
+	case gateway != "":
+		endpoints.DedicatedGateway = gateway
+		fmt.Printf("Dedicated gateway endpoint: %s\n", gateway)
+	}
+
+	summary.Endpoints = endpoints
+}
+
+// getDedicatedGatewayEndpoint returns the SqlDedicatedGateway service endpoint, or "" when the account has none.
+func getDedicatedGatewayEndpoint(ctx context.Context) (string, error) {
+	serviceClient, err := armcosmos.NewServiceClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cosmos db service client: %w", err)
+	}
+
+	resp, err := serviceClient.Get(ctx, resourceGroupName, accountName, string(armcosmos.ServiceTypeSQLDedicatedGateway), nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if p, ok := resp.Properties.(*armcosmos.SQLDedicatedGatewayServiceResourceProperties); ok && p.SQLDedicatedGatewayEndpoint != nil {
+		return *p.SQLDedicatedGatewayEndpoint, nil
+	}
+	return "", nil
+}
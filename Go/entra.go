@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// getByIds accepts at most 1000 ids per request.
+const graphGetByIDsBatchSize = 1000
+
+// graphClient is a minimal Microsoft Graph client for looking up directory objects. It uses the azcore pipeline with
+// the same credential as the ARM clients, so no extra SDK is needed.
+type graphClient struct {
+	internal *azcore.Client
+	endpoint string
+}
+
+type graphDirectoryObject struct {
+	ID        string `json:"id"`
+	ODataType string `json:"@odata.type"`
+}
+
+// newGraphClient creates a Microsoft Graph client for the configured cloud.
+func newGraphClient() (*graphClient, error) {
+	endpoint := microsoftGraphEndpoint()
+	bearer := runtime.NewBearerTokenPolicy(credential, []string{endpoint + "/.default"}, nil)
+	client, err := azcore.NewClient(armRestModuleName, armRestModuleVersion, runtime.PipelineOptions{PerRetry: []policy.Policy{bearer}}, &policy.ClientOptions{Cloud: azureCloud, PerRetryPolicies: []policy.Policy{tracingPolicy{}}, TracingProvider: tracingProvider()})
+	if err != nil {
+		return nil, err
+	}
+	return &graphClient{internal: client, endpoint: endpoint}, nil
+}
+
+// existingPrincipals returns the subset of object IDs that still exist in Entra ID as users, groups, or service
+// principals (which include managed identities), keyed by lower-case ID.
+func (c *graphClient) existingPrincipals(ctx context.Context, objectIDs []string) (_ map[string]string, err error) {
+	ctx, endSpan := runtime.StartSpan(ctx, "graphClient.existingPrincipals", c.internal.Tracer(), nil)
+	defer func() { endSpan(err) }()
+	found := map[string]string{}
+	for start := 0; start < len(objectIDs); start += graphGetByIDsBatchSize {
+		end := min(start+graphGetByIDsBatchSize, len(objectIDs))
+
+		req, err := runtime.NewRequest(ctx, http.MethodPost, runtime.JoinPaths(c.endpoint, "/v1.0/directoryObjects/getByIds"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+		body := map[string][]string{"ids": objectIDs[start:end], "types": {"user", "group", "servicePrincipal"}}
+		if err := runtime.MarshalAsJSON(req, body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		resp, err := c.internal.Pipeline().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, runtime.NewResponseError(resp)
+		}
+		var page struct {
+			Value []graphDirectoryObject `json:"value"`
+		}
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		for _, o := range page.Value {
+			found[strings.ToLower(o.ID)] = strings.TrimPrefix(o.ODataType, "#microsoft.graph.")
+		}
+	}
+	return found, nil
+}
+
+// servicePrincipalObjectID returns the object ID of the service principal for an application ID in the tenant.
+func (c *graphClient) servicePrincipalObjectID(ctx context.Context, appID string) (_ string, err error) {
+	ctx, endSpan := runtime.StartSpan(ctx, "graphClient.servicePrincipalObjectID", c.internal.Tracer(), nil)
+	defer func() { endSpan(err) }()
+	req, err := runtime.NewRequest(ctx, http.MethodGet, runtime.JoinPaths(c.endpoint, "/v1.0/servicePrincipals(appId='"+appID+"')"))
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+	resp, err := c.internal.Pipeline().Do(req)
+	if err != nil {
+		return "", err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return "", runtime.NewResponseError(resp)
+	}
+	var sp graphDirectoryObject
+	if err := runtime.UnmarshalAsJSON(resp, &sp); err != nil {
+		return "", err
+	}
+	return sp.ID, nil
+}
+
+// memberGroupIDs returns the IDs of every group the directory object is a member of, directly or through nested
+// groups, in lower case.
+func (c *graphClient) memberGroupIDs(ctx context.Context, objectID string) (_ []string, err error) {
+	ctx, endSpan := runtime.StartSpan(ctx, "graphClient.memberGroupIDs", c.internal.Tracer(), nil)
+	defer func() { endSpan(err) }()
+	req, err := runtime.NewRequest(ctx, http.MethodPost, runtime.JoinPaths(c.endpoint, "/v1.0/directoryObjects/"+objectID+"/getMemberGroups"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := runtime.MarshalAsJSON(req, map[string]bool{"securityEnabledOnly": false}); err != nil {
+		return nil, fmt.Errorf("failed to encode request body: %w", err)
+	}
+	resp, err := c.internal.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	var groups struct {
+		Value []string `json:"value"`
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &groups); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(groups.Value))
+	for _, id := range groups.Value {
+		ids = append(ids, strings.ToLower(id))
+	}
+	return ids, nil
+}
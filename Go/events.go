@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventgrid/armeventgrid/v2"
+)
+
+// Resource group and subscription system topics publish Azure Resource Manager events for every resource they contain.
+const (
+	resourceGroupTopicType = "Microsoft.Resources.ResourceGroups"
+	subscriptionTopicType  = "Microsoft.Resources.Subscriptions"
+)
+
+// accountChangeEventTypes are the ARM events delivered by default: successful creates, updates, and deletes.
+var accountChangeEventTypes = []string{"Microsoft.Resources.ResourceWriteSuccess", "Microsoft.Resources.ResourceDeleteSuccess"}
+
+// accountEventSubscriptionName is the event subscription the sample creates for the account.
+func accountEventSubscriptionName() string {
+	return accountName + "-changes"
+}
+
+// runEventsCommand subscribes a webhook or storage queue to ARM change events for the account, shows the
+// subscription, or removes it.
+func runEventsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("events")
+	scope := fs.String("scope", "resource-group", "Event source: resource-group or subscription (subscribe only)")
+	webhook := fs.String("webhook", "", "HTTPS endpoint to deliver events to (subscribe only)")
+	storageAccount := fs.String("storage-account", "", "Resource ID of a storage account to deliver events to a queue in (subscribe only)")
+	queue := fs.String("queue", "", "Storage queue name, with -storage-account")
+	actions := fs.Bool("actions", false, "Also deliver successful actions, such as listKeys, regenerateKey, and failovers (subscribe only)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: events [-scope resource-group|subscription] [-actions] (-webhook <url> | -storage-account <id> -queue <name>) subscribe | events (show | unsubscribe)")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch fs.Arg(0) {
+	case "subscribe":
+		var source, topicType, topicName string
+		switch *scope {
+		case "resource-group":
+			source, topicType, topicName = getAssignableScope(ResourceGroup), resourceGroupTopicType, resourceGroupName+"-events"
+		case "subscription":
+			source, topicType, topicName = getAssignableScope(Subscription), subscriptionTopicType, resourceGroupName+"-subscription-events"
+		default:
+			fs.Usage()
+			os.Exit(2)
+		}
+		destination, err := eventDestination(*webhook, *storageAccount, *queue)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		eventTypes := accountChangeEventTypes
+		if *actions {
+			eventTypes = append(eventTypes, "Microsoft.Resources.ResourceActionSuccess")
+		}
+		subscribeToAccountEvents(ctx, source, topicType, topicName, destination, eventTypes)
+	case "show":
+		showAccountEventSubscription(ctx)
+	case "unsubscribe":
+		unsubscribeFromAccountEvents(ctx)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// eventDestination builds the event subscription destination from -webhook, or -storage-account and -queue.
+func eventDestination(webhook string, storageAccount string, queue string) (armeventgrid.EventSubscriptionDestinationClassification, error) {
+	switch {
+	case webhook != "" && storageAccount == "":
+		u, err := url.Parse(webhook)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("-webhook must be an https URL (got %q)", webhook)
+		}
+		return &armeventgrid.WebHookEventSubscriptionDestination{
+			EndpointType: to.Ptr(armeventgrid.EndpointTypeWebHook),
+			Properties:   &armeventgrid.WebHookEventSubscriptionDestinationProperties{EndpointURL: to.Ptr(webhook)},
+		}, nil
+	case storageAccount != "" && webhook == "":
+		id, err := arm.ParseResourceID(storageAccount)
+		if err != nil || !strings.EqualFold(id.ResourceType.String(), "Microsoft.Storage/storageAccounts") {
+			return nil, fmt.Errorf("-storage-account must be a storage account resource ID (got %q)", storageAccount)
+		}
+		if queue == "" {
+			return nil, fmt.Errorf("-queue is required with -storage-account")
+		}
+		return &armeventgrid.StorageQueueEventSubscriptionDestination{
+			EndpointType: to.Ptr(armeventgrid.EndpointTypeStorageQueue),
+			Properties:   &armeventgrid.StorageQueueEventSubscriptionDestinationProperties{ResourceID: to.Ptr(storageAccount), QueueName: to.Ptr(queue)},
+		}, nil
+	}
+	return nil, fmt.Errorf("set either -webhook or -storage-account and -queue")
+}
+
+// newEventGridClients creates the clients for the resource group's system topics and their event subscriptions.
+func newEventGridClients() (*armeventgrid.SystemTopicsClient, *armeventgrid.SystemTopicEventSubscriptionsClient) {
+	topics, err := armeventgrid.NewSystemTopicsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create event grid system topics client: %v", err)
+	}
+	subscriptions, err := armeventgrid.NewSystemTopicEventSubscriptionsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create event grid event subscriptions client: %v", err)
+	}
+	return topics, subscriptions
+}
+
+// subscribeToAccountEvents creates (or reuses) the system topic for the source and creates or updates an event
+// subscription that delivers the account's change events to the destination.
+func subscribeToAccountEvents(ctx context.Context, source string, topicType string, topicName string, destination armeventgrid.EventSubscriptionDestinationClassification, eventTypes []string) {
+	topics, subscriptions := newEventGridClients()
+
+	// A source can have only one system topic, so reuse one created outside the sample.
+	topic, err := findSystemTopic(ctx, topics, source)
+	if err != nil {
+		log.Fatalf("failed to list event grid system topics: %v", err)
+	}
+	if topic == nil {
+		params := armeventgrid.SystemTopic{
+			Location:   to.Ptr("global"),
+			Tags:       sampleTags(ctx),
+			Properties: &armeventgrid.SystemTopicProperties{Source: to.Ptr(source), TopicType: to.Ptr(topicType)},
+		}
+		poller, err := topics.BeginCreateOrUpdate(ctx, resourceGroupName, topicName, params, nil)
+		if err != nil {
+			log.Fatalf("failed to start creating event grid system topic: %v", err)
+		}
+		created, err := pollUntilDone(ctx, poller)
+		if err != nil {
+			log.Fatalf("failed to create event grid system topic: %v", err)
+		}
+		topic = &created.SystemTopic
+		recordResource("Microsoft.EventGrid/systemTopics", topic.ID)
+		fmt.Printf("Created Event Grid System Topic: %s\n", stringValue(topic.ID))
+	} else {
+		topicName = stringValue(topic.Name)
+		fmt.Printf("Using Event Grid System Topic: %s\n", stringValue(topic.ID))
+	}
+
+	// Events from a resource group or subscription cover every resource in it; the subject filter keeps the
+	// account and its child resources (databases, containers, ...).
+	params := armeventgrid.EventSubscription{Properties: &armeventgrid.EventSubscriptionProperties{
+		Destination:         destination,
+		Filter:              &armeventgrid.EventSubscriptionFilter{IncludedEventTypes: to.SliceOfPtrs(eventTypes...), SubjectBeginsWith: to.Ptr(getAssignableScope(Account))},
+		EventDeliverySchema: to.Ptr(armeventgrid.EventDeliverySchemaEventGridSchema),
+	}}
+	poller, err := subscriptions.BeginCreateOrUpdate(ctx, resourceGroupName, topicName, accountEventSubscriptionName(), params, nil)
+	if err != nil {
+		log.Fatalf("failed to start creating event subscription: %v", err)
+	}
+	subscription, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		log.Fatalf("failed to create event subscription (a webhook must answer Event Grid's validation handshake): %v", err)
+	}
+	recordResource("Microsoft.EventGrid/systemTopics/eventSubscriptions", subscription.ID)
+	fmt.Printf("Created/updated Event Subscription (%s): %s\n", enumValue(destination.GetEventSubscriptionDestination().EndpointType), stringValue(subscription.ID))
+}
+
+// findSystemTopic returns the system topic in the resource group whose source is source, or nil.
+func findSystemTopic(ctx context.Context, client *armeventgrid.SystemTopicsClient, source string) (*armeventgrid.SystemTopic, error) {
+	pager := client.NewListByResourceGroupPager(resourceGroupName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page.Value {
+			if t != nil && t.Properties != nil && strings.EqualFold(stringValue(t.Properties.Source), source) {
+				return t, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findAccountEventSubscription returns the system topic that holds the account's event subscription, and the
+// subscription, or nil when the account has none.
+func findAccountEventSubscription(ctx context.Context, topics *armeventgrid.SystemTopicsClient, subscriptions *armeventgrid.SystemTopicEventSubscriptionsClient) (*armeventgrid.SystemTopic, *armeventgrid.EventSubscription, error) {
+	for _, source := range []string{getAssignableScope(ResourceGroup), getAssignableScope(Subscription)} {
+		topic, err := findSystemTopic(ctx, topics, source)
+		if err != nil {
+			return nil, nil, err
+		}
+		if topic == nil {
+			continue
+		}
+		resp, err := subscriptions.Get(ctx, resourceGroupName, stringValue(topic.Name), accountEventSubscriptionName(), nil)
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return topic, &resp.EventSubscription, nil
+	}
+	return nil, nil, nil
+}
+
+// showAccountEventSubscription prints where the account's change events are delivered.
+func showAccountEventSubscription(ctx context.Context) {
+	topics, subscriptions := newEventGridClients()
+	topic, subscription, err := findAccountEventSubscription(ctx, topics, subscriptions)
+	if err != nil {
+		log.Fatalf("failed to get event subscription: %v", err)
+	}
+	if subscription == nil || subscription.Properties == nil {
+		fmt.Printf("No event subscription %s for %s (use events subscribe).\n", accountEventSubscriptionName(), accountName)
+		return
+	}
+	p := subscription.Properties
+	fmt.Printf("Event Subscription: %s\n", stringValue(subscription.ID))
+	if topic.Properties != nil {
+		fmt.Printf("  Source:      %s (%s)\n", stringValue(topic.Properties.Source), stringValue(topic.Properties.TopicType))
+	}
+	fmt.Printf("  State:       %s\n", orDash(enumValue(p.ProvisioningState)))
+	fmt.Printf("  Destination: %s\n", describeEventDestination(p.Destination))
+	if p.Filter != nil {
+		fmt.Printf("  Events:      %s\n", joinStrings(p.Filter.IncludedEventTypes))
+		fmt.Printf("  Subjects:    %s*\n", stringValue(p.Filter.SubjectBeginsWith))
+	}
+}
+
+// describeEventDestination returns the endpoint type and the queue, or the webhook's base URL; Event Grid doesn't
+// return the full URL, which can contain a secret.
+func describeEventDestination(d armeventgrid.EventSubscriptionDestinationClassification) string {
+	switch d := d.(type) {
+	case *armeventgrid.StorageQueueEventSubscriptionDestination:
+		if d.Properties != nil {
+			return fmt.Sprintf("StorageQueue %s (queue %s)", stringValue(d.Properties.ResourceID), stringValue(d.Properties.QueueName))
+		}
+	case *armeventgrid.WebHookEventSubscriptionDestination:
+		if d.Properties != nil {
+			return "WebHook " + stringValue(d.Properties.EndpointBaseURL)
+		}
+	case nil:
+		return "-"
+	}
+	return enumValue(d.GetEventSubscriptionDestination().EndpointType)
+}
+
+// unsubscribeFromAccountEvents deletes the account's event subscription, and the system topic when the sample created
+// it and nothing else subscribes to it.
+func unsubscribeFromAccountEvents(ctx context.Context) {
+	topics, subscriptions := newEventGridClients()
+	topic, subscription, err := findAccountEventSubscription(ctx, topics, subscriptions)
+	if err != nil {
+		log.Fatalf("failed to get event subscription: %v", err)
+	}
+	if subscription == nil {
+		fmt.Printf("No event subscription %s for %s.\n", accountEventSubscriptionName(), accountName)
+		return
+	}
+
+	topicName := stringValue(topic.Name)
+	poller, err := subscriptions.BeginDelete(ctx, resourceGroupName, topicName, accountEventSubscriptionName(), nil)
+	if err != nil {
+		log.Fatalf("failed to start deleting event subscription: %v", err)
+	}
+	if _, err := pollUntilDone(ctx, poller); err != nil {
+		log.Fatalf("failed to delete event subscription: %v", err)
+	}
+	fmt.Printf("Deleted Event Subscription: %s\n", stringValue(subscription.ID))
+
+	if topic.Tags[runIDTagName] == nil {
+		return
+	}
+	pager := subscriptions.NewListBySystemTopicPager(resourceGroupName, topicName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Printf("Could not list the system topic's event subscriptions: %v", err)
+			return
+		}
+		if len(page.Value) > 0 {
+			return
+		}
+	}
+	topicPoller, err := topics.BeginDelete(ctx, resourceGroupName, topicName, nil)
+	if err != nil {
+		log.Fatalf("failed to start deleting event grid system topic: %v", err)
+	}
+	if _, err := pollUntilDone(ctx, topicPoller); err != nil {
+		log.Fatalf("failed to delete event grid system topic: %v", err)
+	}
+	fmt.Printf("Deleted Event Grid System Topic: %s\n", stringValue(topic.ID))
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/spf13/viper"
+)
+
+// exporterAccounts are the resource IDs of the accounts the exporter scrapes (the ExporterAccounts setting, or the
+// configured account).
+var exporterAccounts []string
+
+// loadExporterSettings reads ExporterAccounts: account names in ResourceGroupName, or full account resource IDs.
+func loadExporterSettings() error {
+	exporterAccounts = nil
+	for _, raw := range viper.GetStringSlice("ExporterAccounts") {
+		entry := strings.TrimSpace(raw)
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, "/"):
+			id, err := arm.ParseResourceID(entry)
+			if err != nil || !strings.EqualFold(id.ResourceType.String(), "Microsoft.DocumentDB/databaseAccounts") {
+				return fmt.Errorf("ExporterAccounts entry %q is not a Cosmos DB account resource ID", raw)
+			}
+		case !accountNamePattern.MatchString(entry):
+			return fmt.Errorf("ExporterAccounts entry %q is not a valid account name", raw)
+		default:
+			entry = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s", subscriptionID, resourceGroupName, entry)
+		}
+		exporterAccounts = append(exporterAccounts, entry)
+	}
+	return nil
+}
+
+// exporterMetric is an Azure Monitor metric the exporter publishes as a Prometheus gauge.
+type exporterMetric struct {
+	name   string
+	help   string
+	query  metricQuery
+	filter string
+	grain  time.Duration
+	// missingIsZero reports 0 when Azure Monitor returns no data points, as it does for a filtered count with no
+	// matching requests.
+	missingIsZero bool
+}
+
+// exporterMetrics are read for each account on every scrape. Each gauge is the latest time grain Azure Monitor has
+// data for, which usually lags a few minutes behind.
+var exporterMetrics = []exporterMetric{
+	{name: "cosmosdb_total_request_units", help: "Request units consumed in the latest minute.", query: metricQuery{name: "TotalRequestUnits", aggregation: "Total"}, grain: time.Minute, missingIsZero: true},
+	{name: "cosmosdb_normalized_ru_consumption_percent", help: "Highest normalized RU consumption (0-100) across partitions in the latest minute.", query: metricQuery{name: "NormalizedRUConsumption", aggregation: "Maximum"}, grain: time.Minute},
+	{name: "cosmosdb_throttled_requests", help: "Requests throttled with status 429 in the latest minute.", query: metricQuery{name: "TotalRequests", aggregation: "Count"}, filter: "StatusCode eq '429'", grain: time.Minute, missingIsZero: true},
+	{name: "cosmosdb_data_usage_bytes", help: "Data stored in the account, in bytes.", query: metricQuery{name: "DataUsage", aggregation: "Total"}, grain: 5 * time.Minute},
+	{name: "cosmosdb_index_usage_bytes", help: "Index storage used by the account, in bytes.", query: metricQuery{name: "IndexUsage", aggregation: "Total"}, grain: 5 * time.Minute},
+}
+
+// exporterLookback is how far back each scrape reads, so the latest grain is found despite Azure Monitor's ingestion
+// delay.
+const exporterLookback = 30 * time.Minute
+
+// exporterSample is one gauge value for one account.
+type exporterSample struct {
+	metric  string
+	account string
+	group   string
+	value   float64
+}
+
+// exporterState is the result of the latest scrape, served on /metrics.
+type exporterState struct {
+	mu         sync.Mutex
+	samples    []exporterSample
+	up         map[string]bool
+	lastScrape time.Time
+	duration   time.Duration
+}
+
+// runExporterCommand scrapes Azure Monitor metrics for the exporter accounts on an interval and serves them in the
+// Prometheus text format until the process is stopped.
+func runExporterCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("exporter")
+	listen := fs.String("listen", ":9464", "Address to serve /metrics on")
+	interval := fs.Duration("interval", time.Minute, "How often to read metrics from Azure Monitor (at least 1m)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: exporter [-listen :9464] [-interval 1m]")
+		fmt.Fprintln(fs.Output(), "Scrapes the ExporterAccounts setting (default: the configured account).")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *interval < time.Minute {
+		log.Fatalf("-interval must be at least 1m, the finest Azure Monitor time grain (got %s)", *interval)
+	}
+
+	accounts := exporterAccounts
+	if len(accounts) == 0 {
+		accounts = []string{getAssignableScope(Account)}
+	}
+	state := &exporterState{}
+	go func() {
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+		for {
+			state.scrape(ctx, accounts)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		state.write(w)
+	})
+	fmt.Printf("Serving metrics for %d account(s) on http://%s/metrics (scraping every %s)\n", len(accounts), *listen, *interval)
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("failed to serve metrics: %v", err)
+	}
+}
+
+// scrape reads every exporter metric for every account and replaces the served values. An account whose metrics
+// can't be read is reported with cosmosdb_exporter_up 0 and keeps no stale values.
+func (s *exporterState) scrape(ctx context.Context, accounts []string) {
+	start := time.Now()
+	var samples []exporterSample
+	up := map[string]bool{}
+	for _, id := range accounts {
+		name, group := id[strings.LastIndex(id, "/")+1:], ""
+		if parsed, err := arm.ParseResourceID(id); err == nil {
+			group = parsed.ResourceGroupName
+		}
+		accountSamples, err := scrapeAccountMetrics(ctx, id)
+		if err != nil {
+			log.Printf("Could not read metrics for %s: %v", name, err)
+		}
+		up[name+"\x00"+group] = err == nil
+		for _, sample := range accountSamples {
+			sample.account, sample.group = name, group
+			samples = append(samples, sample)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples, s.up = samples, up
+	s.lastScrape, s.duration = start, time.Since(start)
+}
+
+// scrapeAccountMetrics reads each exporter metric for one account.
+func scrapeAccountMetrics(ctx context.Context, id string) ([]exporterSample, error) {
+	samples := make([]exporterSample, 0, len(exporterMetrics))
+	for _, m := range exporterMetrics {
+		metric, err := queryResourceMetric(ctx, id, m.query, m.filter, exporterLookback, m.grain)
+		if err != nil {
+			return samples, fmt.Errorf("failed to read %s: %w", m.query.name, err)
+		}
+		value, ok := latestMetricValue(metric, m.query.aggregation)
+		if !ok && !m.missingIsZero {
+			continue
+		}
+		samples = append(samples, exporterSample{metric: m.name, value: value})
+	}
+	return samples, nil
+}
+
+// latestMetricValue returns the value of the latest time grain with data, summed across time series (a filtered
+// metric returns one series per dimension value).
+func latestMetricValue(metric *armmonitor.Metric, aggregation string) (float64, bool) {
+	var latest time.Time
+	var total float64
+	found := false
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		for _, point := range series.Data {
+			value, ok := metricValue(point, aggregation)
+			if !ok || point.TimeStamp == nil {
+				continue
+			}
+			switch {
+			case point.TimeStamp.After(latest):
+				latest, total, found = *point.TimeStamp, value, true
+			case point.TimeStamp.Equal(latest):
+				total += value
+			}
+		}
+	}
+	return total, found
+}
+
+// write serves the latest scrape in the Prometheus text exposition format.
+func (s *exporterState) write(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byMetric := map[string][]exporterSample{}
+	for _, sample := range s.samples {
+		byMetric[sample.metric] = append(byMetric[sample.metric], sample)
+	}
+	for _, m := range exporterMetrics {
+		samples := byMetric[m.name]
+		if len(samples) == 0 {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i].account < samples[j].account })
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, sample := range samples {
+			fmt.Fprintf(w, "%s{account=%s,resource_group=%s} %g\n", m.name, prometheusLabel(sample.account), prometheusLabel(sample.group), sample.value)
+		}
+	}
+
+	keys := make([]string, 0, len(s.up))
+	for key := range s.up {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintln(w, "# HELP cosmosdb_exporter_up Whether the latest scrape read every metric of the account (1) or not (0).")
+	fmt.Fprintln(w, "# TYPE cosmosdb_exporter_up gauge")
+	for _, key := range keys {
+		name, group, _ := strings.Cut(key, "\x00")
+		up := 0
+		if s.up[key] {
+			up = 1
+		}
+		fmt.Fprintf(w, "cosmosdb_exporter_up{account=%s,resource_group=%s} %d\n", prometheusLabel(name), prometheusLabel(group), up)
+	}
+	if !s.lastScrape.IsZero() {
+		fmt.Fprintln(w, "# HELP cosmosdb_exporter_last_scrape_timestamp_seconds When the latest scrape of Azure Monitor started.")
+		fmt.Fprintln(w, "# TYPE cosmosdb_exporter_last_scrape_timestamp_seconds gauge")
+		fmt.Fprintf(w, "cosmosdb_exporter_last_scrape_timestamp_seconds %d\n", s.lastScrape.Unix())
+		fmt.Fprintln(w, "# HELP cosmosdb_exporter_scrape_duration_seconds How long the latest scrape of Azure Monitor took.")
+		fmt.Fprintln(w, "# TYPE cosmosdb_exporter_scrape_duration_seconds gauge")
+		fmt.Fprintf(w, "cosmosdb_exporter_scrape_duration_seconds %g\n", s.duration.Seconds())
+	}
+}
+
+// prometheusLabel quotes a label value, escaping backslashes, quotes, and newlines.
+func prometheusLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runThroughputPoolCommand manages a throughput pool: a fleet with a fleetspace whose throughput is shared by its accounts.
+func runThroughputPoolCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("throughput-pool")
+	minThroughput := fs.Int("min", 100000, "Pool minimum RU/s (create only)")
+	maxThroughput := fs.Int("max", 500000, "Pool maximum RU/s (create only)")
+	tier := fs.String("tier", string(armcosmos.FleetspacePropertiesServiceTierGeneralPurpose), "Service tier: GeneralPurpose (single write region) or BusinessCritical (multi-region writes) (create only)")
+	window := fs.Duration("window", time.Hour, "How far back to read RU consumption (show only)")
+	report := addReportFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: throughput-pool [flags] [show | create | add-account | remove-account]")
+		fmt.Fprintf(fs.Output(), "The pool is fleetspace %q in fleet %q (FleetspaceName / FleetName settings).\n", fleetspaceName, fleetName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "", "show":
+		showThroughputPool(ctx, *window, report)
+	case "create":
+		if *minThroughput <= 0 || *maxThroughput < *minThroughput {
+			log.Fatalf("invalid pool throughput: -min must be positive and <= -max (got %d and %d)", *minThroughput, *maxThroughput)
+		}
+		createOrUpdateFleet(ctx)
+		createOrUpdateFleetspace(ctx, int32(*minThroughput), int32(*maxThroughput), armcosmos.FleetspacePropertiesServiceTier(*tier))
+	case "add-account":
+		addAccountToFleetspace(ctx)
+	case "remove-account":
+		removeAccountFromFleetspace(ctx)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// createOrUpdateFleet creates the fleet that holds the throughput pool.
+func createOrUpdateFleet(ctx context.Context) {
+	fleetClient, err := armcosmos.NewFleetClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleet client: %v", err)
+	}
+
+	properties := armcosmos.FleetResource{
+		Location: &location,
+		Tags:     sampleTags(ctx),
+	}
+
+	resp, err := fleetClient.Create(ctx, resourceGroupName, fleetName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update fleet: %v", err)
+	}
+
+	fmt.Printf("Created/updated Fleet: %s\n", *resp.ID)
+}
+
+// createOrUpdateFleetspace creates the fleetspace (throughput pool) with the given RU/s range.
+func createOrUpdateFleetspace(ctx context.Context, minThroughput int32, maxThroughput int32, tier armcosmos.FleetspacePropertiesServiceTier) {
+	log.Printf("Starting throughput pool create/update (this can take a couple minutes): fleetspace=%s", fleetspaceName)
+
+	fleetspaceClient, err := armcosmos.NewFleetspaceClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleetspace client: %v", err)
+	}
+
+	properties := armcosmos.FleetspaceResource{
+		Properties: &armcosmos.FleetspaceProperties{
+			FleetspaceAPIKind: to.Ptr(armcosmos.FleetspacePropertiesFleetspaceAPIKindNoSQL),
+			ServiceTier:       to.Ptr(tier),
+			DataRegions:       to.SliceOfPtrs(location),
+			ThroughputPoolConfiguration: &armcosmos.FleetspacePropertiesThroughputPoolConfiguration{
+				MinThroughput: to.Ptr(minThroughput),
+				MaxThroughput: to.Ptr(maxThroughput),
+			},
+		},
+	}
+
+	pollerResp, err := fleetspaceClient.BeginCreate(ctx, resourceGroupName, fleetName, fleetspaceName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to begin create or update fleetspace: %v", err)
+	}
+
+	resp, err := pollUntilDone(ctx, pollerResp)
+	if err != nil {
+		log.Fatalf("failed to poll the result: %v", err)
+	}
+
+	fmt.Printf("Created/updated Throughput Pool: %s\n", *resp.ID)
+}
+
+// addAccountToFleetspace adds the configured account to the throughput pool.
+func addAccountToFleetspace(ctx context.Context) {
+	log.Printf("Adding account to throughput pool: account=%s fleetspace=%s", accountName, fleetspaceName)
+
+	fleetspaceAccountClient, err := armcosmos.NewFleetspaceAccountClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleetspace account client: %v", err)
+	}
+
+	properties := armcosmos.FleetspaceAccountResource{
+		Properties: &armcosmos.FleetspaceAccountProperties{
+			GlobalDatabaseAccountProperties: &armcosmos.FleetspaceAccountPropertiesGlobalDatabaseAccountProperties{
+				ResourceID:  to.Ptr(getAssignableScope(Account)),
+				ArmLocation: &location,
+			},
+		},
+	}
+
+	pollerResp, err := fleetspaceAccountClient.BeginCreate(ctx, resourceGroupName, fleetName, fleetspaceName, accountName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to begin add account to fleetspace: %v", err)
+	}
+
+	resp, err := pollUntilDone(ctx, pollerResp)
+	if err != nil {
+		log.Fatalf("failed to poll the result: %v", err)
+	}
+
+	fmt.Printf("Added Account to Throughput Pool: %s\n", *resp.ID)
+}
+
+// removeAccountFromFleetspace removes the configured account from the throughput pool.
+func removeAccountFromFleetspace(ctx context.Context) {
+	fleetspaceAccountClient, err := armcosmos.NewFleetspaceAccountClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleetspace account client: %v", err)
+	}
+
+	pollerResp, err := fleetspaceAccountClient.BeginDelete(ctx, resourceGroupName, fleetName, fleetspaceName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to begin remove account from fleetspace: %v", err)
+	}
+
+	if _, err := pollUntilDone(ctx, pollerResp); err != nil {
+		log.Fatalf("failed to remove account from fleetspace: %v", err)
+	}
+
+	fmt.Printf("Removed Account from Throughput Pool: %s\n", accountName)
+}
+
+// showThroughputPool reports the pool configuration and how much of it each member account consumed over the window.
+func showThroughputPool(ctx context.Context, window time.Duration, report reportOptions) {
+	fleetspaceClient, err := armcosmos.NewFleetspaceClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleetspace client: %v", err)
+	}
+	fleetspaceAccountClient, err := armcosmos.NewFleetspaceAccountClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db fleetspace account client: %v", err)
+	}
+
+	fleetspace, err := fleetspaceClient.Get(ctx, resourceGroupName, fleetName, fleetspaceName, nil)
+	if err != nil {
+		log.Fatalf("failed to get fleetspace: %v", err)
+	}
+
+	var minThroughput, maxThroughput int32
+	pool := reportTable{headers: []string{"POOL", "TIER", "STATE", "MIN RU/S", "MAX RU/S"}}
+	if p := fleetspace.Properties; p != nil {
+		if c := p.ThroughputPoolConfiguration; c != nil {
+			if c.MinThroughput != nil {
+				minThroughput = *c.MinThroughput
+			}
+			if c.MaxThroughput != nil {
+				maxThroughput = *c.MaxThroughput
+			}
+		}
+		pool.addRow(fleetName+"/"+fleetspaceName, enumValue(p.ServiceTier), enumValue(p.ProvisioningState), strconv.Itoa(int(minThroughput)), strconv.Itoa(int(maxThroughput)))
+	}
+
+	accountIDs := make([]string, 0)
+	pager := fleetspaceAccountClient.NewListPager(resourceGroupName, fleetName, fleetspaceName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list fleetspace accounts: %v", err)
+		}
+		for _, a := range page.Value {
+			if a != nil && a.Properties != nil && a.Properties.GlobalDatabaseAccountProperties != nil && a.Properties.GlobalDatabaseAccountProperties.ResourceID != nil {
+				accountIDs = append(accountIDs, *a.Properties.GlobalDatabaseAccountProperties.ResourceID)
+			}
+		}
+	}
+
+	if len(accountIDs) == 0 {
+		pool.addNote("No accounts are in the pool yet (use throughput-pool add-account).")
+		if err := writeReport(report, "Throughput pool "+fleetName+"/"+fleetspaceName, pool); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	// Sum per-minute RU totals across member accounts to get pool-wide RU/s.
+	query := metricQuery{name: "TotalRequestUnits", aggregation: "Total"}
+	poolPerMinute := map[time.Time]float64{}
+	usage := reportTable{title: fmt.Sprintf("RU consumption over the last %s", window), headers: []string{"ACCOUNT", "AVG RU/S", "PEAK RU/S"}}
+	for _, id := range accountIDs {
+		name := id[strings.LastIndex(id, "/")+1:]
+		metric, err := queryResourceMetric(ctx, id, query, "", window, time.Minute)
+		if err != nil {
+			usage.addRow(name, "n/a", "n/a")
+			continue
+		}
+
+		var sum, peak float64
+		for _, series := range metric.Timeseries {
+			if series == nil {
+				continue
+			}
+			for _, point := range series.Data {
+				value, ok := metricValue(point, query.aggregation)
+				if !ok || point.TimeStamp == nil {
+					continue
+				}
+				sum += value
+				peak = max(peak, value/60)
+				poolPerMinute[*point.TimeStamp] += value
+			}
+		}
+		usage.addRow(name, fmt.Sprintf("%.0f", sum/window.Seconds()), fmt.Sprintf("%.0f", peak))
+	}
+
+	var poolSum, poolPeak float64
+	for _, v := range poolPerMinute {
+		poolSum += v
+		poolPeak = max(poolPeak, v/60)
+	}
+	poolAverage := poolSum / window.Seconds()
+	if maxThroughput > 0 {
+		usage.addNote("Pool: average %.0f RU/s (%.1f%% of max), peak %.0f RU/s (%.1f%% of max)", poolAverage, poolAverage/float64(maxThroughput)*100, poolPeak, poolPeak/float64(maxThroughput)*100)
+	} else {
+		usage.addNote("Pool: average %.0f RU/s, peak %.0f RU/s", poolAverage, poolPeak)
+	}
+	if err := writeReport(report, "Throughput pool "+fleetName+"/"+fleetspaceName, pool, usage); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
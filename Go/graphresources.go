@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+)
+
+// Graph resources are only available in preview api-versions of the Cosmos DB resource provider.
+const graphResourcesAPIVersion = "2024-12-01-preview"
+
+// graphResource is a Graph resource on an account that has the GraphAPICompute service.
+type graphResource struct {
+	ID         *string                  `json:"id,omitempty"`
+	Name       *string                  `json:"name,omitempty"`
+	Location   *string                  `json:"location,omitempty"`
+	Properties *graphResourceProperties `json:"properties,omitempty"`
+}
+
+type graphResourceProperties struct {
+	Resource *graphResourceID `json:"resource,omitempty"`
+	Options  map[string]any   `json:"options,omitempty"`
+}
+
+type graphResourceID struct {
+	ID string `json:"id"`
+}
+
+type graphResourceList struct {
+	Value []*graphResource `json:"value"`
+}
+
+// graphResourcesClient manages Graph resources on the configured account.
+type graphResourcesClient struct {
+	rest *armRestClient
+}
+
+func newGraphResourcesClient() (*graphResourcesClient, error) {
+	rest, err := newARMRestClient(graphResourcesAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &graphResourcesClient{rest: rest}, nil
+}
+
+func (c *graphResourcesClient) graphsPath() string {
+	return getAssignableScope(Account) + "/graphs"
+}
+
+// list returns the Graph resources on the account.
+func (c *graphResourcesClient) list(ctx context.Context) ([]*graphResource, error) {
+	var resp graphResourceList
+	if err := c.rest.do(ctx, http.MethodGet, c.graphsPath(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Value, nil
+}
+
+// createOrUpdate creates or updates a Graph resource and waits for the operation to finish.
+func (c *graphResourcesClient) createOrUpdate(ctx context.Context, graphName string) (graphResource, error) {
+	params := graphResource{
+		Location: &location,
+		Properties: &graphResourceProperties{
+			Resource: &graphResourceID{ID: graphName},
+			Options:  map[string]any{},
+		},
+	}
+
+	poller, err := beginARMOperation[graphResource](ctx, c.rest, http.MethodPut, c.graphsPath()+"/"+graphName, params)
+	if err != nil {
+		return graphResource{}, err
+	}
+	return pollUntilDone(ctx, poller)
+}
+
+// delete deletes a Graph resource and waits for the operation to finish.
+func (c *graphResourcesClient) delete(ctx context.Context, graphName string) error {
+	poller, err := beginARMOperation[struct{}](ctx, c.rest, http.MethodDelete, c.graphsPath()+"/"+graphName, nil)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone(ctx, poller)
+	return err
+}
+
+// runGraphsCommand lists, creates, or deletes Graph resources on an account with the GraphAPICompute service.
+func runGraphsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("graphs")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: graphs [list | create <name> | delete <name>]")
+		fmt.Fprintln(fs.Output(), "The account must have the GraphAPICompute service (see the services command).")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	client, err := newGraphResourcesClient()
+	if err != nil {
+		log.Fatalf("failed to create graph resources client: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "", "list":
+		graphs, err := client.list(ctx)
+		if err != nil {
+			log.Fatalf("failed to list graph resources: %v", err)
+		}
+		if len(graphs) == 0 {
+			fmt.Printf("No Graph resources on account %s.\n", accountName)
+			return
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tLOCATION\tID")
+		for _, g := range graphs {
+			if g != nil {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", stringValue(g.Name), stringValue(g.Location), stringValue(g.ID))
+			}
+		}
+		_ = tw.Flush()
+	case "create":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		log.Printf("Starting Graph resource create/update: graph=%s", fs.Arg(1))
+		graph, err := client.createOrUpdate(ctx, fs.Arg(1))
+		if err != nil {
+			log.Fatalf("failed to create or update graph resource: %v", err)
+		}
+		if graph.ID != nil {
+			fmt.Printf("Created/updated Graph Resource: %s\n", *graph.ID)
+			return
+		}
+		fmt.Println("Created/updated Graph Resource.")
+	case "delete":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if err := client.delete(ctx, fs.Arg(1)); err != nil {
+			log.Fatalf("failed to delete graph resource: %v", err)
+		}
+		fmt.Printf("Deleted Graph Resource: %s\n", fs.Arg(1))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
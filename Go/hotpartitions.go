@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// partitionRangeUsage is the normalized RU consumption observed for one physical partition (partition key range).
+type partitionRangeUsage struct {
+	rangeID string
+	avg     float64
+	max     float64
+	share   float64
+	hot     bool
+}
+
+// runHotPartitionsCommand flags physical partitions that consume a disproportionate share of the container's RU/s.
+func runHotPartitionsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("hot-partitions")
+	window := fs.Duration("window", 24*time.Hour, "How far back to analyze partition metrics")
+	interval := fs.Duration("interval", 5*time.Minute, "Metric time grain")
+	factor := fs.Float64("factor", 2, "Flag a partition when its share of RU consumption exceeds factor x the even share")
+	top := fs.Int("top", 10, "Number of top partition key values to show from PartitionKeyRUConsumption logs")
+	_ = fs.Parse(args)
+
+	filter := containerMetricFilter(databaseName, containerName) + " and PartitionKeyRangeId eq '*'"
+	metric, err := queryAccountMetric(ctx, metricQuery{name: "NormalizedRUConsumption", aggregation: "Maximum"}, filter, *window, *interval)
+	if err != nil {
+		log.Fatalf("failed to query partition metrics: %v", err)
+	}
+
+	ranges := make([]partitionRangeUsage, 0, len(metric.Timeseries))
+	total := 0.0
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+
+		usage := partitionRangeUsage{rangeID: "(unknown)"}
+		if id := seriesDimension(series, "PartitionKeyRangeId"); id != "" {
+			usage.rangeID = id
+		}
+
+		points := 0
+		for _, point := range series.Data {
+			value, ok := metricValue(point, "Maximum")
+			if !ok {
+				continue
+			}
+			points++
+			usage.avg += value
+			if value > usage.max {
+				usage.max = value
+			}
+		}
+		if points > 0 {
+			usage.avg /= float64(points)
+		}
+		total += usage.avg
+		ranges = append(ranges, usage)
+	}
+
+	if len(ranges) == 0 {
+		fmt.Printf("No partition-level metrics were returned for %s/%s over the last %s.\n", databaseName, containerName, *window)
+		return
+	}
+
+	evenShare := 1 / float64(len(ranges))
+	for i := range ranges {
+		if total > 0 {
+			ranges[i].share = ranges[i].avg / total
+		}
+		ranges[i].hot = len(ranges) > 1 && ranges[i].share > *factor*evenShare
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].share > ranges[j].share })
+
+	fmt.Printf("Normalized RU consumption by partition key range for %s/%s over the last %s\n", databaseName, containerName, *window)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARTITION KEY RANGE\tAVG %\tMAX %\tSHARE\tHOT")
+	hotCount := 0
+	for _, r := range ranges {
+		marker := ""
+		if r.hot {
+			marker = "yes"
+			hotCount++
+		}
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\t%.1f%%\t%s\n", r.rangeID, r.avg, r.max, r.share*100, marker)
+	}
+	_ = tw.Flush()
+
+	if hotCount == 0 {
+		fmt.Println("No partition exceeds the hot-partition threshold.")
+	} else {
+		fmt.Printf("%d partition(s) consume more than %.1fx their even share (%.1f%%) of RU/s.\n", hotCount, *factor, evenShare*100)
+	}
+
+	printTopPartitionKeys(ctx, *window, *top)
+}
+
+// printTopPartitionKeys prints the partition key values that consumed the most RUs, using PartitionKeyRUConsumption logs when available.
+func printTopPartitionKeys(ctx context.Context, window time.Duration, top int) {
+	query := fmt.Sprintf(`CDBPartitionKeyRUConsumption
+| where DatabaseName == %s and CollectionName == %s
+| summarize TotalRequestCharge = sum(todouble(RequestCharge)) by PartitionKey, PartitionKeyRangeId
+| top %d by TotalRequestCharge desc`, kqlString(databaseName), kqlString(containerName), top)
+
+	table, err := queryAccountLogs(ctx, query, window)
+	if err != nil {
+		fmt.Printf("Top partition key values are unavailable (is PartitionKeyRUConsumption enabled in the diagnostic setting?): %v\n", err)
+		return
+	}
+	if len(table.Rows) == 0 {
+		fmt.Println("No PartitionKeyRUConsumption logs were found for the container in this window.")
+		return
+	}
+
+	keyIndex := logColumnIndex(table, "PartitionKey")
+	rangeIndex := logColumnIndex(table, "PartitionKeyRangeId")
+	chargeIndex := logColumnIndex(table, "TotalRequestCharge")
+
+	fmt.Println()
+	fmt.Println("Top partition key values by RU consumption:")
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARTITION KEY\tPARTITION KEY RANGE\tTOTAL RU")
+	for _, row := range table.Rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", logCell(row, keyIndex), logCell(row, rangeIndex), logCell(row, chargeIndex))
+	}
+	_ = tw.Flush()
+}
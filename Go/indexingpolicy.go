@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// indexingPolicyKeys are the indexing policy properties armcosmos.IndexingPolicy understands. Any other key in a
+// policy file is rejected rather than silently dropped.
+var indexingPolicyKeys = map[string]bool{
+	"automatic":        true,
+	"compositeIndexes": true,
+	"excludedPaths":    true,
+	"fullTextIndexes":  true,
+	"includedPaths":    true,
+	"indexingMode":     true,
+	"spatialIndexes":   true,
+	"vectorIndexes":    true,
+}
+
+// loadIndexingPolicy reads an indexing policy JSON file in the format the portal's Settings > Indexing Policy editor
+// shows and exports.
+func loadIndexingPolicy(path string) (*armcosmos.IndexingPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexing policy: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse indexing policy %s: %w", path, err)
+	}
+	unknown := make([]string, 0)
+	for key := range raw {
+		if !indexingPolicyKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("indexing policy %s has unsupported properties %v (property names are case-sensitive)", path, unknown)
+	}
+
+	policy := &armcosmos.IndexingPolicy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse indexing policy %s: %w", path, err)
+	}
+	return policy, nil
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// inventoryResource is a database, container, keyspace, table, or graph and its own throughput, if any.
+type inventoryResource struct {
+	kind       string
+	name       string
+	throughput *armcosmos.ThroughputSettingsGetProperties
+	// container is a NoSQL container's definition, as listed; nil for other resources.
+	container *armcosmos.SQLContainerGetPropertiesResource
+}
+
+// inventoryAccount is one account in the subscription and its throughput totals.
+type inventoryAccount struct {
+	name          string
+	resourceGroup string
+	api           string
+	regions       int
+	serverless    bool
+	resources     []inventoryResource
+	manualRU      int
+	autoscaleRU   int
+	err           error
+}
+
+// totalRU is the provisioned RU/s (manual RU/s plus autoscale max) in each region.
+func (a inventoryAccount) totalRU() int {
+	return a.manualRU + a.autoscaleRU
+}
+
+// inventoryClients are the per-API resource clients the inventory reads with.
+type inventoryClients struct {
+	sql       *armcosmos.SQLResourcesClient
+	mongo     *armcosmos.MongoDBResourcesClient
+	cassandra *armcosmos.CassandraResourcesClient
+	gremlin   *armcosmos.GremlinResourcesClient
+	table     *armcosmos.TableResourcesClient
+}
+
+// runInventoryCommand lists every Cosmos DB account in the subscription with its databases and containers (or the
+// equivalent for its API), and totals the provisioned throughput per account and overall.
+func runInventoryCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("inventory")
+	details := fs.Bool("details", false, "Also list each account's databases and containers with their throughput")
+	report := addReportFlags(fs)
+	_ = fs.Parse(args)
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	clients, err := newInventoryClients()
+	if err != nil {
+		log.Fatalf("failed to create cosmos db resource clients: %v", err)
+	}
+
+	var accounts []inventoryAccount
+	pager := accountClient.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list cosmos db accounts: %v", err)
+		}
+		for _, a := range page.Value {
+			if a == nil || a.ID == nil {
+				continue
+			}
+			accounts = append(accounts, inventoryAccountOf(ctx, clients, *a))
+		}
+	}
+	if len(accounts) == 0 {
+		fmt.Printf("No Cosmos DB accounts in subscription %s.\n", subscriptionID)
+		return
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].name < accounts[j].name })
+
+	var tables []reportTable
+	if *details {
+		tables = append(tables, inventoryDetailsTable(accounts))
+	}
+	tables = append(tables, inventorySummaryTable(accounts))
+	for _, a := range accounts {
+		if a.err != nil {
+			log.Printf("Could not read all of %s: %v", a.name, a.err)
+		}
+	}
+	if err := writeReport(report, "Cosmos DB throughput inventory", tables...); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+func newInventoryClients() (inventoryClients, error) {
+	var c inventoryClients
+	var err error
+	if c.sql, err = armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions()); err != nil {
+		return c, err
+	}
+	if c.mongo, err = armcosmos.NewMongoDBResourcesClient(subscriptionID, credential, armClientOptions()); err != nil {
+		return c, err
+	}
+	if c.cassandra, err = armcosmos.NewCassandraResourcesClient(subscriptionID, credential, armClientOptions()); err != nil {
+		return c, err
+	}
+	if c.gremlin, err = armcosmos.NewGremlinResourcesClient(subscriptionID, credential, armClientOptions()); err != nil {
+		return c, err
+	}
+	c.table, err = armcosmos.NewTableResourcesClient(subscriptionID, credential, armClientOptions())
+	return c, err
+}
+
+// inventoryAccountOf reads one account's resources and totals their throughput. Errors are kept on the account so one
+// inaccessible account doesn't stop the inventory.
+func inventoryAccountOf(ctx context.Context, clients inventoryClients, account armcosmos.DatabaseAccountGetResults) inventoryAccount {
+	a := inventoryAccount{name: stringValue(account.Name), api: accountAPI(account), regions: 1, serverless: isServerless(account)}
+	if id, err := arm.ParseResourceID(*account.ID); err == nil {
+		a.resourceGroup = id.ResourceGroupName
+	}
+	if account.Properties != nil && len(account.Properties.Locations) > 0 {
+		a.regions = len(account.Properties.Locations)
+	}
+
+	switch a.api {
+	case "Cassandra":
+		a.resources, a.err = listCassandraInventory(ctx, clients.cassandra, a.resourceGroup, a.name)
+	case "Gremlin":
+		a.resources, a.err = listGremlinInventory(ctx, clients.gremlin, a.resourceGroup, a.name)
+	case "Table":
+		a.resources, a.err = listTableInventory(ctx, clients.table, a.resourceGroup, a.name)
+	case "MongoDB":
+		a.resources, a.err = listMongoInventory(ctx, clients.mongo, a.resourceGroup, a.name)
+	default:
+		a.resources, a.err = listSQLInventory(ctx, clients.sql, a.resourceGroup, a.name)
+	}
+	for _, r := range a.resources {
+		if r.throughput == nil || r.throughput.Resource == nil {
+			continue
+		}
+		t := r.throughput.Resource
+		switch {
+		case t.AutoscaleSettings != nil && t.AutoscaleSettings.MaxThroughput != nil:
+			a.autoscaleRU += int(*t.AutoscaleSettings.MaxThroughput)
+		case t.Throughput != nil:
+			a.manualRU += int(*t.Throughput)
+		}
+	}
+	return a
+}
+
+// accountAPI returns the API an account serves, from its kind and capabilities.
+func accountAPI(account armcosmos.DatabaseAccountGetResults) string {
+	if account.Kind != nil && *account.Kind == armcosmos.DatabaseAccountKindMongoDB {
+		return "MongoDB"
+	}
+	if account.Properties != nil {
+		for _, c := range account.Properties.Capabilities {
+			switch stringValue(c.Name) {
+			case "EnableCassandra":
+				return "Cassandra"
+			case "EnableGremlin":
+				return "Gremlin"
+			case "EnableTable":
+				return "Table"
+			}
+		}
+	}
+	return "NoSQL"
+}
+
+// inventoryThroughput returns a resource's own throughput settings, or nil when it has none. Resources using shared
+// database throughput return 404, and every resource of a serverless account returns 400.
+func inventoryThroughput(settings armcosmos.ThroughputSettingsGetResults, err error) (*armcosmos.ThroughputSettingsGetProperties, error) {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && (respErr.StatusCode == http.StatusNotFound || respErr.StatusCode == http.StatusBadRequest) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings.Properties, nil
+}
+
+func listSQLInventory(ctx context.Context, client *armcosmos.SQLResourcesClient, group string, account string) ([]inventoryResource, error) {
+	var resources []inventoryResource
+	databases := client.NewListSQLDatabasesPager(group, account, nil)
+	for databases.More() {
+		page, err := databases.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			resp, err := client.GetSQLDatabaseThroughput(ctx, group, account, *db.Name, nil)
+			throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+			if err != nil {
+				return resources, err
+			}
+			resources = append(resources, inventoryResource{kind: "Database", name: *db.Name, throughput: throughput})
+
+			containers := client.NewListSQLContainersPager(group, account, *db.Name, nil)
+			for containers.More() {
+				page, err := containers.NextPage(ctx)
+				if err != nil {
+					return resources, err
+				}
+				for _, c := range page.Value {
+					if c == nil || c.Name == nil {
+						continue
+					}
+					resp, err := client.GetSQLContainerThroughput(ctx, group, account, *db.Name, *c.Name, nil)
+					throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+					if err != nil {
+						return resources, err
+					}
+					resource := inventoryResource{kind: "Container", name: *db.Name + "/" + *c.Name, throughput: throughput}
+					if c.Properties != nil {
+						resource.container = c.Properties.Resource
+					}
+					resources = append(resources, resource)
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+func listMongoInventory(ctx context.Context, client *armcosmos.MongoDBResourcesClient, group string, account string) ([]inventoryResource, error) {
+	var resources []inventoryResource
+	databases := client.NewListMongoDBDatabasesPager(group, account, nil)
+	for databases.More() {
+		page, err := databases.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			resp, err := client.GetMongoDBDatabaseThroughput(ctx, group, account, *db.Name, nil)
+			throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+			if err != nil {
+				return resources, err
+			}
+			resources = append(resources, inventoryResource{kind: "Database", name: *db.Name, throughput: throughput})
+
+			collections := client.NewListMongoDBCollectionsPager(group, account, *db.Name, nil)
+			for collections.More() {
+				page, err := collections.NextPage(ctx)
+				if err != nil {
+					return resources, err
+				}
+				for _, c := range page.Value {
+					if c == nil || c.Name == nil {
+						continue
+					}
+					resp, err := client.GetMongoDBCollectionThroughput(ctx, group, account, *db.Name, *c.Name, nil)
+					throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+					if err != nil {
+						return resources, err
+					}
+					resources = append(resources, inventoryResource{kind: "Collection", name: *db.Name + "/" + *c.Name, throughput: throughput})
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+func listCassandraInventory(ctx context.Context, client *armcosmos.CassandraResourcesClient, group string, account string) ([]inventoryResource, error) {
+	var resources []inventoryResource
+	keyspaces := client.NewListCassandraKeyspacesPager(group, account, nil)
+	for keyspaces.More() {
+		page, err := keyspaces.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, ks := range page.Value {
+			if ks == nil || ks.Name == nil {
+				continue
+			}
+			resp, err := client.GetCassandraKeyspaceThroughput(ctx, group, account, *ks.Name, nil)
+			throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+			if err != nil {
+				return resources, err
+			}
+			resources = append(resources, inventoryResource{kind: "Keyspace", name: *ks.Name, throughput: throughput})
+
+			tables := client.NewListCassandraTablesPager(group, account, *ks.Name, nil)
+			for tables.More() {
+				page, err := tables.NextPage(ctx)
+				if err != nil {
+					return resources, err
+				}
+				for _, t := range page.Value {
+					if t == nil || t.Name == nil {
+						continue
+					}
+					resp, err := client.GetCassandraTableThroughput(ctx, group, account, *ks.Name, *t.Name, nil)
+					throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+					if err != nil {
+						return resources, err
+					}
+					resources = append(resources, inventoryResource{kind: "Table", name: *ks.Name + "/" + *t.Name, throughput: throughput})
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+func listGremlinInventory(ctx context.Context, client *armcosmos.GremlinResourcesClient, group string, account string) ([]inventoryResource, error) {
+	var resources []inventoryResource
+	databases := client.NewListGremlinDatabasesPager(group, account, nil)
+	for databases.More() {
+		page, err := databases.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			resp, err := client.GetGremlinDatabaseThroughput(ctx, group, account, *db.Name, nil)
+			throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+			if err != nil {
+				return resources, err
+			}
+			resources = append(resources, inventoryResource{kind: "Database", name: *db.Name, throughput: throughput})
+
+			graphs := client.NewListGremlinGraphsPager(group, account, *db.Name, nil)
+			for graphs.More() {
+				page, err := graphs.NextPage(ctx)
+				if err != nil {
+					return resources, err
+				}
+				for _, g := range page.Value {
+					if g == nil || g.Name == nil {
+						continue
+					}
+					resp, err := client.GetGremlinGraphThroughput(ctx, group, account, *db.Name, *g.Name, nil)
+					throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+					if err != nil {
+						return resources, err
+					}
+					resources = append(resources, inventoryResource{kind: "Graph", name: *db.Name + "/" + *g.Name, throughput: throughput})
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+func listTableInventory(ctx context.Context, client *armcosmos.TableResourcesClient, group string, account string) ([]inventoryResource, error) {
+	var resources []inventoryResource
+	tables := client.NewListTablesPager(group, account, nil)
+	for tables.More() {
+		page, err := tables.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+		for _, t := range page.Value {
+			if t == nil || t.Name == nil {
+				continue
+			}
+			resp, err := client.GetTableThroughput(ctx, group, account, *t.Name, nil)
+			throughput, err := inventoryThroughput(resp.ThroughputSettingsGetResults, err)
+			if err != nil {
+				return resources, err
+			}
+			resources = append(resources, inventoryResource{kind: "Table", name: *t.Name, throughput: throughput})
+		}
+	}
+	return resources, nil
+}
+
+// inventorySummaryTable has one row per account and the subscription total. RU/s are per region; the total across
+// regions is what is billed.
+func inventorySummaryTable(accounts []inventoryAccount) reportTable {
+	table := reportTable{headers: []string{"ACCOUNT", "RESOURCE GROUP", "API", "REGIONS", "RESOURCES", "MANUAL RU/S", "AUTOSCALE MAX RU/S", "TOTAL RU/S (ALL REGIONS)"}}
+	var manual, autoscale, total, failed int
+	for _, a := range accounts {
+		note := ""
+		if a.err != nil {
+			failed++
+			note = " (incomplete)"
+		}
+		if a.serverless {
+			table.addRow(a.name, a.resourceGroup, a.api, strconv.Itoa(a.regions), strconv.Itoa(len(a.resources)), "serverless", "serverless", "-"+note)
+			continue
+		}
+		table.addRow(a.name, a.resourceGroup, a.api, strconv.Itoa(a.regions), strconv.Itoa(len(a.resources)), strconv.Itoa(a.manualRU), strconv.Itoa(a.autoscaleRU), strconv.Itoa(a.totalRU()*a.regions)+note)
+		manual += a.manualRU * a.regions
+		autoscale += a.autoscaleRU * a.regions
+		total += a.totalRU() * a.regions
+	}
+	table.addRow("TOTAL", "", "", "", "", strconv.Itoa(manual), strconv.Itoa(autoscale), strconv.Itoa(total))
+	table.addNote("Manual and autoscale columns are per region; the TOTAL row counts every region.")
+	if failed > 0 {
+		table.addNote("%d account(s) are incomplete; their totals only count what could be read.", failed)
+	}
+	return table
+}
+
+// inventoryDetailsTable lists every resource of every account with its throughput.
+func inventoryDetailsTable(accounts []inventoryAccount) reportTable {
+	table := reportTable{headers: []string{"ACCOUNT", "TYPE", "NAME", "THROUGHPUT"}}
+	for _, a := range accounts {
+		for _, r := range a.resources {
+			throughput := describeThroughputSettings(r.throughput)
+			switch {
+			case a.serverless:
+				throughput = "serverless"
+			case throughput == "" && strings.Contains(r.name, "/"):
+				throughput = "shared"
+			}
+			table.addRow(a.name, r.kind, r.name, orDash(throughput))
+		}
+	}
+	return table
+}
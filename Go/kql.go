@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/query/azlogs"
+)
+
+// cannedQuery is a named KQL query over the account's resource-specific diagnostic tables. The query text has one %d
+// placeholder for the row limit.
+type cannedQuery struct {
+	name        string
+	description string
+	category    string
+	query       string
+}
+
+var cannedQueries = []cannedQuery{
+	{
+		name:        "top-ru-operations",
+		description: "Operations that consumed the most request units",
+		category:    "DataPlaneRequests",
+		query: `CDBDataPlaneRequests
+| summarize TotalRU = round(sum(RequestCharge), 2), Requests = count(), AvgRU = round(avg(RequestCharge), 2) by OperationName, DatabaseName, CollectionName
+| top %d by TotalRU desc`,
+	},
+	{
+		name:        "hot-partition-keys",
+		description: "Partition key values that consumed the most request units",
+		category:    "PartitionKeyRUConsumption",
+		query: `CDBPartitionKeyRUConsumption
+| summarize TotalRU = round(sum(todouble(RequestCharge)), 2) by DatabaseName, CollectionName, PartitionKey, PartitionKeyRangeId
+| top %d by TotalRU desc`,
+	},
+	{
+		name:        "throttled-operations",
+		description: "429 (throttled) responses by operation",
+		category:    "DataPlaneRequests",
+		query: `CDBDataPlaneRequests
+| summarize Throttled = countif(StatusCode == 429), Requests = count() by OperationName, DatabaseName, CollectionName
+| where Throttled > 0
+| extend ThrottledPercent = round(100.0 * Throttled / Requests, 2)
+| top %d by Throttled desc`,
+	},
+}
+
+// runKQLCommand runs a canned (or custom) KQL query against the logs the account's diagnostic setting sends to Log
+// Analytics and prints the result table.
+func runKQLCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("kql")
+	window := fs.Duration("window", 24*time.Hour, "How far back to query logs")
+	top := fs.Int("top", 10, "Maximum number of rows for canned queries")
+	custom := fs.String("query", "", "Run this KQL query instead of a canned one")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: kql [flags] <query name> | kql -query '<KQL>'")
+		fmt.Fprintln(fs.Output(), "Canned queries:")
+		for _, q := range cannedQueries {
+			fmt.Fprintf(fs.Output(), "  %-22s %s (needs the %s log category)\n", q.name, q.description, q.category)
+		}
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	query := *custom
+	hint := "is the diagnostic setting sending logs to Log Analytics?"
+	switch {
+	case query != "" && fs.NArg() == 0:
+	case query == "" && fs.NArg() == 1:
+		canned, ok := findCannedQuery(fs.Arg(0))
+		if !ok {
+			fmt.Fprintf(fs.Output(), "Unknown query %q.\n", fs.Arg(0))
+			fs.Usage()
+			os.Exit(2)
+		}
+		query = fmt.Sprintf(canned.query, *top)
+		hint = fmt.Sprintf("is %s in DiagnosticLogCategories?", canned.category)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	table, err := queryAccountLogs(ctx, query, *window)
+	if err != nil {
+		log.Fatalf("failed to run query (%s): %v", hint, err)
+	}
+	if len(table.Rows) == 0 {
+		fmt.Printf("No results in the last %s (%s Logs can take a few minutes to arrive.)\n", *window, hint)
+		return
+	}
+	printLogTable(table)
+}
+
+func findCannedQuery(name string) (cannedQuery, bool) {
+	for _, q := range cannedQueries {
+		if strings.EqualFold(q.name, name) {
+			return q, true
+		}
+	}
+	return cannedQuery{}, false
+}
+
+// printLogTable prints every column of a logs query result.
+func printLogTable(table *azlogs.Table) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		headers = append(headers, strings.ToUpper(stringValue(column.Name)))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(table.Columns))
+		for i := range table.Columns {
+			cells = append(cells, logCell(row, i))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// latencyRow is the latency of one region (or region pair) over the query window.
+type latencyRow struct {
+	metric string
+	region string
+	avg    float64
+	max    float64
+	warn   bool
+}
+
+// runLatencyCommand prints server-side latency per region and replication latency per region pair from Azure Monitor,
+// warning where the average exceeds a threshold.
+func runLatencyCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("latency")
+	window := fs.Duration("window", time.Hour, "How far back to query latency metrics")
+	interval := fs.Duration("interval", 5*time.Minute, "Metric time grain (1m, 5m, 15m, 30m, 1h, 6h, 12h, 24h)")
+	serverThreshold := fs.Duration("server-threshold", 10*time.Millisecond, "Warn when a region's average server-side latency exceeds this")
+	replicationThreshold := fs.Duration("replication-threshold", 100*time.Millisecond, "Warn when a region pair's average replication latency exceeds this")
+	_ = fs.Parse(args)
+
+	rows := make([]latencyRow, 0)
+	serverRows, err := latencyByDimension(ctx, "ServerSideLatency", []string{"Region"}, *window, *interval, *serverThreshold)
+	if err != nil {
+		log.Fatalf("failed to query server-side latency: %v", err)
+	}
+	rows = append(rows, serverRows...)
+
+	// Replication latency is only emitted for accounts with more than one region.
+	replicationRows, err := latencyByDimension(ctx, "ReplicationLatency", []string{"SourceRegion", "TargetRegion"}, *window, *interval, *replicationThreshold)
+	if err != nil {
+		log.Printf("Could not query replication latency: %v", err)
+	}
+	rows = append(rows, replicationRows...)
+
+	if len(rows) == 0 {
+		fmt.Printf("No latency metrics were returned for the last %s.\n", *window)
+		return
+	}
+
+	fmt.Printf("Latency over the last %s (time grain %s)\n", *window, *interval)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tREGION\tAVG MS\tMAX MS\tSTATUS")
+	warnings := make([]string, 0)
+	for _, r := range rows {
+		status := "ok"
+		if r.warn {
+			status = "WARN"
+			threshold := *serverThreshold
+			if r.metric == "ReplicationLatency" {
+				threshold = *replicationThreshold
+			}
+			warnings = append(warnings, fmt.Sprintf("%s in %s averaged %.1f ms (threshold %s)", r.metric, r.region, r.avg, threshold))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.1f\t%.1f\t%s\n", r.metric, r.region, r.avg, r.max, status)
+	}
+	_ = tw.Flush()
+
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+}
+
+// latencyByDimension reads a latency metric (in milliseconds) split by the given dimensions and returns one row per
+// combination, flagged when its average exceeds threshold.
+func latencyByDimension(ctx context.Context, metricName string, dimensions []string, window time.Duration, interval time.Duration, threshold time.Duration) ([]latencyRow, error) {
+	filters := make([]string, 0, len(dimensions))
+	for _, d := range dimensions {
+		filters = append(filters, d+" eq '*'")
+	}
+	metric, err := queryAccountMetric(ctx, metricQuery{name: metricName, aggregation: "Average"}, strings.Join(filters, " and "), window, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdMS := float64(threshold) / float64(time.Millisecond)
+	rows := make([]latencyRow, 0, len(metric.Timeseries))
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		values := make([]string, 0, len(dimensions))
+		for _, d := range dimensions {
+			values = append(values, orDash(seriesDimension(series, d)))
+		}
+
+		row := latencyRow{metric: metricName, region: strings.Join(values, " -> ")}
+		points := 0
+		for _, point := range series.Data {
+			value, ok := metricValue(point, "Average")
+			if !ok {
+				continue
+			}
+			points++
+			row.avg += value
+			if value > row.max {
+				row.max = value
+			}
+		}
+		if points == 0 {
+			continue
+		}
+		row.avg /= float64(points)
+		row.warn = row.avg > thresholdMS
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].region < rows[j].region })
+	return rows, nil
+}
+
+// seriesDimension returns the value of a dimension of a metric time series, or "" if it is absent.
+func seriesDimension(series *armmonitor.TimeSeriesElement, name string) string {
+	for _, md := range series.Metadatavalues {
+		if md != nil && md.Name != nil && md.Name.Value != nil && strings.EqualFold(*md.Name.Value, name) && md.Value != nil {
+			return *md.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// Changing DisableLocalAuth takes a few minutes to reach every gateway of the data plane.
+const localAuthPropagationTimeout = 5 * time.Minute
+
+// runHardenLocalAuthCommand disables key-based (local) auth on the account and then proves it: a request signed with
+// an account key must be rejected while the same request with Entra ID still succeeds. -revert re-enables keys and
+// proves they work again.
+func runHardenLocalAuthCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("harden-local-auth")
+	revert := fs.Bool("revert", false, "Re-enable key-based auth instead, and verify keys work again")
+	yes := fs.Bool("yes", false, "Change DisableLocalAuth; without it, only verify the current setting")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: harden-local-auth [-revert] [-yes]")
+		fmt.Fprintf(fs.Output(), "Sets DisableLocalAuth on %s and verifies that key-based data-plane requests are rejected (or, with -revert, accepted).\n", accountName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	disable := !*revert
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	if api := accountAPI(account.DatabaseAccountGetResults); api != "NoSQL" {
+		log.Fatalf("harden-local-auth verifies with the NoSQL data plane; %s is a %s account", accountName, api)
+	}
+	p := account.Properties
+	if p == nil || p.DocumentEndpoint == nil {
+		log.Fatalf("Account %s did not return a document endpoint", accountName)
+	}
+	current := p.DisableLocalAuth != nil && *p.DisableLocalAuth
+	fmt.Printf("DisableLocalAuth on %s: %t\n", accountName, current)
+
+	switch {
+	case current == disable:
+	case !*yes:
+		fmt.Printf("Would set DisableLocalAuth to %t. Run with -yes to change it; verifying the current setting only.\n", disable)
+		disable = current
+	case *createOnly:
+		fmt.Println("Skipping the account update (-create-only never changes existing resources); verifying the current setting only.")
+		disable = current
+	default:
+		fmt.Printf("Setting DisableLocalAuth to %t...\n", disable)
+		params := armcosmos.DatabaseAccountUpdateParameters{Properties: &armcosmos.DatabaseAccountUpdateProperties{DisableLocalAuth: to.Ptr(disable)}}
+		poller, err := accountClient.BeginUpdate(ctx, resourceGroupName, accountName, params, nil)
+		if err != nil {
+			log.Fatalf("failed to update the account: %v", err)
+		}
+		if _, err := pollUntilDone(ctx, poller); err != nil {
+			log.Fatalf("failed to update the account: %v", err)
+		}
+	}
+
+	keys, err := accountClient.ListReadOnlyKeys(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to list the account's read-only keys: %v", err)
+	}
+	if keys.PrimaryReadonlyMasterKey == nil {
+		log.Fatalf("Account %s returned no read-only key", accountName)
+	}
+	keyCredential, err := azcosmos.NewKeyCredential(*keys.PrimaryReadonlyMasterKey)
+	if err != nil {
+		log.Fatalf("failed to create key credential: %v", err)
+	}
+	keyClient, err := azcosmos.NewClientWithKey(*p.DocumentEndpoint, keyCredential, nil)
+	if err != nil {
+		log.Fatalf("failed to create cosmos client: %v", err)
+	}
+	keyContainer, err := keyClient.NewContainer(databaseName, containerName)
+	if err != nil {
+		log.Fatalf("failed to create cosmos container client: %v", err)
+	}
+	entraContainer, err := getDataPlaneContainerClient(ctx)
+	if err != nil {
+		log.Fatalf("failed to create cosmos db data-plane client: %v", err)
+	}
+
+	// The key-based request is retried until it gives the expected answer, since the change propagates gradually.
+	keyResult, keyAccepted, err := tryLocalAuthRead(ctx, keyContainer)
+	deadline := time.Now().Add(localAuthPropagationTimeout)
+	for err == nil && keyAccepted == disable && time.Now().Before(deadline) {
+		log.Printf("Key-based read was %s; waiting for DisableLocalAuth=%t to propagate...", keyResult, disable)
+		time.Sleep(dataPlaneRBACRetryInterval)
+		keyResult, keyAccepted, err = tryLocalAuthRead(ctx, keyContainer)
+	}
+	if err != nil {
+		log.Fatalf("key-based read failed unexpectedly: %v", err)
+	}
+	entraResult, entraAccepted, err := tryLocalAuthRead(ctx, entraContainer)
+	if err != nil {
+		log.Fatalf("Entra ID read failed unexpectedly: %v", err)
+	}
+
+	keyExpected := "accepted"
+	if disable {
+		keyExpected = "rejected"
+	}
+	passed := keyAccepted != disable && entraAccepted
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tEXPECTED\tRESULT\tOK")
+	fmt.Fprintf(tw, "Read %s/%s with the read-only account key\t%s\t%s\t%t\n", databaseName, containerName, keyExpected, keyResult, keyAccepted != disable)
+	fmt.Fprintf(tw, "Read %s/%s with Entra ID\taccepted\t%s\t%t\n", databaseName, containerName, entraResult, entraAccepted)
+	_ = tw.Flush()
+
+	if !passed {
+		fmt.Printf("Local auth enforcement on %s does not match DisableLocalAuth=%t.\n", accountName, disable)
+		if !entraAccepted {
+			fmt.Println("The Entra ID read needs a SQL role assignment for the signed-in identity, such as the one the full run creates.")
+		}
+		os.Exit(1)
+	}
+	if disable {
+		fmt.Printf("Verified: %s rejects account keys and accepts Entra ID.\n", accountName)
+	} else {
+		fmt.Printf("Verified: %s accepts account keys and Entra ID.\n", accountName)
+	}
+}
+
+// tryLocalAuthRead reads the container's properties and reports whether the request was accepted, or rejected as
+// unauthorized (401 or 403). Any other failure is returned as an error.
+func tryLocalAuthRead(ctx context.Context, container *azcosmos.ContainerClient) (string, bool, error) {
+	_, err := container.Read(ctx, nil)
+	if err == nil {
+		return "accepted", true, nil
+	}
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && (respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden) {
+		return fmt.Sprintf("rejected (HTTP %d)", respErr.StatusCode), false, nil
+	}
+	return "", false, err
+}
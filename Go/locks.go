@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+)
+
+const accountLockName = "cosmos-sample-do-not-delete"
+
+// createOrUpdateAccountLock places a CanNotDelete management lock on the Cosmos DB account.
+func createOrUpdateAccountLock(ctx context.Context) {
+	locksClient, err := armlocks.NewManagementLocksClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create management locks client: %v", err)
+	}
+
+	properties := armlocks.ManagementLockObject{
+		Properties: &armlocks.ManagementLockProperties{
+			Level: to.Ptr(armlocks.LockLevelCanNotDelete),
+			Notes: to.Ptr("Created by the Cosmos DB management sample to protect the account from accidental deletion."),
+		},
+	}
+
+	resp, err := locksClient.CreateOrUpdateByScope(ctx, getAssignableScope(Account), accountLockName, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to create or update management lock: %v", err)
+	}
+
+	recordResource("Microsoft.Authorization/locks", resp.ID)
+	fmt.Printf("Created/updated Management Lock: %s\n", *resp.ID)
+}
+
+// deleteAccountLock removes the sample's management lock from the Cosmos DB account, if present.
+func deleteAccountLock(ctx context.Context) {
+	locksClient, err := armlocks.NewManagementLocksClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create management locks client: %v", err)
+	}
+
+	if _, err := locksClient.DeleteByScope(ctx, getAssignableScope(Account), accountLockName, nil); err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == 404 {
+			return
+		}
+		log.Fatalf("failed to delete management lock: %v", err)
+	}
+
+	fmt.Printf("Deleted Management Lock: %s\n", accountLockName)
+}
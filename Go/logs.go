@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/monitor/query/azlogs"
+)
+
+// queryAccountLogs runs a KQL query against the logs collected for the Cosmos DB account.
+// The query is resource-centric, so it searches whichever workspace the account's diagnostic settings write to.
+func queryAccountLogs(ctx context.Context, query string, window time.Duration) (*azlogs.Table, error) {
+	logsClient, err := azlogs.NewClient(credential, &azlogs.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: azureCloud}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logs query client: %w", err)
+	}
+
+	end := time.Now().UTC()
+	timespan := azlogs.NewTimeInterval(end.Add(-window), end)
+	resp, err := logsClient.QueryResource(ctx, getAssignableScope(Account), azlogs.QueryBody{Query: to.Ptr(query), Timespan: to.Ptr(timespan)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run logs query: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("logs query returned an error: %s", resp.Error.Error())
+	}
+	if len(resp.Tables) == 0 {
+		return &azlogs.Table{}, nil
+	}
+
+	return &resp.Tables[0], nil
+}
+
+// logColumnIndex returns the index of a named column in a logs query result table, or -1 if it is absent.
+func logColumnIndex(table *azlogs.Table, name string) int {
+	for i, column := range table.Columns {
+		if column.Name != nil && strings.EqualFold(*column.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// logCell formats a single cell of a logs query result row for display.
+func logCell(row azlogs.Row, index int) string {
+	if index < 0 || index >= len(row) || row[index] == nil {
+		return ""
+	}
+	return fmt.Sprint(row[index])
+}
+
+// kqlString quotes a value for use as a KQL string literal.
+func kqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "\\'") + "'"
+}
@@ -6,20 +6,22 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/google/uuid"
@@ -35,22 +37,80 @@ var (
 	containerName          string
 	maxAutoScaleThroughput int
 	credential             *azidentity.DefaultAzureCredential
+
+	// Optional settings
+	logAnalyticsWorkspaceName string
+	alertEmailAddress         string
+	throttleAlertThreshold    int
+	lockAccount               bool
+	createResourceGroup       bool
+	fleetName                 string
+	fleetspaceName            string
+	verifyDataPlane           bool
+	useEmulator               bool
+	emulatorEndpoint          string
+	pollFrequency             time.Duration
+	operationTimeout          time.Duration
+	accountNamePrefix         string
+	databases                 []databaseConfig
+	indexingPolicyPath        string
+	consistencyLevel          armcosmos.DefaultConsistencyLevel
+	maxStalenessPrefix        int64
+	maxIntervalInSeconds      int64
+	regions                   []regionConfig
+	diagnosticCategories      []string
+	diagnosticRetentionInDays int
+	keyVaultKeyURI            string
+	defaultIdentity           string
+	accountKind               armcosmos.DatabaseAccountKind
+	mongoServerVersion        armcosmos.ServerVersion
+)
+
+// Command-line flags (before the command name, for example `go run . -seed 1000`)
+var (
+	seedCount      = flag.Int("seed", 0, "Insert this many synthetic documents into the container during the full run")
+	outputFormat   = flag.String("output", "text", "Output format: text, or json for a machine-readable summary of the full run")
+	outputFile     = flag.String("output-file", "", "Write the -output json summary to this file instead of stdout")
+	runIDFlag      = flag.String("run-id", "", "Tag created resources with this run ID (default: generated from the start time)")
+	allowScaleDown = flag.Bool("allow-scale-down", false, "Allow throughput updates that lower the current autoscale max or manual RU/s")
+	createOnly     = flag.Bool("create-only", false, "Only create missing resources; never update an existing account, database, container, or throughput")
+	debugHTTP      = flag.Bool("debug-http", false, "Log SDK HTTP requests and responses, retries, and long-running operation polling (secrets redacted)")
+	profileName    = flag.String("profile", "", "Apply this entry of the Profiles setting over config.json, for example dev or prod")
+	watchAccount   = flag.Bool("watch", false, "After creating or updating the account, poll it until every region reports Succeeded, printing per-region transitions")
 )
 
 // main is the entry point for the Cosmos DB management sample.
 func main() {
+	flag.Usage = printUsage
+	flag.Parse()
+	configureOutput()
+	configureHTTPLogging()
+	initializeRunID()
+
 	loadConfiguration()
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+	spanName := "cosmos-sample"
+	if args := flag.Args(); len(args) > 0 {
+		spanName += " " + args[0]
 	}
-	credential = cred
+	ctx := startRunTracing(context.Background(), spanName)
+	defer stopRunTracing()
 
-	ctx := context.Background()
+	if args := flag.Args(); len(args) > 0 {
+		if accountName == "" && !useEmulator {
+			log.Fatalf("AccountName is required to run commands; AccountNamePrefix only applies to the full run and the interactive menu.")
+		}
+		runCommand(ctx, args[0], args[1:])
+		return
+	}
+
+	if accountName == "" && !useEmulator {
+		generateUniqueAccountName(ctx)
+	}
 
 	// If we're not running in an interactive terminal (e.g., CI), fall back to the full sample.
-	if !isInteractiveTerminal() {
+	// The menu's steps are management-plane operations, so emulator mode always runs the full (data-plane) sample.
+	if !isInteractiveTerminal() || useEmulator {
 		runFullSample(ctx)
 		return
 	}
@@ -60,20 +120,51 @@ func main() {
 
 // runFullSample runs the end-to-end management-plane workflow with sensible defaults.
 func runFullSample(ctx context.Context) {
+	defer writeRunSummary()
+
+	if useEmulator {
+		runEmulatorSample(ctx)
+		return
+	}
+
 	initializeSubscription(ctx)
+	runPreflightChecks(ctx)
 
 	createOrUpdateCosmosDBAccount(ctx)
+	if lockAccount {
+		createOrUpdateAccountLock(ctx)
+	}
+	if budgetAmount > 0 {
+		createOrUpdateResourceGroupBudget(ctx)
+	}
+	createOrUpdateDiagnosticSettings(ctx)
 	createOrUpdateAzureRoleAssignment(ctx)
-	createOrUpdateCosmosDBDatabase(ctx)
-	createOrUpdateCosmosDBContainer(ctx)
-	updateThroughput(ctx, 1000)
-
-	// Cosmos DB SQL RBAC (built-in data contributor)
-	builtInRoleDefinitionID, err := getBuiltInDataContributorRoleDefinition(ctx)
-	if err != nil {
-		log.Fatalf("failed to get built-in data contributor role definition: %v", err)
+	if isMongoAccount() {
+		// The database, container, SQL RBAC, and data-plane steps use the NoSQL (SQL) APIs.
+		fmt.Println("Kind is MongoDB; skipping the NoSQL database, container, throughput, and SQL RBAC steps.")
+		createOrUpdateThrottlingAlert(ctx)
+	} else {
+		createOrUpdateCosmosDBDatabase(ctx)
+		printConfiguredThroughputCostBestEffort(ctx)
+		createOrUpdateCosmosDBContainer(ctx)
+		createOrUpdateThrottlingAlert(ctx)
+		updateThroughput(ctx, 1000)
+
+		// Cosmos DB SQL RBAC (built-in data contributor)
+		builtInRoleDefinitionID, err := getBuiltInDataContributorRoleDefinition(ctx)
+		if err != nil {
+			log.Fatalf("failed to get built-in data contributor role definition: %v", err)
+		}
+		createOrUpdateRoleAssignment(ctx, builtInRoleDefinitionID)
+		if verifyDataPlane {
+			verifyDataPlaneAccess(ctx)
+		}
+		if *seedCount > 0 {
+			seedSampleDocuments(ctx, *seedCount)
+		}
 	}
-	createOrUpdateRoleAssignment(ctx, builtInRoleDefinitionID)
+
+	printAdvisorRecommendationsBestEffort(ctx)
 
 	// Optional cleanup: set COSMOS_SAMPLE_DELETE_ACCOUNT=true to delete the account at the end of a full run.
 	if strings.EqualFold(os.Getenv("COSMOS_SAMPLE_DELETE_ACCOUNT"), "true") {
@@ -96,6 +187,13 @@ func runInteractiveMenu(ctx context.Context) {
 		fmt.Println("  6) Update container throughput (+delta)")
 		fmt.Println("  7) Create Cosmos NoSQL RBAC assignment (Built-in Data Contributor)")
 		fmt.Println("  8) Delete Cosmos DB account")
+		fmt.Println("  9) Create/update diagnostic settings (Log Analytics)")
+		fmt.Println(" 10) Create/update 429 throttling alert")
+		fmt.Println(" 11) Lock Cosmos DB account (CanNotDelete)")
+		fmt.Println(" 12) Remove Cosmos DB account lock")
+		fmt.Println(" 13) Verify data-plane access (write/read a test item)")
+		fmt.Println(" 14) Create/update resource group budget")
+		fmt.Println("  c) Run a command (for example: metrics -window 24h)")
 		fmt.Println("  0) Exit")
 		fmt.Print("Selection: ")
 
@@ -147,6 +245,30 @@ func runInteractiveMenu(ctx context.Context) {
 				} else {
 					fmt.Println("Delete cancelled.")
 				}
+			case "9":
+				createOrUpdateDiagnosticSettings(ctx)
+			case "10":
+				createOrUpdateThrottlingAlert(ctx)
+			case "11":
+				createOrUpdateAccountLock(ctx)
+			case "12":
+				deleteAccountLock(ctx)
+			case "13":
+				verifyDataPlaneAccess(ctx)
+			case "14":
+				createOrUpdateResourceGroupBudget(ctx)
+			case "c":
+				fmt.Print("Command: ")
+				raw, err := readLine(reader)
+				if err != nil {
+					log.Printf("Failed to read command: %v", err)
+					return
+				}
+				fields := strings.Fields(raw)
+				if len(fields) == 0 {
+					return
+				}
+				runCommand(ctx, fields[0], fields[1:])
 			default:
 				fmt.Println("Unknown selection.")
 			}
@@ -207,9 +329,150 @@ func loadConfiguration() {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("config")
 	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Missing configuration. Copy Go/config.json.sample to Go/config.json and fill it in. Original error: %v", err)
+		// Without a config.json, first-time users at a terminal are prompted for the required settings below.
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) || !isInteractiveTerminal() {
+			log.Fatalf("Missing configuration. Copy Go/config.json.sample to Go/config.json and fill it in. Original error: %v", err)
+		}
+	}
+	if err := applyProfiles(); err != nil {
+		log.Fatalf("Invalid profile: %v", err)
+	}
+
+	cloudConfiguration, err := parseAzureCloud(viper.GetString("Cloud"))
+	if err != nil {
+		log.Fatalf("Invalid Cloud setting: %v", err)
+	}
+	azureCloud = cloudConfiguration
+	// The credential depends on the cloud, and Key Vault references are read with it.
+	cred, err := azidentity.NewDefaultAzureCredential(credentialOptions())
+	if err != nil {
+		log.Fatalf("failed to obtain a credential: %v", err)
+	}
+	credential = cred
+	if err := resolveKeyVaultReferences(context.Background()); err != nil {
+		log.Fatalf("Invalid Key Vault reference: %v", err)
+	}
+
+	useEmulator = viper.GetBool("UseEmulator")
+	emulatorEndpoint = strings.TrimSpace(viper.GetString("EmulatorEndpoint"))
+	if emulatorEndpoint == "" {
+		emulatorEndpoint = defaultEmulatorEndpoint
+	}
+
+	missing := missingRequiredSettings()
+	if len(missing) > 0 && isInteractiveTerminal() {
+		if err := promptForMissingSettings(missing); err != nil {
+			log.Fatalf("%v", err)
+		}
+		missing = missingRequiredSettings()
+	}
+	if len(missing) > 0 {
+		log.Fatalf("Missing required configuration values: %s. Copy Go/config.json.sample to Go/config.json and fill it in.", strings.Join(missing, ", "))
+	}
+
+	maxAutoScaleThroughput = viper.GetInt("MaxAutoScaleThroughput")
+	if err := validateMaxAutoScaleThroughput(maxAutoScaleThroughput); err != nil {
+		log.Fatalf("%v", err)
+	}
+	indexingPolicyPath = strings.TrimSpace(viper.GetString("IndexingPolicyPath"))
+	if err := loadDatabaseConfigs(); err != nil {
+		log.Fatalf("Invalid database or container settings: %v", err)
+	}
+
+	if err := loadNamingConvention(); err != nil {
+		log.Fatalf("Invalid naming convention: %v", err)
+	}
+	if accountName == "" && accountNamePrefix != "" {
+		if err := validateAccountNamePrefix(accountNamePrefix); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	if err := validateResourceNames(); err != nil {
+		log.Fatalf("Names don't follow NamingConvention:\n%v", err)
+	}
+
+	logAnalyticsWorkspaceName = strings.TrimSpace(viper.GetString("LogAnalyticsWorkspaceName"))
+	if err := loadDiagnosticSettings(); err != nil {
+		log.Fatalf("Invalid diagnostic settings: %v", err)
+	}
+
+	alertEmailAddress = strings.TrimSpace(viper.GetString("AlertEmailAddress"))
+	viper.SetDefault("ThrottleAlertThreshold", 100)
+	throttleAlertThreshold = viper.GetInt("ThrottleAlertThreshold")
+	if throttleAlertThreshold < 1 {
+		log.Fatalf("ThrottleAlertThreshold must be >= 1 (got %d)", throttleAlertThreshold)
+	}
+
+	if err := loadTagSettings(); err != nil {
+		log.Fatalf("Invalid tag settings: %v", err)
+	}
+
+	if err := loadBudgetSettings(); err != nil {
+		log.Fatalf("Invalid budget settings: %v", err)
+	}
+
+	viper.SetDefault("LockAccount", true)
+	lockAccount = viper.GetBool("LockAccount")
+
+	createResourceGroup = viper.GetBool("CreateResourceGroup")
+
+	fleetName = strings.TrimSpace(viper.GetString("FleetName"))
+	fleetspaceName = strings.TrimSpace(viper.GetString("FleetspaceName"))
+	if fleetspaceName == "" {
+		fleetspaceName = "throughput-pool"
+	}
+	if err := loadExporterSettings(); err != nil {
+		log.Fatalf("Invalid exporter settings: %v", err)
+	}
+
+	verifyDataPlane = viper.GetBool("VerifyDataPlane")
+
+	if err := loadAccountKindSettings(); err != nil {
+		log.Fatalf("Invalid account kind settings: %v", err)
+	}
+
+	keyVaultKeyURI = strings.TrimRight(strings.TrimSpace(viper.GetString("KeyVaultKeyUri")), "/")
+	defaultIdentity = strings.TrimSpace(viper.GetString("DefaultIdentity"))
+	if err := validateCMKSettings(); err != nil {
+		log.Fatalf("Invalid customer-managed key settings: %v", err)
+	}
+
+	if err := loadConsistencySettings(); err != nil {
+		log.Fatalf("Invalid consistency settings: %v", err)
+	}
+	if err := loadRegionConfigs(); err != nil {
+		log.Fatalf("Invalid Regions setting: %v", err)
+	}
+	if err := validateConsistencySettings(len(regions)); err != nil {
+		log.Fatalf("Invalid consistency settings: %v", err)
+	}
+
+	// Zero keeps the SDK's default polling interval (the service's Retry-After, or 30s).
+	pollFrequency = viper.GetDuration("PollFrequency")
+	viper.SetDefault("OperationTimeout", "30m")
+	operationTimeout = viper.GetDuration("OperationTimeout")
+	if err := validatePollingSettings(); err != nil {
+		log.Fatalf("Invalid polling settings: %v", err)
+	}
+	if err := loadTracingSettings(); err != nil {
+		log.Fatalf("Invalid tracing settings: %v", err)
+	}
+	if err := loadThroughputScheduleSettings(); err != nil {
+		log.Fatalf("Invalid throughput schedule: %v", err)
+	}
+	if err := loadThroughputBudgetSettings(); err != nil {
+		log.Fatalf("Invalid throughput budget: %v", err)
 	}
 
+	// With AccountNamePrefix, the name (and these defaults) are set once it has been generated.
+	if accountName != "" {
+		applyAccountNameDefaults()
+	}
+}
+
+// missingRequiredSettings reads the required settings into their globals and returns the keys that are not set.
+func missingRequiredSettings() []string {
 	subscriptionID = strings.TrimSpace(viper.GetString("SubscriptionId"))
 	resourceGroupName = strings.TrimSpace(viper.GetString("ResourceGroupName"))
 	accountName = strings.TrimSpace(viper.GetString("AccountName"))
@@ -218,39 +481,45 @@ func loadConfiguration() {
 	containerName = strings.TrimSpace(viper.GetString("ContainerName"))
 
 	missing := make([]string, 0, 7)
-	if subscriptionID == "" {
+	// The emulator has no subscription or account, so only the database and container settings are required.
+	if subscriptionID == "" && !useEmulator {
 		missing = append(missing, "SubscriptionId")
 	}
-	if resourceGroupName == "" {
+	if resourceGroupName == "" && !useEmulator {
 		missing = append(missing, "ResourceGroupName")
 	}
-	if accountName == "" {
+	accountNamePrefix = strings.TrimSpace(viper.GetString("AccountNamePrefix"))
+	// With NamingConvention.AccountPrefix, the account name can be generated from the convention.
+	if accountName == "" && accountNamePrefix == "" && viper.GetString("NamingConvention.AccountPrefix") == "" && !useEmulator {
 		missing = append(missing, "AccountName")
 	}
-	if location == "" {
+	if location == "" && !useEmulator {
 		missing = append(missing, "Location")
 	}
-	if databaseName == "" {
+	if databaseName == "" && !viper.IsSet("Databases") {
 		missing = append(missing, "DatabaseName")
 	}
-	if containerName == "" {
+	if containerName == "" && !viper.IsSet("Containers") && !viper.IsSet("Databases") {
 		missing = append(missing, "ContainerName")
 	}
 	if !viper.IsSet("MaxAutoScaleThroughput") {
 		missing = append(missing, "MaxAutoScaleThroughput")
 	}
-	if len(missing) > 0 {
-		log.Fatalf("Missing required configuration values: %s. Copy Go/config.json.sample to Go/config.json and fill it in.", strings.Join(missing, ", "))
-	}
+	return missing
+}
 
-	maxAutoScaleThroughput = viper.GetInt("MaxAutoScaleThroughput")
-	if maxAutoScaleThroughput < 1000 {
-		log.Fatalf("MaxAutoScaleThroughput must be >= 1000 (got %d)", maxAutoScaleThroughput)
+// applyAccountNameDefaults fills in the optional resource names that default to names derived from AccountName.
+func applyAccountNameDefaults() {
+	if logAnalyticsWorkspaceName == "" {
+		logAnalyticsWorkspaceName = accountName + "-logs"
+	}
+	if fleetName == "" {
+		fleetName = accountName + "-fleet"
 	}
 }
 
 func initializeSubscription(ctx context.Context) {
-	subscriptionClient, err := armsubscriptions.NewClient(credential, nil)
+	subscriptionClient, err := armsubscriptions.NewClient(credential, armClientOptions())
 	if err != nil {
 		log.Fatalf("failed to create subscription client: %v", err)
 	}
@@ -266,287 +535,157 @@ func initializeSubscription(ctx context.Context) {
 func createOrUpdateCosmosDBAccount(ctx context.Context) {
 	log.Printf("Starting Cosmos DB account create/update (this can take a couple minutes): account=%s", accountName)
 
-	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, nil)
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
 		log.Fatalf("failed to create cosmos db account client: %v", err)
 	}
 
+	if *createOnly {
+		existing, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+		if err == nil {
+			fmt.Printf("Account %s already exists; leaving it unchanged (-create-only).\n", accountName)
+			printAccountEndpoints(ctx, existing.DatabaseAccountGetResults)
+			printRestoreInfo(ctx, existing.DatabaseAccountGetResults)
+			return
+		}
+		var respErr *azcore.ResponseError
+		if !errors.As(err, &respErr) || respErr.StatusCode != 404 {
+			log.Fatalf("failed to get cosmos db account: %v", err)
+		}
+	}
+
 	properties := armcosmos.DatabaseAccountCreateUpdateParameters{
 		Location: &location,
-		Tags: map[string]*string{
-			"owner": to.StringPtr(getCurrentUserEmailBestEffort(ctx)),
-		},
+		Tags:     sampleTags(ctx),
 		Properties: &armcosmos.DatabaseAccountCreateUpdateProperties{
-			Locations: []*armcosmos.Location{{
-				LocationName:     &location,
-				FailoverPriority: to.Int32Ptr(0),
-				IsZoneRedundant:  to.BoolPtr(false),
-			}},
-			Capabilities: []*armcosmos.Capability{{
-				Name: to.StringPtr("EnableNoSQLVectorSearch"),
-			}},
-			// Uncomment to experiment with serverless.
-			// Capabilities: append(capabilities, &armcosmos.Capability{Name: to.StringPtr("EnableServerless")}),
-			DatabaseAccountOfferType: to.StringPtr("Standard"),
-			DisableLocalAuth:         to.BoolPtr(true),
-			PublicNetworkAccess:      to.PublicNetworkAccessPtr(armcosmos.PublicNetworkAccessEnabled),
+			Locations:                accountLocations(),
+			DatabaseAccountOfferType: to.Ptr("Standard"),
+			PublicNetworkAccess:      to.Ptr(armcosmos.PublicNetworkAccessEnabled),
+			ConsistencyPolicy:        accountConsistencyPolicy(),
 		},
 	}
+	// Sets the kind, capabilities, and DisableLocalAuth. To experiment with serverless, append
+	// &armcosmos.Capability{Name: to.Ptr("EnableServerless")} to properties.Properties.Capabilities after this call.
+	applyAccountKind(&properties)
 
-	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, nil)
+	locationsClient, err := armcosmos.NewLocationsClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
-		log.Fatalf("failed to create resource group client: %v", err)
+		log.Fatalf("failed to create cosmos db locations client: %v", err)
 	}
-	if _, err := resourceGroupClient.Get(ctx, resourceGroupName, nil); err != nil {
-		log.Fatalf("failed to get resource group: %v", err)
+	if problems := checkZoneRedundancySupport(ctx, locationsClient); len(problems) > 0 {
+		log.Fatalf("Invalid Regions setting: %v", errors.Join(problems...))
+	}
+	if keyVaultKeyURI != "" {
+		applyCMKSettings(&properties)
+		if problems := checkCMKPrerequisites(ctx); len(problems) > 0 {
+			log.Fatalf("Customer-managed key prerequisites are missing:\n%v", errors.Join(problems...))
+		}
 	}
 
-	pollerResp, err := accountClient.BeginCreateOrUpdate(ctx, resourceGroupName, accountName, properties, nil)
+	ensureResourceGroup(ctx)
+
+	requestCtx := ctx
+	if *createOnly {
+		requestCtx = withIfNoneMatch(ctx)
+	}
+	pollerResp, err := accountClient.BeginCreateOrUpdate(requestCtx, resourceGroupName, accountName, properties, nil)
 	if err != nil {
-		log.Fatalf("failed to begin create or update cosmos db account: %v", err)
+		log.Fatalf("failed to begin create or update cosmos db account: %v", describeConcurrencyError(err, "Account "+accountName))
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := pollUntilDone(ctx, pollerResp)
 	if err != nil {
 		log.Fatalf("failed to poll the result: %v", err)
 	}
+	recordResource("Microsoft.DocumentDB/databaseAccounts", resp.ID)
 	if resp.ID != nil {
 		fmt.Printf("Created/updated Account: %s\n", *resp.ID)
-		return
-	}
-	fmt.Println("Created/updated Account.")
-}
-
-func deleteCosmosDBAccount(ctx context.Context) {
-	log.Printf("Starting Cosmos DB account delete (this can take a couple minutes): account=%s", accountName)
-
-	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, nil)
-	if err != nil {
-		log.Fatalf("failed to create cosmos db account client: %v", err)
-	}
-
-	pollerResp, err := accountClient.BeginDelete(ctx, resourceGroupName, accountName, nil)
-	if err != nil {
-		log.Fatalf("failed to begin delete cosmos db account: %v", err)
+	} else {
+		fmt.Println("Created/updated Account.")
 	}
-
-	_, err = pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		log.Fatalf("failed to delete cosmos db account: %v", err)
+	account := resp.DatabaseAccountGetResults
+	if *watchAccount {
+		account, err = watchAccountUntilSteady(ctx)
+		if err != nil {
+			log.Fatalf("failed to watch account: %v", err)
+		}
 	}
-
-	fmt.Printf("Deleted Cosmos DB account: %s\n", accountName)
+	printAccountEndpoints(ctx, account)
+	printRestoreInfo(ctx, account)
 }
 
-// createOrUpdateCosmosDBDatabase creates or updates a SQL database.
-func createOrUpdateCosmosDBDatabase(ctx context.Context) {
-	databaseClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
+// ensureResourceGroup verifies the resource group exists, creating it when CreateResourceGroup is enabled.
+func ensureResourceGroup(ctx context.Context) {
+	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
-		log.Fatalf("failed to create cosmos db database client: %v", err)
-	}
-
-	properties := armcosmos.SQLDatabaseCreateUpdateParameters{
-		Location: &location,
-		Properties: &armcosmos.SQLDatabaseCreateUpdateProperties{
-			Resource: &armcosmos.SQLDatabaseResource{ID: &databaseName},
-		},
+		log.Fatalf("failed to create resource group client: %v", err)
 	}
 
-	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, nil)
-	if err != nil {
-		log.Fatalf("failed to create cosmos db account client: %v", err)
-	}
-	if _, err := accountClient.Get(ctx, resourceGroupName, accountName, nil); err != nil {
-		log.Fatalf("failed to get cosmos db account: %v", err)
+	_, err = resourceGroupClient.Get(ctx, resourceGroupName, nil)
+	if err == nil {
+		return
 	}
-
-	pollerResp, err := databaseClient.BeginCreateUpdateSQLDatabase(ctx, resourceGroupName, accountName, databaseName, properties, nil)
-	if err != nil {
-		log.Fatalf("failed to begin create or update cosmos db database: %v", err)
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != 404 || !createResourceGroup {
+		log.Fatalf("failed to get resource group: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := resourceGroupClient.CreateOrUpdate(ctx, resourceGroupName, armresources.ResourceGroup{
+		Location: &location,
+		Tags:     sampleTags(ctx),
+	}, nil)
 	if err != nil {
-		log.Fatalf("failed to poll the result: %v", err)
+		log.Fatalf("failed to create resource group: %v", err)
 	}
 
-	fmt.Printf("Created/updated Database: %s\n", *resp.ID)
+	recordResource("Microsoft.Resources/resourceGroups", resp.ID)
+	fmt.Printf("Created Resource Group: %s\n", *resp.ID)
 }
 
-// createOrUpdateCosmosDBContainer creates or updates a NoSQL container and configures throughput.
-func createOrUpdateCosmosDBContainer(ctx context.Context) {
-	containerClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
-	if err != nil {
-		log.Fatalf("failed to create cosmos db container client: %v", err)
-	}
-
-	if _, err := containerClient.GetSQLDatabase(ctx, resourceGroupName, accountName, databaseName, nil); err != nil {
-		log.Fatalf("failed to get cosmos db database: %v", err)
-	}
-
-	// NOTE: The Go `armcosmos` management SDK does not currently expose some newer SQL container fields
-	// (computed properties and vector settings like vectorEmbeddingPolicy / indexingPolicy.vectorIndexes).
-	// This sample creates the container using only fields currently supported by the SDK.
-	// When these features become supported in the Go management SDK, we will add them here.
+func deleteCosmosDBAccount(ctx context.Context) {
+	log.Printf("Starting Cosmos DB account delete (this can take a couple minutes): account=%s", accountName)
 
-	partitionKind := armcosmos.PartitionKindMultiHash
-	indexingMode := armcosmos.IndexingModeConsistent
-	conflictResolutionModeLastWriterWins := armcosmos.ConflictResolutionModeLastWriterWins
+	// A CanNotDelete lock would block the delete, so remove the sample's lock first.
+	deleteAccountLock(ctx)
 
-	properties := armcosmos.SQLContainerCreateUpdateParameters{
-		Location: &location,
-		Properties: &armcosmos.SQLContainerCreateUpdateProperties{
-			Resource: &armcosmos.SQLContainerResource{
-				ID:         &containerName,
-				DefaultTTL: to.Int32Ptr(-1),
-				PartitionKey: &armcosmos.ContainerPartitionKey{
-					Paths:   []*string{to.StringPtr("/companyId"), to.StringPtr("/departmentId"), to.StringPtr("/userId")},
-					Kind:    &partitionKind,
-					Version: to.Int32Ptr(2),
-				},
-				IndexingPolicy: &armcosmos.IndexingPolicy{
-					Automatic:     to.BoolPtr(true),
-					IndexingMode:  &indexingMode,
-					IncludedPaths: []*armcosmos.IncludedPath{{Path: to.StringPtr("/*")}},
-					ExcludedPaths: []*armcosmos.ExcludedPath{{Path: to.StringPtr("/\"_etag\"/?")}},
-				},
-				UniqueKeyPolicy: &armcosmos.UniqueKeyPolicy{
-					UniqueKeys: []*armcosmos.UniqueKey{{Paths: []*string{to.StringPtr("/userId")}}},
-				},
-				ConflictResolutionPolicy: &armcosmos.ConflictResolutionPolicy{
-					Mode:                   &conflictResolutionModeLastWriterWins,
-					ConflictResolutionPath: to.StringPtr("/_ts"),
-				},
-			},
-			Options: &armcosmos.CreateUpdateOptions{
-				AutoscaleSettings: &armcosmos.AutoscaleSettings{MaxThroughput: to.Int32Ptr(int32(maxAutoScaleThroughput))},
-			},
-		},
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
 	}
 
-	pollerResp, err := containerClient.BeginCreateUpdateSQLContainer(ctx, resourceGroupName, accountName, databaseName, containerName, properties, nil)
+	pollerResp, err := accountClient.BeginDelete(ctx, resourceGroupName, accountName, nil)
 	if err != nil {
-		log.Fatalf("failed to begin create or update cosmos db container: %v", err)
+		log.Fatalf("failed to begin delete cosmos db account: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	_, err = pollUntilDone(ctx, pollerResp)
 	if err != nil {
-		log.Fatalf("failed to poll the result: %v", err)
+		log.Fatalf("failed to delete cosmos db account: %v", err)
 	}
 
-	fmt.Printf("Created/updated Collection: %s\n", *resp.ID)
+	fmt.Printf("Deleted Cosmos DB account: %s\n", accountName)
 }
 
 // updateThroughput updates the container throughput by a delta, handling autoscale vs manual throughput.
 func updateThroughput(ctx context.Context, addThroughput int) {
-	log.Printf(
-		"Starting throughput update (this can take a couple minutes): account=%s, database=%s, container=%s, delta=%d",
-		accountName,
-		databaseName,
-		containerName,
-		addThroughput,
-	)
-
-	throughputClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
-	if err != nil {
-		log.Fatalf("failed to create throughput client: %v", err)
-	}
-
-	existing, err := throughputClient.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, databaseName, containerName, nil)
-	if err != nil {
-		var respErr *azcore.ResponseError
-		if errors.As(err, &respErr) && respErr.StatusCode == 404 {
-			log.Fatalf("Container throughput settings were not found. This usually means the container uses shared database throughput or serverless, and therefore does not have a dedicated throughput resource to update. Create the container with dedicated throughput (or update database throughput instead), then retry.")
-		}
-		log.Fatalf("failed to read existing container throughput settings: %v", err)
-	}
-
-	existingResource := (*armcosmos.ThroughputSettingsGetPropertiesResource)(nil)
-	if existing.Properties != nil {
-		existingResource = existing.Properties.Resource
-	}
-	if existingResource == nil {
-		log.Fatalf("Container throughput settings did not include a resource payload. The container likely uses shared database throughput or serverless.")
-	}
-
-	currentAutoscaleMax := (*int32)(nil)
-	if existingResource.AutoscaleSettings != nil {
-		currentAutoscaleMax = existingResource.AutoscaleSettings.MaxThroughput
-	}
-	currentManualThroughput := existingResource.Throughput
-
-	throughput := armcosmos.ThroughputSettingsUpdateParameters{
-		Location: &location,
-		Properties: &armcosmos.ThroughputSettingsUpdateProperties{
-			Resource: &armcosmos.ThroughputSettingsResource{},
-		},
-	}
-
-	if currentAutoscaleMax != nil {
-		baseline := int64(*currentAutoscaleMax)
-		if baseline == 0 {
-			baseline = int64(maxAutoScaleThroughput)
-		}
-		newAutoscaleMax := baseline + int64(addThroughput)
-		if newAutoscaleMax < 1000 {
-			newAutoscaleMax = 1000
-		}
-
-		fmt.Printf("Updating container autoscale max throughput from %d to %d\n", *currentAutoscaleMax, newAutoscaleMax)
-		throughput.Properties.Resource.AutoscaleSettings = &armcosmos.AutoscaleSettingsResource{MaxThroughput: to.Int32Ptr(int32(newAutoscaleMax))}
-	} else {
-		currentManual := int64(0)
-		if currentManualThroughput != nil {
-			currentManual = int64(*currentManualThroughput)
-		}
-
-		baseline := currentManual
-		adjustedDelta := int64(addThroughput)
-		if baseline == 0 {
-			baseline = adjustedDelta
-			adjustedDelta = 0
-		}
-		newManualThroughput := baseline + adjustedDelta
-		if newManualThroughput < 400 {
-			newManualThroughput = 400
-		}
-
-		fmt.Printf("Updating container manual throughput from %d to %d\n", currentManual, newManualThroughput)
-		throughput.Properties.Resource.Throughput = to.Int32Ptr(int32(newManualThroughput))
-	}
-
-	pollerResp, err := throughputClient.BeginUpdateSQLContainerThroughput(ctx, resourceGroupName, accountName, databaseName, containerName, throughput, nil)
-	if err != nil {
-		log.Fatalf("failed to update throughput: %v", err)
-	}
-
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
-	if err != nil {
-		log.Fatalf("failed to poll the result: %v", err)
-	}
-	fmt.Printf("Updated collection throughput for: %s\n", *resp.ID)
-
-	applied, err := throughputClient.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, databaseName, containerName, nil)
-	if err != nil {
-		log.Fatalf("failed to read applied container throughput settings: %v", err)
-	}
-
-	var appliedAutoscaleMax any
-	var appliedManual any
-	if applied.Properties != nil && applied.Properties.Resource != nil {
-		if applied.Properties.Resource.AutoscaleSettings != nil && applied.Properties.Resource.AutoscaleSettings.MaxThroughput != nil {
-			appliedAutoscaleMax = *applied.Properties.Resource.AutoscaleSettings.MaxThroughput
+	setThroughput(ctx, throughputTarget{database: databaseName, container: containerName}, false, func(current int64, autoscale bool) int64 {
+		if autoscale {
+			baseline := current
+			if baseline == 0 {
+				baseline = int64(maxAutoScaleThroughput)
+			}
+			return max(baseline+int64(addThroughput), minAutoscaleMaxThroughput)
 		}
-		if applied.Properties.Resource.Throughput != nil {
-			appliedManual = *applied.Properties.Resource.Throughput
+		if current == 0 {
+			return max(int64(addThroughput), minManualThroughput)
 		}
-	}
-	fmt.Printf("Applied throughput settings: autoscaleMax=%v, manual=%v\n", appliedAutoscaleMax, appliedManual)
+		return max(current+int64(addThroughput), minManualThroughput)
+	})
 }
 
 // createOrUpdateRoleAssignment creates or updates a Cosmos SQL RBAC role assignment for the current principal.
 func createOrUpdateRoleAssignment(ctx context.Context, roleDefinitionID string) {
-	roleAssignmentClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
+	roleAssignmentClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
 		log.Fatalf("failed to create role assignment client: %v", err)
 	}
@@ -558,7 +697,7 @@ func createOrUpdateRoleAssignment(ctx context.Context, roleDefinitionID string)
 
 	assignableScope := getAssignableScope(Account)
 
-	properties := armcosmos.SQLRoleAssignmentCreateUpdateParameters{Properties: &armcosmos.SQLRoleAssignmentResource{RoleDefinitionID: &roleDefinitionID, Scope: &assignableScope, PrincipalID: to.StringPtr(principalID)}}
+	properties := armcosmos.SQLRoleAssignmentCreateUpdateParameters{Properties: &armcosmos.SQLRoleAssignmentResource{RoleDefinitionID: &roleDefinitionID, Scope: &assignableScope, PrincipalID: to.Ptr(principalID)}}
 	roleAssignmentID := uuid5Name(fmt.Sprintf("%s|%s|%s", assignableScope, roleDefinitionID, principalID))
 
 	pollerResp, err := roleAssignmentClient.BeginCreateUpdateSQLRoleAssignment(ctx, roleAssignmentID, resourceGroupName, accountName, properties, nil)
@@ -566,11 +705,16 @@ func createOrUpdateRoleAssignment(ctx context.Context, roleDefinitionID string)
 		log.Fatalf("failed to create or update role assignment: %v", err)
 	}
 
-	_, err = pollerResp.PollUntilDone(ctx, nil)
+	resp, err := pollUntilDone(ctx, pollerResp)
 	if err != nil {
 		log.Fatalf("failed to poll the result: %v", err)
 	}
 
+	roleAssignmentResourceID := ""
+	if resp.ID != nil {
+		roleAssignmentResourceID = *resp.ID
+	}
+	recordRoleAssignment("CosmosDBSqlRBAC", roleAssignmentResourceID, roleDefinitionID, principalID, assignableScope)
 	fmt.Println("Created/updated Cosmos SQL RBAC role assignment.")
 }
 
@@ -586,19 +730,27 @@ func createOrUpdateAzureRoleAssignment(ctx context.Context) {
 		log.Fatalf("failed to resolve Azure RBAC role definition (Cosmos DB Operator): %v", err)
 	}
 
+	principalType, err := getCurrentPrincipalType(ctx)
+	if err != nil {
+		log.Fatalf("failed to get current principal type: %v", err)
+	}
+	log.Printf("Current principal: objectId=%s type=%s", principalObjectID, principalType)
+
 	scope := getAssignableScope(Account)
-	createOrUpdateAzureRoleAssignmentWithDefinition(ctx, scope, roleDefinitionResourceID, principalObjectID)
+	createOrUpdateAzureRoleAssignmentWithDefinition(ctx, scope, roleDefinitionResourceID, principalObjectID, principalType)
 }
 
 // createOrUpdateAzureRoleAssignmentWithDefinition creates or updates an Azure RBAC role assignment idempotently.
-func createOrUpdateAzureRoleAssignmentWithDefinition(ctx context.Context, scope string, roleDefinitionResourceID string, principalObjectID string) {
-	roleAssignmentsClient, err := armauthorization.NewRoleAssignmentsClient(subscriptionID, credential, nil)
+func createOrUpdateAzureRoleAssignmentWithDefinition(ctx context.Context, scope string, roleDefinitionResourceID string, principalObjectID string, principalType armauthorization.PrincipalType) {
+	roleAssignmentsClient, err := armauthorization.NewRoleAssignmentsClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
 		log.Fatalf("failed to create Azure RBAC role assignments client: %v", err)
 	}
 
 	roleAssignmentName := uuid5Name(fmt.Sprintf("%s|%s|%s", scope, roleDefinitionResourceID, principalObjectID))
-	properties := armauthorization.RoleAssignmentCreateParameters{Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: to.StringPtr(roleDefinitionResourceID), PrincipalID: to.StringPtr(principalObjectID)}}
+	// Setting the principal type lets ARM skip the directory lookup, which can fail for a service principal that was
+	// created moments ago and hasn't replicated yet.
+	properties := armauthorization.RoleAssignmentCreateParameters{Properties: &armauthorization.RoleAssignmentProperties{RoleDefinitionID: to.Ptr(roleDefinitionResourceID), PrincipalID: to.Ptr(principalObjectID), PrincipalType: to.Ptr(principalType)}}
 
 	resp, err := roleAssignmentsClient.Create(ctx, scope, roleAssignmentName, properties, nil)
 	if err != nil {
@@ -607,9 +759,11 @@ func createOrUpdateAzureRoleAssignmentWithDefinition(ctx context.Context, scope
 			if respErr.StatusCode == 409 {
 				existing, getErr := roleAssignmentsClient.Get(ctx, scope, roleAssignmentName, nil)
 				if getErr == nil && existing.ID != nil {
+					recordRoleAssignment("AzureRBAC", *existing.ID, roleDefinitionResourceID, principalObjectID, scope)
 					fmt.Printf("Azure RBAC role assignment already exists: %s\n", *existing.ID)
 					return
 				}
+				recordRoleAssignment("AzureRBAC", "", roleDefinitionResourceID, principalObjectID, scope)
 				fmt.Println("Azure RBAC role assignment already exists.")
 				return
 			}
@@ -619,9 +773,11 @@ func createOrUpdateAzureRoleAssignmentWithDefinition(ctx context.Context, scope
 	}
 
 	if resp.ID != nil {
+		recordRoleAssignment("AzureRBAC", *resp.ID, roleDefinitionResourceID, principalObjectID, scope)
 		fmt.Printf("Created Azure RBAC role assignment: %s\n", *resp.ID)
 		return
 	}
+	recordRoleAssignment("AzureRBAC", "", roleDefinitionResourceID, principalObjectID, scope)
 	fmt.Println("Created Azure RBAC role assignment.")
 }
 
@@ -633,13 +789,13 @@ func getBuiltInCosmosDbOperatorRoleDefinitionID(ctx context.Context) (string, er
 
 // getAzureRoleDefinitionIDByName returns a role definition resource ID for a role name at the given scope.
 func getAzureRoleDefinitionIDByName(ctx context.Context, scope string, roleName string) (string, error) {
-	roleDefinitionsClient, err := armauthorization.NewRoleDefinitionsClient(credential, nil)
+	roleDefinitionsClient, err := armauthorization.NewRoleDefinitionsClient(credential, armClientOptions())
 	if err != nil {
 		return "", fmt.Errorf("failed to create Azure RBAC role definitions client: %w", err)
 	}
 
 	filter := fmt.Sprintf("roleName eq '%s'", strings.ReplaceAll(roleName, "'", "''"))
-	pager := roleDefinitionsClient.NewListPager(scope, &armauthorization.RoleDefinitionsClientListOptions{Filter: to.StringPtr(filter)})
+	pager := roleDefinitionsClient.NewListPager(scope, &armauthorization.RoleDefinitionsClientListOptions{Filter: to.Ptr(filter)})
 	for pager.More() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
@@ -666,7 +822,7 @@ func uuid5Name(name string) string {
 
 // getBuiltInDataContributorRoleDefinition returns the Cosmos SQL RBAC built-in data contributor role definition ID.
 func getBuiltInDataContributorRoleDefinition(ctx context.Context) (string, error) {
-	roleDefinitionClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
+	roleDefinitionClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
 		return "", fmt.Errorf("failed to create role definition client: %v", err)
 	}
@@ -683,31 +839,31 @@ func getBuiltInDataContributorRoleDefinition(ctx context.Context) (string, error
 
 // createOrUpdateCustomRoleDefinition creates a custom Cosmos SQL RBAC role definition (delete action commented out).
 func createOrUpdateCustomRoleDefinition(ctx context.Context) (string, error) {
-	roleDefinitionClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, nil)
+	roleDefinitionClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
 	if err != nil {
 		return "", fmt.Errorf("failed to create role definition client: %v", err)
 	}
 
-	assignableScope := []*string{to.StringPtr(getAssignableScope(Account))}
+	assignableScope := to.SliceOfPtrs(getAssignableScope(Account))
 	roleDefinitionTypeCustomRole := armcosmos.RoleDefinitionTypeCustomRole
 
 	properties := armcosmos.SQLRoleDefinitionCreateUpdateParameters{
 		Properties: &armcosmos.SQLRoleDefinitionResource{
-			RoleName:         to.StringPtr("My Custom Cosmos DB Data Contributor Except Delete"),
+			RoleName:         to.Ptr("My Custom Cosmos DB Data Contributor Except Delete"),
 			Type:             &roleDefinitionTypeCustomRole,
 			AssignableScopes: assignableScope,
 			Permissions: []*armcosmos.Permission{{
 				DataActions: []*string{
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/readMetadata"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/create"),
-					// to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/delete"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/read"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/replace"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/upsert"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeQuery"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/readChangeFeed"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeStoredProcedure"),
-					to.StringPtr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/manageConflicts"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/readMetadata"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/create"),
+					// to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/delete"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/read"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/replace"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/items/upsert"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeQuery"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/readChangeFeed"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/executeStoredProcedure"),
+					to.Ptr("Microsoft.DocumentDB/databaseAccounts/sqlDatabases/containers/manageConflicts"),
 				},
 			}},
 		},
@@ -719,7 +875,7 @@ func createOrUpdateCustomRoleDefinition(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("failed to create new role definition: %v", err)
 	}
 
-	resp, err := pollerResp.PollUntilDone(ctx, nil)
+	resp, err := pollUntilDone(ctx, pollerResp)
 	if err != nil {
 		return "", fmt.Errorf("failed to poll the result: %v", err)
 	}
@@ -728,13 +884,17 @@ func createOrUpdateCustomRoleDefinition(ctx context.Context) (string, error) {
 	return *resp.ID, nil
 }
 
-const armTokenScope = "https://management.azure.com/.default"
-
-// getCurrentPrincipalObjectID returns the current principal object ID from the ARM token (or env override).
+// getCurrentPrincipalObjectID returns the current principal object ID from the ARM token, unless
+// AZURE_PRINCIPAL_OBJECT_ID or the PrincipalObjectId setting overrides it.
+// The oid claim is present for users, service principals, and managed identities alike, so no Microsoft Graph
+// lookup (which would need /me for users and /servicePrincipals for apps) is required.
 func getCurrentPrincipalObjectID(ctx context.Context) (string, error) {
 	if override := strings.TrimSpace(os.Getenv("AZURE_PRINCIPAL_OBJECT_ID")); override != "" {
 		return override, nil
 	}
+	if configured := strings.TrimSpace(viper.GetString("PrincipalObjectId")); configured != "" {
+		return configured, nil
+	}
 
 	claims, err := getArmTokenClaims(ctx)
 	if err != nil {
@@ -748,14 +908,47 @@ func getCurrentPrincipalObjectID(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("could not determine current principal object id (oid) from the ARM access token")
 }
 
+// getCurrentPrincipalType reports whether the credential is a user or a service principal (which includes managed
+// identities), from the token's idtyp claim. App-only tokens carry roles instead of delegated scopes (scp), which
+// identifies them when idtyp isn't emitted. AZURE_PRINCIPAL_TYPE (User or ServicePrincipal) overrides detection.
+func getCurrentPrincipalType(ctx context.Context) (armauthorization.PrincipalType, error) {
+	if override := strings.TrimSpace(os.Getenv("AZURE_PRINCIPAL_TYPE")); override != "" {
+		for _, t := range []armauthorization.PrincipalType{armauthorization.PrincipalTypeUser, armauthorization.PrincipalTypeServicePrincipal} {
+			if strings.EqualFold(override, string(t)) {
+				return t, nil
+			}
+		}
+		return "", fmt.Errorf("invalid AZURE_PRINCIPAL_TYPE %q (expected User or ServicePrincipal)", override)
+	}
+
+	claims, err := getArmTokenClaims(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if idtyp, ok := claims["idtyp"].(string); ok {
+		switch strings.ToLower(idtyp) {
+		case "app":
+			return armauthorization.PrincipalTypeServicePrincipal, nil
+		case "user":
+			return armauthorization.PrincipalTypeUser, nil
+		}
+	}
+	if _, delegated := claims["scp"]; delegated {
+		return armauthorization.PrincipalTypeUser, nil
+	}
+	return armauthorization.PrincipalTypeServicePrincipal, nil
+}
+
 // getCurrentUserEmailBestEffort extracts a user identifier (UPN/email) from the ARM token claims.
+// Service principals and managed identities have no UPN, so their application (client) id is returned instead.
 func getCurrentUserEmailBestEffort(ctx context.Context) string {
 	claims, err := getArmTokenClaims(ctx)
 	if err != nil {
 		return ""
 	}
 
-	for _, key := range []string{"preferred_username", "upn", "unique_name"} {
+	for _, key := range []string{"preferred_username", "upn", "unique_name", "appid", "azp"} {
 		if value, ok := claims[key].(string); ok {
 			value = strings.TrimSpace(value)
 			if value != "" {
@@ -769,7 +962,7 @@ func getCurrentUserEmailBestEffort(ctx context.Context) string {
 
 // getArmTokenClaims acquires an ARM access token and parses its JWT claims.
 func getArmTokenClaims(ctx context.Context) (map[string]any, error) {
-	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{armTokenScope}})
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{armTokenScope()}})
 	if err != nil {
 		return nil, fmt.Errorf("failed to acquire ARM access token: %w", err)
 	}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// cassandraAdminPasswordEnv holds the initial password of the cluster's "cassandra" admin user. It is only sent when the
+// cluster is created, and is kept out of config.json and the command line.
+const cassandraAdminPasswordEnv = "CASSANDRA_ADMIN_PASSWORD"
+
+// runManagedCassandraCommand provisions and inspects Azure Managed Instance for Apache Cassandra clusters. These are a
+// separate resource type from Cosmos DB accounts and live in ResourceGroupName, not in the configured account.
+func runManagedCassandraCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("managed-cassandra")
+	cluster := fs.String("cluster", "", "Cluster name (required)")
+	subnet := fs.String("subnet", "", "create: resource ID of the subnet delegated to Azure Managed Instance for Apache Cassandra (required)")
+	dataCenter := fs.String("data-center", "dc1", "create, scale: data center name")
+	dataCenterLocation := fs.String("dc-location", "", "create: data center region; defaults to Location")
+	nodes := fs.Int("nodes", 3, "create, scale: number of nodes in the data center (at least 3)")
+	sku := fs.String("sku", "Standard_DS14_v2", "create: VM size of the nodes")
+	disks := fs.Int("disks", 4, "create: number of P30 data disks per node")
+	version := fs.String("version", "4.0", "create: Cassandra version")
+	maxAge := fs.Duration("max-age", 0, "backups: exit with status 1 unless a backup succeeded within this time")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: managed-cassandra -cluster <name> -subnet <subnet ID> [flags] create | managed-cassandra -cluster <name> seeds")
+		fmt.Fprintln(fs.Output(), "       managed-cassandra -cluster <name> [-data-center <name>] -nodes <count> scale")
+		fmt.Fprintln(fs.Output(), "       managed-cassandra -cluster <name> [-max-age <duration>] backups | managed-cassandra -cluster <name> backup <backup ID>")
+		fmt.Fprintf(fs.Output(), "create reads the initial admin password from %s.\n", cassandraAdminPasswordEnv)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *cluster == "" || fs.NArg() != 1 && !(fs.Arg(0) == "backup" && fs.NArg() == 2) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch fs.Arg(0) {
+	case "backups":
+		listManagedCassandraBackups(ctx, *cluster, *maxAge)
+		return
+	case "backup":
+		printManagedCassandraBackup(ctx, *cluster, fs.Arg(1))
+		return
+	}
+
+	clustersClient, err := armcosmos.NewCassandraClustersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create managed cassandra clusters client: %v", err)
+	}
+	dataCentersClient, err := armcosmos.NewCassandraDataCentersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create managed cassandra data centers client: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "create":
+		if *subnet == "" || *nodes < 3 || *disks < 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if *dataCenterLocation == "" {
+			*dataCenterLocation = location
+		}
+		if err := createManagedCassandraCluster(ctx, clustersClient, *cluster, *subnet, *version); err != nil {
+			log.Fatalf("failed to create managed cassandra cluster %s: %v", *cluster, err)
+		}
+		dc := armcosmos.DataCenterResourceProperties{
+			DataCenterLocation: dataCenterLocation,
+			DelegatedSubnetID:  subnet,
+			NodeCount:          to.Ptr(int32(*nodes)),
+			SKU:                sku,
+			DiskCapacity:       to.Ptr(int32(*disks)),
+		}
+		if err := createManagedCassandraDataCenter(ctx, dataCentersClient, *cluster, *dataCenter, dc); err != nil {
+			log.Fatalf("failed to create data center %s: %v", *dataCenter, err)
+		}
+		printManagedCassandraSeedNodes(ctx, dataCentersClient, *cluster)
+	case "seeds":
+		printManagedCassandraSeedNodes(ctx, dataCentersClient, *cluster)
+	case "scale":
+		nodesSet := false
+		fs.Visit(func(f *flag.Flag) { nodesSet = nodesSet || f.Name == "nodes" })
+		if !nodesSet || *nodes < 3 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		scaleManagedCassandraDataCenter(ctx, clustersClient, dataCentersClient, *cluster, *dataCenter, int32(*nodes))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// createManagedCassandraCluster creates the cluster resource, which holds no nodes until a data center is added. An
+// existing cluster is left as is.
+func createManagedCassandraCluster(ctx context.Context, client *armcosmos.CassandraClustersClient, cluster string, subnet string, version string) error {
+	existing, err := client.Get(ctx, resourceGroupName, cluster, nil)
+	if err == nil {
+		state := ""
+		if existing.Properties != nil {
+			state = enumValue(existing.Properties.ProvisioningState)
+		}
+		fmt.Printf("Managed Cassandra cluster %s already exists (%s).\n", cluster, orDash(state))
+		return nil
+	}
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	password := os.Getenv(cassandraAdminPasswordEnv)
+	if password == "" {
+		return fmt.Errorf("set %s to the initial admin password", cassandraAdminPasswordEnv)
+	}
+
+	body := armcosmos.ClusterResource{
+		Location: to.Ptr(location),
+		Tags:     sampleTags(ctx),
+		Properties: &armcosmos.ClusterResourceProperties{
+			DelegatedManagementSubnetID:   to.Ptr(subnet),
+			InitialCassandraAdminPassword: to.Ptr(password),
+			CassandraVersion:              to.Ptr(version),
+		},
+	}
+	fmt.Printf("Creating managed Cassandra cluster %s (this can take several minutes)...\n", cluster)
+	poller, err := client.BeginCreateUpdate(ctx, resourceGroupName, cluster, body, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return err
+	}
+	recordResource("Microsoft.DocumentDB/cassandraClusters", resp.ID)
+	fmt.Printf("Created managed Cassandra cluster: %s\n", stringValue(resp.ID))
+	return nil
+}
+
+// createManagedCassandraDataCenter adds a data center (the cluster's nodes) in one region. An existing data center is
+// left as is.
+func createManagedCassandraDataCenter(ctx context.Context, client *armcosmos.CassandraDataCentersClient, cluster string, dataCenter string, properties armcosmos.DataCenterResourceProperties) error {
+	existing, err := client.Get(ctx, resourceGroupName, cluster, dataCenter, nil)
+	if err == nil {
+		nodes := "-"
+		if existing.Properties != nil {
+			nodes = int32Value(existing.Properties.NodeCount)
+		}
+		fmt.Printf("Data center %s already exists with %s nodes.\n", dataCenter, nodes)
+		return nil
+	}
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	fmt.Printf("Creating data center %s in %s with %d %s nodes (this can take 10 minutes or more)...\n", dataCenter, *properties.DataCenterLocation, *properties.NodeCount, *properties.SKU)
+	poller, err := client.BeginCreateUpdate(ctx, resourceGroupName, cluster, dataCenter, armcosmos.DataCenterResource{Properties: &properties}, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		return err
+	}
+	recordResource("Microsoft.DocumentDB/cassandraClusters/dataCenters", resp.ID)
+	fmt.Printf("Created data center: %s\n", stringValue(resp.ID))
+	return nil
+}
+
+// scaleManagedCassandraDataCenter changes the node count of a data center, waits for the update, and then polls the
+// cluster status until the data center has that many nodes, all Up and Normal. Nodes join or leave the ring one at a
+// time, so the status lags the resource update. The wait is bounded by OperationTimeout when it is set.
+func scaleManagedCassandraDataCenter(ctx context.Context, clustersClient *armcosmos.CassandraClustersClient, dataCentersClient *armcosmos.CassandraDataCentersClient, cluster string, dataCenter string, nodes int32) {
+	current, err := dataCentersClient.Get(ctx, resourceGroupName, cluster, dataCenter, nil)
+	if err != nil {
+		log.Fatalf("failed to get data center %s: %v", dataCenter, err)
+	}
+	if current.Properties == nil || current.Properties.NodeCount == nil {
+		log.Fatalf("data center %s reported no node count", dataCenter)
+	}
+	from := *current.Properties.NodeCount
+	if from == nodes {
+		fmt.Printf("Data center %s already has %d nodes; nothing to do.\n", dataCenter, nodes)
+		return
+	}
+	if *createOnly {
+		fmt.Printf("Leaving data center %s at %d nodes (-create-only).\n", dataCenter, from)
+		return
+	}
+
+	direction := "up"
+	if nodes < from {
+		direction = "down"
+	}
+	fmt.Printf("Scaling data center %s %s from %d to %d nodes...\n", dataCenter, direction, from, nodes)
+	body := armcosmos.DataCenterResource{Properties: &armcosmos.DataCenterResourceProperties{NodeCount: to.Ptr(nodes)}}
+	poller, err := dataCentersClient.BeginUpdate(ctx, resourceGroupName, cluster, dataCenter, body, nil)
+	if err != nil {
+		log.Fatalf("failed to scale data center %s: %v", dataCenter, err)
+	}
+	if _, err := pollUntilDone(ctx, poller); err != nil {
+		log.Fatalf("failed to scale data center %s: %v", dataCenter, err)
+	}
+
+	if err := waitForManagedCassandraNodes(ctx, clustersClient, cluster, dataCenter, int(nodes)); err != nil {
+		log.Fatalf("data center %s did not settle after scaling: %v", dataCenter, err)
+	}
+	fmt.Printf("Data center %s now has %d nodes, all Up and Normal.\n", dataCenter, nodes)
+}
+
+// waitForManagedCassandraNodes polls the cluster status until the data center has want nodes that are all Up and
+// Normal, printing the node table whenever it changes.
+func waitForManagedCassandraNodes(ctx context.Context, client *armcosmos.CassandraClustersClient, cluster string, dataCenter string, want int) error {
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+	interval := defaultWatchInterval
+	if pollFrequency > 0 {
+		interval = pollFrequency
+	}
+
+	previous := ""
+	for {
+		resp, err := client.Status(ctx, resourceGroupName, cluster, nil)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("nodes did not settle within OperationTimeout (%s): %w", operationTimeout, err)
+			}
+			return fmt.Errorf("failed to get cluster status: %w", err)
+		}
+
+		var nodes []*armcosmos.ComponentsM9L909SchemasCassandraclusterpublicstatusPropertiesDatacentersItemsPropertiesNodesItems
+		for _, dc := range resp.DataCenters {
+			if dc != nil && stringValue(dc.Name) == dataCenter {
+				nodes = dc.Nodes
+			}
+		}
+
+		ready := 0
+		var table strings.Builder
+		tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  ADDRESS\tSTATUS\tSTATE\tLOAD\tRACK")
+		for _, n := range nodes {
+			if n == nil {
+				continue
+			}
+			if stringValue(n.Status) == "Up" && enumValue(n.State) == string(armcosmos.NodeStateNormal) {
+				ready++
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", stringValue(n.Address), orDash(stringValue(n.Status)), orDash(enumValue(n.State)), orDash(stringValue(n.Load)), orDash(stringValue(n.Rack)))
+		}
+		_ = tw.Flush()
+		if table.String() != previous {
+			fmt.Printf("Data center %s: %d of %d nodes Up and Normal (%d reported)\n%s", dataCenter, ready, want, len(nodes), table.String())
+			previous = table.String()
+		}
+		if ready == want && len(nodes) == want {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("nodes did not settle within OperationTimeout (%s): %w", operationTimeout, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// printManagedCassandraSeedNodes prints the seed node IP addresses of each data center; clients and hybrid clusters
+// use them as contact points.
+func printManagedCassandraSeedNodes(ctx context.Context, client *armcosmos.CassandraDataCentersClient, cluster string) {
+	pager := client.NewListPager(resourceGroupName, cluster, nil)
+	found := false
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list data centers of %s: %v", cluster, err)
+		}
+		for _, dc := range page.Value {
+			if dc == nil || dc.Properties == nil {
+				continue
+			}
+			found = true
+			seeds := make([]string, 0, len(dc.Properties.SeedNodes))
+			for _, seed := range dc.Properties.SeedNodes {
+				if seed != nil && seed.IPAddress != nil {
+					seeds = append(seeds, *seed.IPAddress)
+				}
+			}
+			fmt.Printf("Data center %s (%s): seed nodes %s\n", stringValue(dc.Name), stringValue(dc.Properties.DataCenterLocation), orDash(strings.Join(seeds, ", ")))
+		}
+	}
+	if !found {
+		fmt.Printf("Cluster %s has no data centers.\n", cluster)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+)
+
+// metricQuery describes an Azure Monitor platform metric to read for the Cosmos DB account.
+type metricQuery struct {
+	name        string
+	aggregation string
+}
+
+// metricSummary is the aggregate of all data points returned for a metric over the query window.
+type metricSummary struct {
+	name        string
+	aggregation string
+	unit        string
+	points      int
+	sum         float64
+	max         float64
+}
+
+func (s metricSummary) average() float64 {
+	if s.points == 0 {
+		return 0
+	}
+	return s.sum / float64(s.points)
+}
+
+// runMetricsCommand prints a summary of RU consumption metrics for the configured account or container.
+func runMetricsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("metrics")
+	window := fs.Duration("window", time.Hour, "How far back to query metrics")
+	interval := fs.Duration("interval", 5*time.Minute, "Metric time grain (1m, 5m, 15m, 30m, 1h, 6h, 12h, 24h)")
+	scope := fs.String("scope", "container", "Metric scope: account or container")
+	report := addReportFlags(fs)
+	_ = fs.Parse(args)
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	filter := ""
+	switch strings.ToLower(*scope) {
+	case "account":
+	case "container":
+		filter = containerMetricFilter(databaseName, containerName)
+	default:
+		log.Fatalf("invalid -scope %q (expected account or container)", *scope)
+	}
+
+	queries := []metricQuery{
+		{name: "TotalRequestUnits", aggregation: "Total"},
+		{name: "NormalizedRUConsumption", aggregation: "Maximum"},
+	}
+
+	summaries := make([]metricSummary, 0, len(queries))
+	for _, q := range queries {
+		summary, err := summarizeAccountMetric(ctx, q, filter, *window, *interval)
+		if err != nil {
+			log.Fatalf("failed to query metric %s: %v", q.name, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	table := reportTable{
+		title:   fmt.Sprintf("RU consumption for %s scope over the last %s (time grain %s)", strings.ToLower(*scope), *window, *interval),
+		headers: []string{"METRIC", "AGGREGATION", "UNIT", "POINTS", "SUM", "AVG", "MAX"},
+	}
+	for _, s := range summaries {
+		table.addRow(s.name, s.aggregation, s.unit, fmt.Sprintf("%d", s.points), fmt.Sprintf("%.0f", s.sum), fmt.Sprintf("%.2f", s.average()), fmt.Sprintf("%.2f", s.max))
+	}
+	if err := writeReport(report, "RU consumption of "+accountName, table); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// containerMetricFilter returns the Azure Monitor dimension filter that scopes Cosmos DB metrics to one container. A
+// single quote in a name is doubled, as OData string literals require.
+func containerMetricFilter(database string, container string) string {
+	return fmt.Sprintf("DatabaseName eq '%s' and CollectionName eq '%s'", strings.ReplaceAll(database, "'", "''"), strings.ReplaceAll(container, "'", "''"))
+}
+
+// queryAccountMetric reads a single platform metric for the Cosmos DB account over the given window.
+func queryAccountMetric(ctx context.Context, q metricQuery, filter string, window time.Duration, interval time.Duration) (*armmonitor.Metric, error) {
+	return queryResourceMetric(ctx, getAssignableScope(Account), q, filter, window, interval)
+}
+
+// queryResourceMetric reads a single platform metric for any Cosmos DB account, by resource ID.
+func queryResourceMetric(ctx context.Context, resourceID string, q metricQuery, filter string, window time.Duration, interval time.Duration) (*armmonitor.Metric, error) {
+	metricsClient, err := armmonitor.NewMetricsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %w", err)
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-window)
+	options := &armmonitor.MetricsClientListOptions{
+		Metricnames:     to.Ptr(q.name),
+		Aggregation:     to.Ptr(q.aggregation),
+		Timespan:        to.Ptr(fmt.Sprintf("%s/%s", start.Format(time.RFC3339), end.Format(time.RFC3339))),
+		Interval:        to.Ptr(iso8601Duration(interval)),
+		Metricnamespace: to.Ptr("Microsoft.DocumentDB/databaseAccounts"),
+	}
+	if filter != "" {
+		options.Filter = to.Ptr(filter)
+	}
+
+	resp, err := metricsClient.List(ctx, resourceID, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metrics: %w", err)
+	}
+	if len(resp.Value) == 0 || resp.Value[0] == nil {
+		return nil, fmt.Errorf("metric %s was not returned", q.name)
+	}
+
+	return resp.Value[0], nil
+}
+
+// summarizeAccountMetric reads a metric and aggregates every returned data point.
+func summarizeAccountMetric(ctx context.Context, q metricQuery, filter string, window time.Duration, interval time.Duration) (metricSummary, error) {
+	metric, err := queryAccountMetric(ctx, q, filter, window, interval)
+	if err != nil {
+		return metricSummary{}, err
+	}
+
+	summary := metricSummary{name: q.name, aggregation: q.aggregation}
+	if metric.Unit != nil {
+		summary.unit = string(*metric.Unit)
+	}
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		for _, point := range series.Data {
+			value, ok := metricValue(point, q.aggregation)
+			if !ok {
+				continue
+			}
+			summary.points++
+			summary.sum += value
+			if value > summary.max {
+				summary.max = value
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// metricValue returns the value of a data point for the requested aggregation, if present.
+func metricValue(point *armmonitor.MetricValue, aggregation string) (float64, bool) {
+	if point == nil {
+		return 0, false
+	}
+
+	var value *float64
+	switch aggregation {
+	case "Total":
+		value = point.Total
+	case "Maximum":
+		value = point.Maximum
+	case "Minimum":
+		value = point.Minimum
+	case "Average":
+		value = point.Average
+	case "Count":
+		value = point.Count
+	}
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
+
+// iso8601Duration formats a metric time grain as an ISO 8601 duration (for example PT5M or P1D).
+func iso8601Duration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("P%dD", int(d/(24*time.Hour)))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("PT%dH", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("PT%dM", int(d/time.Minute))
+	}
+}
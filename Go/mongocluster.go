@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mongocluster/armmongocluster"
+)
+
+// mongoAdminPasswordEnv holds the password of the cluster's administrator. It is kept out of config.json and the
+// command line.
+const mongoAdminPasswordEnv = "MONGO_ADMIN_PASSWORD"
+
+// runMongoVCoreCommand provisions or shows an Azure Cosmos DB for MongoDB (vCore) cluster.
+func runMongoVCoreCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("mongo-vcore")
+	cluster := fs.String("cluster", "", "Cluster name (required)")
+	admin := fs.String("admin", "mongoAdmin", "create: administrator user name")
+	tier := fs.String("tier", "M30", "create: compute tier (M10, M20, M30, M40, ...)")
+	storageGB := fs.Int64("storage-gb", 128, "create: storage per shard in GB")
+	highAvailability := fs.Bool("ha", false, "create: keep a standby replica of each shard")
+	shards := fs.Int("shards", 1, "create: number of shards")
+	serverVersion := fs.String("version", "7.0", "create: MongoDB server version")
+	firewall := fs.String("firewall", "", "create: comma-separated IP addresses or start-end ranges allowed to connect")
+	allowAzure := fs.Bool("allow-azure", false, "create: allow connections from Azure services")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mongo-vcore -cluster <name> [flags] (create | show)")
+		fmt.Fprintf(fs.Output(), "create reads the administrator password from %s.\n", mongoAdminPasswordEnv)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *cluster == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// vCore clusters have their own resource type and management surface, unrelated to the database accounts used by
+	// the rest of the sample.
+	client, err := armmongocluster.NewMongoClustersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create mongo clusters client: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "create":
+		rules, err := parseFirewallRanges(*firewall)
+		if err != nil {
+			log.Fatalf("Invalid -firewall: %v", err)
+		}
+		if *shards < 1 || *storageGB < 32 {
+			log.Fatalf("-shards must be at least 1 and -storage-gb at least 32")
+		}
+		password := os.Getenv(mongoAdminPasswordEnv)
+		if password == "" {
+			log.Fatalf("set %s to the administrator password", mongoAdminPasswordEnv)
+		}
+
+		params := armmongocluster.MongoCluster{
+			Location: &location,
+			Tags:     sampleTags(ctx),
+			Properties: &armmongocluster.Properties{
+				AdministratorLogin:         admin,
+				AdministratorLoginPassword: &password,
+				ServerVersion:              serverVersion,
+				CreateMode:                 to.Ptr(armmongocluster.CreateModeDefault),
+				NodeGroupSpecs: []*armmongocluster.NodeGroupSpec{{
+					Kind:       to.Ptr(armmongocluster.NodeKindShard),
+					SKU:        tier,
+					DiskSizeGB: storageGB,
+					EnableHa:   highAvailability,
+					NodeCount:  to.Ptr(int32(*shards)),
+				}},
+				PublicNetworkAccess: to.Ptr(armmongocluster.PublicNetworkAccessEnabled),
+			},
+		}
+		fmt.Printf("Creating MongoDB vCore cluster %s (%s, %d shard(s) of %d GB, high availability %t); this can take 10 minutes or more...\n", *cluster, *tier, *shards, *storageGB, *highAvailability)
+		poller, err := client.BeginCreateOrUpdate(ctx, resourceGroupName, *cluster, params, nil)
+		if err != nil {
+			log.Fatalf("failed to create mongo cluster %s: %v", *cluster, err)
+		}
+		created, err := pollUntilDone(ctx, poller)
+		if err != nil {
+			log.Fatalf("failed to create mongo cluster %s: %v", *cluster, err)
+		}
+		recordResource("Microsoft.DocumentDB/mongoClusters", created.ID)
+		fmt.Printf("Created/updated MongoDB vCore cluster: %s\n", stringValue(created.ID))
+
+		firewallClient, err := armmongocluster.NewFirewallRulesClient(subscriptionID, credential, armClientOptions())
+		if err != nil {
+			log.Fatalf("failed to create mongo cluster firewall rules client: %v", err)
+		}
+		if *allowAzure {
+			// The 0.0.0.0 range is the service's marker for "connections from Azure services".
+			rules = append(rules, [2]string{"0.0.0.0", "0.0.0.0"})
+		}
+		for i, r := range rules {
+			name := fmt.Sprintf("sample-rule-%d", i+1)
+			if r[0] == "0.0.0.0" && r[1] == "0.0.0.0" {
+				name = "AllowAllAzureServicesAndResourcesWithinAzureIps"
+			}
+			rule := armmongocluster.FirewallRule{Properties: &armmongocluster.FirewallRuleProperties{StartIPAddress: to.Ptr(r[0]), EndIPAddress: to.Ptr(r[1])}}
+			rulePoller, err := firewallClient.BeginCreateOrUpdate(ctx, resourceGroupName, *cluster, name, rule, nil)
+			if err == nil {
+				_, err = pollUntilDone(ctx, rulePoller)
+			}
+			if err != nil {
+				log.Fatalf("failed to create firewall rule %s: %v", name, err)
+			}
+			fmt.Printf("Firewall rule %s: %s - %s\n", name, r[0], r[1])
+		}
+		printMongoCluster(ctx, client, *cluster)
+	case "show":
+		printMongoCluster(ctx, client, *cluster)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// printMongoCluster prints a cluster's configuration, state, and connection strings.
+func printMongoCluster(ctx context.Context, client *armmongocluster.MongoClustersClient, cluster string) {
+	resp, err := client.Get(ctx, resourceGroupName, cluster, nil)
+	if err != nil {
+		log.Fatalf("failed to get mongo cluster %s: %v", cluster, err)
+	}
+	p := resp.Properties
+	if p == nil {
+		p = &armmongocluster.Properties{}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Cluster:\t%s\n", stringValue(resp.ID))
+	fmt.Fprintf(tw, "Location:\t%s\n", stringValue(resp.Location))
+	fmt.Fprintf(tw, "State:\t%s / %s\n", orDash(enumValue(p.ProvisioningState)), orDash(enumValue(p.ClusterStatus)))
+	fmt.Fprintf(tw, "Server version:\t%s\n", orDash(stringValue(p.ServerVersion)))
+	for _, g := range p.NodeGroupSpecs {
+		if g == nil {
+			continue
+		}
+		fmt.Fprintf(tw, "Tier:\t%s\n", orDash(stringValue(g.SKU)))
+		if g.DiskSizeGB != nil {
+			fmt.Fprintf(tw, "Storage:\t%d GB per shard\n", *g.DiskSizeGB)
+		}
+		if g.NodeCount != nil {
+			fmt.Fprintf(tw, "Shards:\t%d\n", *g.NodeCount)
+		}
+		fmt.Fprintf(tw, "High availability:\t%t\n", g.EnableHa != nil && *g.EnableHa)
+	}
+	fmt.Fprintf(tw, "Administrator:\t%s\n", orDash(stringValue(p.AdministratorLogin)))
+	_ = tw.Flush()
+
+	// The connection strings contain a <password> placeholder, not the password.
+	connection, err := client.ListConnectionStrings(ctx, resourceGroupName, cluster, nil)
+	if err != nil {
+		log.Printf("Could not list connection strings: %v", err)
+		return
+	}
+	for _, cs := range connection.ConnectionStrings {
+		if cs != nil {
+			fmt.Printf("Connection string (%s): %s\n", orDash(stringValue(cs.Description)), stringValue(cs.ConnectionString))
+		}
+	}
+}
+
+// parseFirewallRanges parses "ip" and "start-end" entries into start/end address pairs.
+func parseFirewallRanges(value string) ([][2]string, error) {
+	ranges := make([][2]string, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		start, end, isRange := strings.Cut(entry, "-")
+		if !isRange {
+			end = start
+		}
+		start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+		if net.ParseIP(start).To4() == nil || net.ParseIP(end).To4() == nil {
+			return nil, fmt.Errorf("%q is not an IPv4 address or range", entry)
+		}
+		ranges = append(ranges, [2]string{start, end})
+	}
+	return ranges, nil
+}
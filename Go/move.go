@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// runMoveCommand validates, and with -yes performs, a move of the account to another resource group.
+func runMoveCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("move")
+	targetSubscription := fs.String("target-subscription", "", "Subscription of the target resource group (default: the account's subscription)")
+	yes := fs.Bool("yes", false, "Move the account; without it, only validate the move")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: move [-target-subscription <id>] [-yes] <target resource group>")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	targetGroup := fs.Arg(0)
+	if *targetSubscription == "" {
+		*targetSubscription = subscriptionID
+	}
+	if strings.EqualFold(*targetSubscription, subscriptionID) && strings.EqualFold(targetGroup, resourceGroupName) {
+		log.Fatalf("the account is already in resource group %s", resourceGroupName)
+	}
+
+	// The target resource group must already exist; a move never creates it.
+	targetGroupClient, err := armresources.NewResourceGroupsClient(*targetSubscription, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create resource group client: %v", err)
+	}
+	target, err := targetGroupClient.Get(ctx, targetGroup, nil)
+	if err != nil {
+		log.Fatalf("failed to get target resource group %s: %v", targetGroup, err)
+	}
+
+	// A lock on the account or either resource group makes the move fail. The sample's own lock is removed for the
+	// move and put back afterwards; other locks have to be removed by their owners.
+	blocking, sampleLock, err := getMoveBlockingLocks(ctx, *targetSubscription, targetGroup)
+	if err != nil {
+		log.Fatalf("failed to list management locks: %v", err)
+	}
+	if len(blocking) > 0 {
+		for _, id := range blocking {
+			fmt.Printf("Lock: %s\n", id)
+		}
+		log.Fatalf("%d management lock(s) block the move; remove them first", len(blocking))
+	}
+
+	move := armresources.MoveInfo{
+		Resources:           to.SliceOfPtrs(getAssignableScope(Account)),
+		TargetResourceGroup: target.ID,
+	}
+	resourcesClient, err := armresources.NewClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create resources client: %v", err)
+	}
+
+	// Validation checks the move without locking either resource group, and reports every reason it would fail.
+	// The sample's lock is still in place here and would fail it, so validate only when there is none.
+	if !sampleLock {
+		fmt.Printf("Validating the move of %s to %s...\n", accountName, stringValue(target.ID))
+		validation, err := resourcesClient.BeginValidateMoveResources(ctx, resourceGroupName, move, nil)
+		if err != nil {
+			log.Fatalf("failed to start move validation: %v", err)
+		}
+		if _, err := pollUntilDone(ctx, validation); err != nil {
+			log.Fatalf("the move is not valid: %v", err)
+		}
+		fmt.Println("The move is valid.")
+	} else {
+		fmt.Printf("The account has the sample's %s lock; the move is validated after -yes removes it.\n", accountLockName)
+	}
+	printMoveCaveats()
+	if !*yes {
+		fmt.Println("Run with -yes to move the account.")
+		return
+	}
+
+	if sampleLock {
+		deleteAccountLock(ctx)
+		validation, err := resourcesClient.BeginValidateMoveResources(ctx, resourceGroupName, move, nil)
+		if err == nil {
+			_, err = pollUntilDone(ctx, validation)
+		}
+		if err != nil {
+			createOrUpdateAccountLock(ctx)
+			log.Fatalf("the move is not valid: %v", err)
+		}
+	}
+
+	// Both resource groups are locked against changes while the move runs.
+	fmt.Printf("Moving %s to %s (both resource groups are locked until it finishes)...\n", accountName, stringValue(target.ID))
+	poller, err := resourcesClient.BeginMoveResources(ctx, resourceGroupName, move, nil)
+	if err == nil {
+		_, err = pollUntilDone(ctx, poller)
+	}
+	if err != nil {
+		// A failed move leaves the account where it was, so put its lock back there.
+		if sampleLock {
+			createOrUpdateAccountLock(ctx)
+		}
+		log.Fatalf("failed to move account: %v", err)
+	}
+
+	subscriptionID, resourceGroupName = *targetSubscription, targetGroup
+	fmt.Printf("Moved account: %s\n", getAssignableScope(Account))
+	if sampleLock {
+		createOrUpdateAccountLock(ctx)
+	}
+	fmt.Printf("Set SubscriptionId and ResourceGroupName in config.json to %s and %s to keep managing the account.\n", subscriptionID, resourceGroupName)
+}
+
+// getMoveBlockingLocks returns the IDs of the locks that would block the move, other than the sample's account lock,
+// and whether the sample's lock is present.
+func getMoveBlockingLocks(ctx context.Context, targetSubscription string, targetGroup string) ([]string, bool, error) {
+	sourceLocks, err := armlocks.NewManagementLocksClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, false, err
+	}
+	targetLocks, err := armlocks.NewManagementLocksClient(targetSubscription, credential, armClientOptions())
+	if err != nil {
+		return nil, false, err
+	}
+
+	var blocking []string
+	sampleLock := false
+	sampleLockID := strings.ToLower(getAssignableScope(Account) + "/providers/Microsoft.Authorization/locks/" + accountLockName)
+	// Listing at the account's scope includes locks inherited from the resource group and subscription.
+	accountPager := sourceLocks.NewListByScopePager(getAssignableScope(Account), nil)
+	for accountPager.More() {
+		page, err := accountPager.NextPage(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, l := range page.Value {
+			if l == nil || l.ID == nil {
+				continue
+			}
+			if strings.ToLower(*l.ID) == sampleLockID {
+				sampleLock = true
+				continue
+			}
+			blocking = append(blocking, *l.ID)
+		}
+	}
+	targetPager := targetLocks.NewListAtResourceGroupLevelPager(targetGroup, nil)
+	for targetPager.More() {
+		page, err := targetPager.NextPage(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, l := range page.Value {
+			if l != nil && l.ID != nil {
+				blocking = append(blocking, *l.ID)
+			}
+		}
+	}
+	return blocking, sampleLock, nil
+}
+
+// printMoveCaveats lists what a move changes besides the account's resource ID.
+func printMoveCaveats() {
+	fmt.Println("Before moving, note that:")
+	fmt.Println("  - The account's resource ID changes, so scripts and config that use it need updating.")
+	fmt.Println("  - Azure RBAC role assignments on the account aren't moved; recreate them at the new scope.")
+	fmt.Println("  - Metric alerts scoped to the old resource ID stop evaluating; recreate them (for example the throttling alert).")
+	fmt.Println("  - Cosmos DB SQL role assignments, data, endpoints, and keys stay with the account.")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// namingConvention is the NamingConvention setting: rules the account, database, and container names must follow,
+// checked when the configuration is loaded so a non-conforming name never reaches ARM.
+type namingConvention struct {
+	// Environment is a segment the account name must contain, for example dev in cosmos-dev-orders. It defaults to
+	// the environment tag.
+	Environment string
+	// AccountPrefix is the account name's first hyphen-separated segment.
+	AccountPrefix string
+	// DatabasePrefix and ContainerPrefix start every database and container name.
+	DatabasePrefix  string
+	ContainerPrefix string
+	// MaxLength caps database and container names (default 255, the service limit).
+	MaxLength int
+	// Pattern is a regular expression every database and container name must match.
+	Pattern string
+
+	pattern *regexp.Regexp
+}
+
+const maxResourceNameLength = 255
+
+// naming is the loaded NamingConvention; nil when the setting is absent.
+var naming *namingConvention
+
+// loadNamingConvention reads NamingConvention. With an AccountPrefix and neither AccountName nor AccountNamePrefix
+// set, the account name is generated as <AccountPrefix>-<Environment>-<random suffix>.
+func loadNamingConvention() error {
+	naming = nil
+	if !viper.IsSet("NamingConvention") {
+		return nil
+	}
+	var convention namingConvention
+	if err := viper.UnmarshalKey("NamingConvention", &convention); err != nil {
+		return fmt.Errorf("failed to read NamingConvention: %w", err)
+	}
+	convention.Environment = strings.TrimSpace(convention.Environment)
+	if convention.Environment == "" {
+		convention.Environment = strings.TrimSpace(viper.GetString("Tags.environment"))
+	}
+	convention.AccountPrefix = strings.TrimSpace(convention.AccountPrefix)
+
+	for _, segment := range []string{convention.Environment, convention.AccountPrefix} {
+		if segment != "" && (strings.Contains(segment, "-") || !accountNamePrefixPattern.MatchString(segment)) {
+			return fmt.Errorf("NamingConvention Environment and AccountPrefix must be lower-case letters and digits (got %q)", segment)
+		}
+	}
+	if convention.MaxLength == 0 {
+		convention.MaxLength = maxResourceNameLength
+	}
+	if convention.MaxLength < 1 || convention.MaxLength > maxResourceNameLength {
+		return fmt.Errorf("NamingConvention MaxLength must be between 1 and %d (got %d)", maxResourceNameLength, convention.MaxLength)
+	}
+	if convention.Pattern != "" {
+		pattern, err := regexp.Compile(convention.Pattern)
+		if err != nil {
+			return fmt.Errorf("NamingConvention Pattern is not a valid regular expression: %w", err)
+		}
+		convention.pattern = pattern
+	}
+	naming = &convention
+
+	if accountName == "" && accountNamePrefix == "" && convention.AccountPrefix != "" {
+		accountNamePrefix = convention.AccountPrefix
+		if convention.Environment != "" {
+			accountNamePrefix += "-" + convention.Environment
+		}
+	}
+	return nil
+}
+
+// validateResourceNames checks AccountName (or AccountNamePrefix) and every configured database and container name
+// against the naming convention, and returns every violation at once.
+func validateResourceNames() error {
+	if naming == nil {
+		return nil
+	}
+	var problems []error
+	switch {
+	case accountName != "":
+		if !accountNamePattern.MatchString(accountName) {
+			problems = append(problems, fmt.Errorf("AccountName %q must be 3-44 lower-case letters, digits, and hyphens, not starting or ending with a hyphen", accountName))
+		}
+		problems = append(problems, naming.checkAccountSegments("AccountName", accountName)...)
+	case accountNamePrefix != "":
+		problems = append(problems, naming.checkAccountSegments("AccountNamePrefix", accountNamePrefix)...)
+	}
+	for _, db := range databases {
+		problems = append(problems, naming.checkName("database", db.Name, naming.DatabasePrefix)...)
+		for _, c := range db.Containers {
+			problems = append(problems, naming.checkName("container", db.Name+"/"+c.Name, naming.ContainerPrefix)...)
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// checkAccountSegments checks the hyphen-separated segments of an account name or prefix.
+func (n *namingConvention) checkAccountSegments(setting string, name string) []error {
+	var problems []error
+	segments := strings.Split(name, "-")
+	if n.AccountPrefix != "" && segments[0] != n.AccountPrefix {
+		problems = append(problems, fmt.Errorf("%s %q must start with %q", setting, name, n.AccountPrefix+"-"))
+	}
+	if n.Environment != "" {
+		found := false
+		for _, s := range segments {
+			found = found || s == n.Environment
+		}
+		if !found {
+			problems = append(problems, fmt.Errorf("%s %q must contain the environment segment %q", setting, name, "-"+n.Environment))
+		}
+	}
+	return problems
+}
+
+// checkName checks a database name, or a container's "database/container" path, against the prefix, length, and
+// pattern rules.
+func (n *namingConvention) checkName(kind string, path string, prefix string) []error {
+	name := path[strings.LastIndex(path, "/")+1:]
+	var problems []error
+	if !resourceNamePattern.MatchString(name) {
+		problems = append(problems, fmt.Errorf("%s %q must be 1-255 characters, without /, \\, #, or ?", kind, path))
+	}
+	if prefix != "" && !strings.HasPrefix(name, prefix) {
+		problems = append(problems, fmt.Errorf("%s %q must start with %q", kind, path, prefix))
+	}
+	if len(name) > n.MaxLength {
+		problems = append(problems, fmt.Errorf("%s %q is longer than %d characters", kind, path, n.MaxLength))
+	}
+	if n.pattern != nil && !n.pattern.MatchString(name) {
+		problems = append(problems, fmt.Errorf("%s %q doesn't match %s", kind, path, n.Pattern))
+	}
+	return problems
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// maxLogicalPartitionSizeGB is the storage limit of a single logical partition (all items with one partition key value).
+const maxLogicalPartitionSizeGB = 20
+
+// runPartitionSizesCommand reports the largest logical partitions from PartitionKeyStatistics logs and flags those
+// approaching the 20 GB limit, after which writes to that partition key value fail. It exits with status 1 when any
+// partition is over the threshold, so it can run as a scheduled check.
+func runPartitionSizesCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("partition-sizes")
+	window := fs.Duration("window", 24*time.Hour, "How far back to look for partition key statistics (they are emitted periodically)")
+	thresholdGB := fs.Float64("threshold-gb", 16, "Flag logical partitions at or above this size in GB")
+	top := fs.Int("top", 10, "Maximum number of partition keys to list")
+	_ = fs.Parse(args)
+	if *thresholdGB <= 0 || *thresholdGB > maxLogicalPartitionSizeGB {
+		log.Fatalf("-threshold-gb must be between 0 and %d (got %g)", maxLogicalPartitionSizeGB, *thresholdGB)
+	}
+
+	// The statistics only cover the largest partition keys of each physical partition; take each key's latest size.
+	query := fmt.Sprintf(`CDBPartitionKeyStatistics
+| summarize arg_max(TimeGenerated, SizeKb) by DatabaseName, CollectionName, PartitionKey
+| top %d by SizeKb desc`, *top)
+	table, err := queryAccountLogs(ctx, query, *window)
+	if err != nil {
+		log.Fatalf("failed to query partition key statistics (is PartitionKeyStatistics in DiagnosticLogCategories?): %v", err)
+	}
+	if len(table.Rows) == 0 {
+		fmt.Printf("No PartitionKeyStatistics logs in the last %s. Add PartitionKeyStatistics to DiagnosticLogCategories; statistics are only emitted for partitions with enough data.\n", *window)
+		return
+	}
+
+	databaseIndex := logColumnIndex(table, "DatabaseName")
+	containerIndex := logColumnIndex(table, "CollectionName")
+	keyIndex := logColumnIndex(table, "PartitionKey")
+	sizeIndex := logColumnIndex(table, "SizeKb")
+
+	over := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tPARTITION KEY\tSIZE (GB)\t% OF 20 GB\tSTATUS")
+	for _, row := range table.Rows {
+		sizeKB, err := strconv.ParseFloat(logCell(row, sizeIndex), 64)
+		if err != nil {
+			continue
+		}
+		sizeGB := sizeKB / 1024 / 1024
+		flag := ""
+		if sizeGB >= *thresholdGB {
+			flag = "NEAR LIMIT"
+			over++
+		}
+		fmt.Fprintf(tw, "%s/%s\t%s\t%.2f\t%.0f%%\t%s\n", logCell(row, databaseIndex), logCell(row, containerIndex), logCell(row, keyIndex), sizeGB, sizeGB/maxLogicalPartitionSizeGB*100, flag)
+	}
+	_ = tw.Flush()
+
+	if over == 0 {
+		fmt.Printf("No logical partition is at or above %g GB.\n", *thresholdGB)
+		return
+	}
+	fmt.Printf("%d logical partition(s) are at or above %g GB. Writes to a partition key value fail once it reaches %d GB; consider a hierarchical partition key or a more granular key.\n", over, *thresholdGB, maxLogicalPartitionSizeGB)
+	os.Exit(1)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// percentileRow is one rendered line of the latency percentiles table.
+type percentileRow struct {
+	source string
+	target string
+	metric string
+	unit   string
+	points int
+	avgP50 float64
+	maxP99 float64
+}
+
+// runLatencyPercentilesCommand prints P50/P99 replication latency for the account, each target region, and each region pair.
+func runLatencyPercentilesCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("latency-percentiles")
+	window := fs.Duration("window", time.Hour, "How far back to query percentile metrics")
+	interval := fs.Duration("interval", 5*time.Minute, "Percentile metric time grain")
+	_ = fs.Parse(args)
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+
+	regions := make([]string, 0)
+	if account.Properties != nil {
+		for _, loc := range account.Properties.Locations {
+			if loc != nil && loc.LocationName != nil {
+				regions = append(regions, *loc.LocationName)
+			}
+		}
+	}
+
+	filter := percentileFilter(*window, *interval)
+	rows := make([]percentileRow, 0)
+
+	percentileClient, err := armcosmos.NewPercentileClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create percentile client: %v", err)
+	}
+	pager := percentileClient.NewListMetricsPager(resourceGroupName, accountName, filter, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list account percentile metrics: %v", err)
+		}
+		rows = append(rows, summarizePercentileMetrics("(any)", "(any)", page.Value)...)
+	}
+
+	targetClient, err := armcosmos.NewPercentileTargetClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create percentile target client: %v", err)
+	}
+	for _, target := range regions {
+		pager := targetClient.NewListMetricsPager(resourceGroupName, accountName, target, filter, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				log.Fatalf("failed to list percentile metrics for target region %s: %v", target, err)
+			}
+			rows = append(rows, summarizePercentileMetrics("(any)", target, page.Value)...)
+		}
+	}
+
+	sourceTargetClient, err := armcosmos.NewPercentileSourceTargetClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create percentile source/target client: %v", err)
+	}
+	for _, source := range regions {
+		for _, target := range regions {
+			if source == target {
+				continue
+			}
+			pager := sourceTargetClient.NewListMetricsPager(resourceGroupName, accountName, source, target, filter, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					log.Fatalf("failed to list percentile metrics for %s -> %s: %v", source, target, err)
+				}
+				rows = append(rows, summarizePercentileMetrics(source, target, page.Value)...)
+			}
+		}
+	}
+
+	fmt.Printf("Replication latency percentiles over the last %s (time grain %s)\n", *window, *interval)
+	if len(regions) < 2 {
+		fmt.Println("The account has a single region, so there are no region pairs to report.")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tTARGET\tMETRIC\tUNIT\tPOINTS\tAVG P50\tMAX P99")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%.2f\t%.2f\n", r.source, r.target, r.metric, r.unit, r.points, r.avgP50, r.maxP99)
+	}
+	_ = tw.Flush()
+}
+
+// percentileFilter builds the OData filter expected by the percentile metrics APIs.
+// The percentile APIs report "Probabilistic Bounded Staleness", the replication latency between regions.
+func percentileFilter(window time.Duration, interval time.Duration) string {
+	end := time.Now().UTC()
+	start := end.Add(-window)
+	return fmt.Sprintf(
+		"(name.value eq 'Probabilistic Bounded Staleness') and timeGrain eq duration'%s' and startTime eq '%s' and endTime eq '%s'",
+		iso8601Duration(interval),
+		start.Format(time.RFC3339),
+		end.Format(time.RFC3339),
+	)
+}
+
+// summarizePercentileMetrics reduces percentile metric series to an average P50 and a worst-case P99.
+func summarizePercentileMetrics(source string, target string, metrics []*armcosmos.PercentileMetric) []percentileRow {
+	rows := make([]percentileRow, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric == nil {
+			continue
+		}
+
+		row := percentileRow{source: source, target: target}
+		if metric.Name != nil && metric.Name.Value != nil {
+			row.metric = *metric.Name.Value
+		}
+		if metric.Unit != nil {
+			row.unit = string(*metric.Unit)
+		}
+
+		sumP50 := 0.0
+		for _, value := range metric.MetricValues {
+			if value == nil || value.P50 == nil {
+				continue
+			}
+			row.points++
+			sumP50 += *value.P50
+			if value.P99 != nil && *value.P99 > row.maxP99 {
+				row.maxP99 = *value.P99
+			}
+		}
+		if row.points > 0 {
+			row.avgP50 = sumP50 / float64(row.points)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/policyinsights/armpolicyinsights"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
+)
+
+// cosmosNetworkPolicy is a built-in policy definition the sample assigns to the resource group.
+type cosmosNetworkPolicy struct {
+	assignmentName string
+	definitionID   string
+	displayName    string
+}
+
+// cosmosNetworkPolicies are built-in policies that harden Cosmos DB networking. Both take an "effect" parameter
+// (Audit, Deny, or Disabled).
+var cosmosNetworkPolicies = []cosmosNetworkPolicy{
+	{
+		assignmentName: "cosmos-firewall-rules",
+		definitionID:   "/providers/Microsoft.Authorization/policyDefinitions/862e97cf-49fc-4a5c-9de4-40d4e2e7c8eb",
+		displayName:    "Azure Cosmos DB accounts should have firewall rules",
+	},
+	{
+		assignmentName: "cosmos-no-public-network",
+		definitionID:   "/providers/Microsoft.Authorization/policyDefinitions/797b37f7-06b8-444c-b1ad-fc62867f335a",
+		displayName:    "Azure Cosmos DB should disable public network access",
+	},
+}
+
+// runPolicyCommand assigns the Cosmos DB network hardening policies to the resource group, reports compliance with
+// them, or removes them.
+func runPolicyCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("policy")
+	effect := fs.String("effect", "Audit", "assign: Audit reports non-compliant accounts; Deny also blocks creating or updating them")
+	scan := fs.Bool("scan", false, "compliance: trigger a compliance scan of the resource group first (can take several minutes)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: policy [-effect Audit|Deny] [-scan] (assign | compliance | unassign)")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if (*effect != "Audit" && *effect != "Deny") || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	switch fs.Arg(0) {
+	case "assign":
+		assignCosmosNetworkPolicies(ctx, *effect)
+	case "compliance":
+		if *scan {
+			triggerPolicyEvaluation(ctx)
+		}
+		printCosmosNetworkPolicyCompliance(ctx)
+	case "unassign":
+		unassignCosmosNetworkPolicies(ctx)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// assignCosmosNetworkPolicies creates or updates an assignment of each cosmosNetworkPolicies definition on the resource group.
+func assignCosmosNetworkPolicies(ctx context.Context, effect string) {
+	client, err := armpolicy.NewAssignmentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create policy assignments client: %v", err)
+	}
+	for _, p := range cosmosNetworkPolicies {
+		assignment := armpolicy.Assignment{Properties: &armpolicy.AssignmentProperties{
+			DisplayName:        to.Ptr(p.displayName),
+			Description:        to.Ptr("Assigned by the Cosmos DB management sample."),
+			PolicyDefinitionID: to.Ptr(p.definitionID),
+			Parameters:         map[string]*armpolicy.ParameterValuesValue{"effect": {Value: effect}},
+		}}
+		resp, err := client.Create(ctx, getAssignableScope(ResourceGroup), p.assignmentName, assignment, nil)
+		if err != nil {
+			log.Fatalf("failed to assign policy %q: %v", p.displayName, err)
+		}
+		recordResource("Microsoft.Authorization/policyAssignments", resp.ID)
+		fmt.Printf("Created/updated Policy Assignment (%s): %s\n", effect, stringValue(resp.ID))
+	}
+	fmt.Println("New assignments are evaluated within about 30 minutes; run `policy -scan compliance` to evaluate them now.")
+}
+
+// unassignCosmosNetworkPolicies deletes the sample's policy assignments, if present.
+func unassignCosmosNetworkPolicies(ctx context.Context) {
+	client, err := armpolicy.NewAssignmentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create policy assignments client: %v", err)
+	}
+	for _, p := range cosmosNetworkPolicies {
+		// A missing assignment is deleted with 204 No Content.
+		if _, err := client.Delete(ctx, getAssignableScope(ResourceGroup), p.assignmentName, nil); err != nil {
+			log.Fatalf("failed to delete policy assignment %s: %v", p.assignmentName, err)
+		}
+		fmt.Printf("Deleted Policy Assignment: %s\n", p.assignmentName)
+	}
+}
+
+// triggerPolicyEvaluation starts a compliance scan of the resource group and waits for it to finish.
+func triggerPolicyEvaluation(ctx context.Context) {
+	client, err := armpolicyinsights.NewPolicyStatesClient(credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create policy insights client: %v", err)
+	}
+	fmt.Println("Scanning the resource group for policy compliance...")
+	poller, err := client.BeginTriggerResourceGroupEvaluation(ctx, subscriptionID, resourceGroupName, nil)
+	if err != nil {
+		log.Fatalf("failed to start policy compliance scan: %v", err)
+	}
+	if _, err := pollUntilDone(ctx, poller); err != nil {
+		log.Fatalf("failed to scan for policy compliance: %v", err)
+	}
+}
+
+// printCosmosNetworkPolicyCompliance prints how many resources in the resource group comply with each of the sample's
+// policy assignments. It exits with status 1 when any resource is non-compliant.
+func printCosmosNetworkPolicyCompliance(ctx context.Context) {
+	assignments, err := armpolicy.NewAssignmentsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create policy assignments client: %v", err)
+	}
+	insights, err := armpolicyinsights.NewPolicyStatesClient(credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create policy insights client: %v", err)
+	}
+	summary, err := insights.SummarizeForResourceGroup(ctx, armpolicyinsights.PolicyStatesSummaryResourceTypeLatest, subscriptionID, resourceGroupName, nil, nil)
+	if err != nil {
+		log.Fatalf("failed to summarize policy compliance: %v", err)
+	}
+
+	nonCompliant := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "POLICY\tEFFECT\tCOMPLIANT\tNON-COMPLIANT")
+	for _, p := range cosmosNetworkPolicies {
+		assignment, err := assignments.Get(ctx, getAssignableScope(ResourceGroup), p.assignmentName, nil)
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			fmt.Fprintf(tw, "%s\t(not assigned)\t-\t-\n", p.displayName)
+			continue
+		}
+		if err != nil {
+			log.Fatalf("failed to get policy assignment %s: %v", p.assignmentName, err)
+		}
+		effect := "-"
+		if assignment.Properties != nil {
+			if v := assignment.Properties.Parameters["effect"]; v != nil {
+				effect = fmt.Sprint(v.Value)
+			}
+		}
+
+		// Assignments the service hasn't evaluated yet have no summary.
+		compliant, notCompliant := "-", "-"
+		for _, s := range summary.Value {
+			if s == nil {
+				continue
+			}
+			for _, a := range s.PolicyAssignments {
+				if a == nil || a.Results == nil || !strings.EqualFold(stringValue(a.PolicyAssignmentID), stringValue(assignment.ID)) {
+					continue
+				}
+				count := 0
+				for _, d := range a.Results.ResourceDetails {
+					if d != nil && d.Count != nil && strings.EqualFold(stringValue(d.ComplianceState), "compliant") {
+						count += int(*d.Count)
+					}
+				}
+				compliant = fmt.Sprint(count)
+				notCompliant = orDash(int32Value(a.Results.NonCompliantResources))
+				if a.Results.NonCompliantResources != nil {
+					nonCompliant += int(*a.Results.NonCompliantResources)
+				}
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.displayName, effect, compliant, notCompliant)
+	}
+	_ = tw.Flush()
+
+	if nonCompliant > 0 {
+		fmt.Printf("%d resource(s) in %s are non-compliant.\n", nonCompliant, resourceGroupName)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// pollUntilDone waits for a long-running operation using the configured PollFrequency, failing once
+// OperationTimeout elapses. Every Begin* call in the sample finishes through here.
+func pollUntilDone[T any](ctx context.Context, poller *runtime.Poller[T]) (T, error) {
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+
+	var options *runtime.PollUntilDoneOptions
+	if pollFrequency > 0 {
+		options = &runtime.PollUntilDoneOptions{Frequency: pollFrequency}
+	}
+
+	result, err := poller.PollUntilDone(ctx, options)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("operation did not finish within OperationTimeout (%s): %w", operationTimeout, err)
+	}
+	return result, describeThrottlingError(err)
+}
+
+// validatePollingSettings checks the PollFrequency and OperationTimeout settings.
+func validatePollingSettings() error {
+	if pollFrequency != 0 && pollFrequency < time.Second {
+		return fmt.Errorf("PollFrequency must be at least 1s (got %s)", pollFrequency)
+	}
+	if operationTimeout < 0 {
+		return fmt.Errorf("OperationTimeout must not be negative (got %s)", operationTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmosforpostgresql/armcosmosforpostgresql"
+)
+
+// postgresAdminPasswordEnv holds the password of the cluster's "citus" administrator. It is kept out of config.json
+// and the command line.
+const postgresAdminPasswordEnv = "POSTGRES_ADMIN_PASSWORD"
+
+// runPostgresClusterCommand provisions or shows an Azure Cosmos DB for PostgreSQL (Citus) cluster.
+func runPostgresClusterCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("postgres-cluster")
+	cluster := fs.String("cluster", "", "Cluster name (required)")
+	version := fs.String("version", "16", "create: PostgreSQL major version")
+	coordinatorVCores := fs.Int("coordinator-vcores", 4, "create: coordinator vCores")
+	coordinatorStorageGB := fs.Int("coordinator-storage-gb", 512, "create: coordinator storage in GB")
+	nodes := fs.Int("nodes", 2, "create: number of worker nodes (0 for a single-node cluster)")
+	nodeVCores := fs.Int("node-vcores", 4, "create: vCores per worker node")
+	nodeStorageGB := fs.Int("node-storage-gb", 512, "create: storage per worker node in GB")
+	highAvailability := fs.Bool("ha", false, "create: keep a standby for the coordinator and each worker node")
+	firewall := fs.String("firewall", "", "create: comma-separated IP addresses or start-end ranges allowed to connect")
+	allowAzure := fs.Bool("allow-azure", false, "create: allow connections from Azure services")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: postgres-cluster -cluster <name> [flags] (create | show)")
+		fmt.Fprintf(fs.Output(), "create reads the administrator password from %s.\n", postgresAdminPasswordEnv)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *cluster == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// Clusters (Citus server groups) belong to the Microsoft.DBforPostgreSQL resource provider, not Microsoft.DocumentDB.
+	client, err := armcosmosforpostgresql.NewClustersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create postgres clusters client: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "create":
+		rules, err := parseFirewallRanges(*firewall)
+		if err != nil {
+			log.Fatalf("Invalid -firewall: %v", err)
+		}
+		if *nodes < 0 || *nodes == 1 {
+			log.Fatalf("-nodes must be 0 (single node) or at least 2")
+		}
+		password := os.Getenv(postgresAdminPasswordEnv)
+		if password == "" {
+			log.Fatalf("set %s to the administrator password", postgresAdminPasswordEnv)
+		}
+
+		properties := &armcosmosforpostgresql.ClusterProperties{
+			AdministratorLoginPassword:      &password,
+			PostgresqlVersion:               version,
+			EnableHa:                        highAvailability,
+			CoordinatorServerEdition:        to.Ptr("GeneralPurpose"),
+			CoordinatorVCores:               to.Ptr(int32(*coordinatorVCores)),
+			CoordinatorStorageQuotaInMb:     to.Ptr(int32(*coordinatorStorageGB * 1024)),
+			CoordinatorEnablePublicIPAccess: to.Ptr(true),
+			NodeCount:                       to.Ptr(int32(*nodes)),
+		}
+		if *nodes > 0 {
+			properties.NodeServerEdition = to.Ptr("MemoryOptimized")
+			properties.NodeVCores = to.Ptr(int32(*nodeVCores))
+			properties.NodeStorageQuotaInMb = to.Ptr(int32(*nodeStorageGB * 1024))
+			properties.NodeEnablePublicIPAccess = to.Ptr(false)
+		}
+
+		fmt.Printf("Creating Cosmos DB for PostgreSQL cluster %s (coordinator %d vCores, %d worker node(s)); this can take 10 minutes or more...\n", *cluster, *coordinatorVCores, *nodes)
+		poller, err := client.BeginCreate(ctx, resourceGroupName, *cluster, armcosmosforpostgresql.Cluster{Location: &location, Tags: sampleTags(ctx), Properties: properties}, nil)
+		if err != nil {
+			log.Fatalf("failed to create postgres cluster %s: %v", *cluster, err)
+		}
+		created, err := pollUntilDone(ctx, poller)
+		if err != nil {
+			log.Fatalf("failed to create postgres cluster %s: %v", *cluster, err)
+		}
+		recordResource("Microsoft.DBforPostgreSQL/serverGroupsv2", created.ID)
+		fmt.Printf("Created Cosmos DB for PostgreSQL cluster: %s\n", stringValue(created.ID))
+
+		firewallClient, err := armcosmosforpostgresql.NewFirewallRulesClient(subscriptionID, credential, armClientOptions())
+		if err != nil {
+			log.Fatalf("failed to create postgres cluster firewall rules client: %v", err)
+		}
+		if *allowAzure {
+			// The 0.0.0.0 range is the service's marker for "connections from Azure services".
+			rules = append(rules, [2]string{"0.0.0.0", "0.0.0.0"})
+		}
+		for i, r := range rules {
+			name := fmt.Sprintf("sample-rule-%d", i+1)
+			if r[0] == "0.0.0.0" && r[1] == "0.0.0.0" {
+				name = "AllowAllAzureServicesAndResourcesWithinAzureIps"
+			}
+			// Firewall rules allow connections to the coordinator.
+			rule := armcosmosforpostgresql.FirewallRule{Properties: &armcosmosforpostgresql.FirewallRuleProperties{StartIPAddress: to.Ptr(r[0]), EndIPAddress: to.Ptr(r[1])}}
+			rulePoller, err := firewallClient.BeginCreateOrUpdate(ctx, resourceGroupName, *cluster, name, rule, nil)
+			if err == nil {
+				_, err = pollUntilDone(ctx, rulePoller)
+			}
+			if err != nil {
+				log.Fatalf("failed to create firewall rule %s: %v", name, err)
+			}
+			fmt.Printf("Firewall rule %s: %s - %s\n", name, r[0], r[1])
+		}
+		printPostgresCluster(ctx, client, *cluster)
+	case "show":
+		printPostgresCluster(ctx, client, *cluster)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// printPostgresCluster prints a cluster's node configuration, its servers, and how to connect to the coordinator.
+func printPostgresCluster(ctx context.Context, client *armcosmosforpostgresql.ClustersClient, cluster string) {
+	c, err := client.Get(ctx, resourceGroupName, cluster, nil)
+	if err != nil {
+		log.Fatalf("failed to get postgres cluster %s: %v", cluster, err)
+	}
+	p := c.Properties
+	if p == nil {
+		p = &armcosmosforpostgresql.ClusterProperties{}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Cluster:\t%s\n", stringValue(c.ID))
+	fmt.Fprintf(tw, "Location:\t%s\n", stringValue(c.Location))
+	fmt.Fprintf(tw, "State:\t%s / %s\n", orDash(stringValue(p.ProvisioningState)), orDash(stringValue(p.State)))
+	fmt.Fprintf(tw, "PostgreSQL / Citus:\t%s / %s\n", orDash(stringValue(p.PostgresqlVersion)), orDash(stringValue(p.CitusVersion)))
+	fmt.Fprintf(tw, "Coordinator:\t%s vCores, %s MB\n", orDash(int32Value(p.CoordinatorVCores)), orDash(int32Value(p.CoordinatorStorageQuotaInMb)))
+	fmt.Fprintf(tw, "Worker nodes:\t%s x %s vCores, %s MB\n", orDash(int32Value(p.NodeCount)), orDash(int32Value(p.NodeVCores)), orDash(int32Value(p.NodeStorageQuotaInMb)))
+	fmt.Fprintf(tw, "High availability:\t%t\n", p.EnableHa != nil && *p.EnableHa)
+	_ = tw.Flush()
+
+	// Clients connect to the coordinator, whose host name starts with "c-"; worker host names start with "w<n>-".
+	coordinator := ""
+	for _, s := range p.ServerNames {
+		if s == nil {
+			continue
+		}
+		fqdn := stringValue(s.FullyQualifiedDomainName)
+		fmt.Printf("Server %s: %s\n", stringValue(s.Name), orDash(fqdn))
+		if strings.HasPrefix(fqdn, "c-") {
+			coordinator = fqdn
+		}
+	}
+	if coordinator != "" {
+		user := stringValue(p.AdministratorLogin)
+		if user == "" {
+			user = "citus"
+		}
+		fmt.Printf("Connection string: host=%s port=5432 dbname=citus user=%s password=<password> sslmode=require\n", coordinator, user)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+const (
+	// Default Cosmos DB limits; both can be raised with a support request.
+	maxAccountsPerSubscription = 250
+	maxContainerThroughput     = 1000000
+)
+
+// runPreflightCommand runs the pre-flight checks without creating anything.
+func runPreflightCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("preflight")
+	_ = fs.Parse(args)
+
+	if warnings := runPreflightChecks(ctx); warnings > 0 {
+		os.Exit(1)
+	}
+}
+
+// runPreflightChecks checks subscription and regional limits before provisioning, so problems show up as warnings
+// up front instead of as errors after minutes of polling. It returns the number of warnings printed.
+func runPreflightChecks(ctx context.Context) int {
+	log.Printf("Running pre-flight checks: subscription=%s location=%s account=%s", subscriptionID, location, accountName)
+
+	warnings := 0
+	warn := func(format string, args ...any) {
+		warnings++
+		fmt.Printf("Pre-flight warning: "+format+"\n", args...)
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+
+	accountExists, err := checkAccountExists(ctx, accountClient)
+	if err != nil {
+		warn("could not check whether account %s exists: %v", accountName, err)
+	}
+
+	if !accountExists {
+		if taken, err := accountClient.CheckNameExists(ctx, accountName, nil); err != nil {
+			warn("could not check whether the account name %s is available: %v", accountName, err)
+		} else if taken.Success {
+			warn("the account name %s is already in use by another subscription or resource group; account names are globally unique", accountName)
+		}
+
+		count, err := countSubscriptionAccounts(ctx, accountClient)
+		switch {
+		case err != nil:
+			warn("could not count the Cosmos DB accounts in the subscription: %v", err)
+		case count >= maxAccountsPerSubscription:
+			warn("the subscription already has %d Cosmos DB accounts; the default limit is %d per subscription", count, maxAccountsPerSubscription)
+		}
+	}
+
+	locationsClient, err := armcosmos.NewLocationsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db locations client: %v", err)
+	}
+	for _, r := range regions {
+		region, err := locationsClient.Get(ctx, r.Name, nil)
+		switch {
+		case err != nil:
+			warn("could not read Cosmos DB availability for region %s: %v", r.Name, err)
+		case region.Properties != nil:
+			p := region.Properties
+			if p.IsSubscriptionRegionAccessAllowedForRegular != nil && !*p.IsSubscriptionRegionAccessAllowedForRegular {
+				warn("the subscription is not allowed to create Cosmos DB accounts in %s (regional capacity restriction); request access or choose another region", r.Name)
+			}
+			if p.Status != nil && *p.Status != armcosmos.StatusOnline {
+				warn("region %s has status %s", r.Name, *p.Status)
+			}
+		}
+	}
+	for _, err := range checkZoneRedundancySupport(ctx, locationsClient) {
+		warn("%v", err)
+	}
+	if keyVaultKeyURI != "" {
+		for _, err := range checkCMKPrerequisites(ctx) {
+			warn("%v", err)
+		}
+	}
+
+	if warnings == 0 {
+		fmt.Println("Pre-flight checks passed.")
+	}
+	return warnings
+}
+
+// checkAccountExists reports whether the configured account already exists in the resource group.
+func checkAccountExists(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient) (bool, error) {
+	if _, err := accountClient.Get(ctx, resourceGroupName, accountName, nil); err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// countSubscriptionAccounts returns the number of Cosmos DB accounts in the subscription.
+func countSubscriptionAccounts(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient) (int, error) {
+	count := 0
+	pager := accountClient.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(page.Value)
+	}
+	return count, nil
+}
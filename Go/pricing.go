@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	retailPricesEndpoint = "https://prices.azure.com/api/retail/prices"
+	hoursPerMonth        = 730
+
+	// List prices (USD per 100 RU/s per hour, single-region writes) used when the retail prices API is unreachable.
+	fallbackManualPricePer100RU    = 0.008
+	fallbackAutoscalePricePer100RU = 0.012
+	// List price (USD per GB per month) of transactional storage in one region.
+	fallbackStoragePricePerGB = 0.25
+)
+
+// ruPrices are the hourly prices for 100 RU/s of provisioned throughput in one region, and the monthly price of a GB
+// of transactional storage there.
+type ruPrices struct {
+	manualPer100RU    float64
+	autoscalePer100RU float64
+	storagePerGB      float64
+	currency          string
+	source            string
+}
+
+// retailPriceItem is the subset of an Azure retail prices API item used by the estimator.
+type retailPriceItem struct {
+	CurrencyCode  string  `json:"currencyCode"`
+	RetailPrice   float64 `json:"retailPrice"`
+	ArmRegionName string  `json:"armRegionName"`
+	ProductName   string  `json:"productName"`
+	SkuName       string  `json:"skuName"`
+	MeterName     string  `json:"meterName"`
+	UnitOfMeasure string  `json:"unitOfMeasure"`
+	Type          string  `json:"type"`
+}
+
+type retailPricesPage struct {
+	Items        []retailPriceItem `json:"Items"`
+	NextPageLink string            `json:"NextPageLink"`
+}
+
+// throughputCostEstimate is the approximate monthly cost of a throughput configuration.
+type throughputCostEstimate struct {
+	mode       string
+	ru         int
+	regions    int
+	minMonthly float64
+	maxMonthly float64
+	prices     ruPrices
+}
+
+// runCostEstimateCommand prints an approximate monthly cost for the configured (or given) throughput.
+func runCostEstimateCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("cost-estimate")
+	mode := fs.String("mode", "autoscale", "Throughput mode: autoscale or manual")
+	ru := fs.Int("ru", maxAutoScaleThroughput, "Autoscale max RU/s or manual RU/s (default MaxAutoScaleThroughput)")
+	regions := fs.Int("regions", 1, "Number of regions the account is replicated to")
+	_ = fs.Parse(args)
+
+	estimate, err := estimateThroughputCost(ctx, *mode, *ru, *regions)
+	if err != nil {
+		log.Fatalf("failed to estimate cost: %v", err)
+	}
+	printThroughputCostEstimate(estimate)
+}
+
+// estimateThroughputCost prices a throughput configuration using the retail prices for the configured location.
+func estimateThroughputCost(ctx context.Context, mode string, ru int, regions int) (throughputCostEstimate, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode != "autoscale" && mode != "manual" {
+		return throughputCostEstimate{}, fmt.Errorf("invalid mode %q (expected autoscale or manual)", mode)
+	}
+	if ru <= 0 || regions <= 0 {
+		return throughputCostEstimate{}, fmt.Errorf("RU/s and region count must be positive (got %d RU/s, %d regions)", ru, regions)
+	}
+
+	prices := getRUPricesBestEffort(ctx, location)
+	estimate := throughputCostEstimate{mode: mode, ru: ru, regions: regions, prices: prices}
+
+	units := float64(ru) / 100 * float64(regions) * hoursPerMonth
+	if mode == "manual" {
+		estimate.minMonthly = units * prices.manualPer100RU
+		estimate.maxMonthly = estimate.minMonthly
+	} else {
+		// Autoscale bills the highest RU/s reached each hour, which is never below 10% of the max.
+		estimate.minMonthly = units * 0.1 * prices.autoscalePer100RU
+		estimate.maxMonthly = units * prices.autoscalePer100RU
+	}
+
+	return estimate, nil
+}
+
+// printThroughputCostEstimate prints a cost estimate in a short human-readable form.
+func printThroughputCostEstimate(e throughputCostEstimate) {
+	fmt.Printf("Estimated monthly throughput cost (%s, %d RU/s, %d region(s), prices from %s):\n", e.mode, e.ru, e.regions, e.prices.source)
+	if e.mode == "manual" {
+		fmt.Printf("  ~%.2f %s/month\n", e.maxMonthly, e.prices.currency)
+	} else {
+		fmt.Printf("  %.2f %s/month when idle (10%% of max) up to %.2f %s/month at max RU/s every hour\n", e.minMonthly, e.prices.currency, e.maxMonthly, e.prices.currency)
+	}
+	fmt.Println("  Storage, backup, and multi-region write charges are not included.")
+}
+
+// printConfiguredThroughputCostBestEffort prints the cost of the configured autoscale max without failing the run.
+func printConfiguredThroughputCostBestEffort(ctx context.Context) {
+	estimate, err := estimateThroughputCost(ctx, "autoscale", maxAutoScaleThroughput, 1)
+	if err != nil {
+		log.Printf("Skipping cost estimate: %v", err)
+		return
+	}
+	printThroughputCostEstimate(estimate)
+}
+
+// getRUPricesBestEffort returns retail RU/s prices for a region, or list prices if the API can't be reached.
+func getRUPricesBestEffort(ctx context.Context, region string) ruPrices {
+	prices, err := getRUPrices(ctx, region)
+	if err != nil {
+		log.Printf("Retail prices API unavailable, using list prices: %v", err)
+		return ruPrices{
+			manualPer100RU:    fallbackManualPricePer100RU,
+			autoscalePer100RU: fallbackAutoscalePricePer100RU,
+			storagePerGB:      fallbackStoragePricePerGB,
+			currency:          "USD",
+			source:            "built-in list prices",
+		}
+	}
+	return prices
+}
+
+// getRUPrices reads the Cosmos DB provisioned and autoscale throughput meters from the Azure retail prices API.
+func getRUPrices(ctx context.Context, region string) (ruPrices, error) {
+	armRegion := strings.ToLower(strings.ReplaceAll(region, " ", ""))
+	filter := fmt.Sprintf("serviceName eq 'Azure Cosmos DB' and armRegionName eq '%s' and priceType eq 'Consumption'", armRegion)
+	next := retailPricesEndpoint + "?$filter=" + url.QueryEscape(filter)
+
+	prices := ruPrices{currency: "USD", source: "Azure retail prices API (" + armRegion + ")"}
+	for next != "" {
+		page, err := getRetailPricesPage(ctx, next)
+		if err != nil {
+			return ruPrices{}, err
+		}
+		for _, item := range page.Items {
+			if item.MeterName == "Data Stored" && strings.Contains(item.UnitOfMeasure, "GB") && prices.storagePerGB == 0 {
+				product := strings.ToLower(item.ProductName + " " + item.SkuName)
+				if !strings.Contains(product, "analytical") && !strings.Contains(product, "backup") {
+					prices.storagePerGB = item.RetailPrice
+				}
+				continue
+			}
+			if item.MeterName != "100 RU/s" || !strings.Contains(strings.ToLower(item.UnitOfMeasure), "hour") {
+				continue
+			}
+			product := strings.ToLower(item.ProductName + " " + item.SkuName)
+			if strings.Contains(product, "multi") || strings.Contains(product, "reserved") {
+				continue
+			}
+			prices.currency = item.CurrencyCode
+			if strings.Contains(product, "autoscale") {
+				prices.autoscalePer100RU = item.RetailPrice
+			} else if prices.manualPer100RU == 0 {
+				prices.manualPer100RU = item.RetailPrice
+			}
+		}
+		next = page.NextPageLink
+	}
+
+	if prices.manualPer100RU == 0 {
+		return ruPrices{}, fmt.Errorf("no provisioned throughput meter found for region %q", armRegion)
+	}
+	if prices.autoscalePer100RU == 0 {
+		// Autoscale is priced at 1.5x the standard provisioned throughput rate.
+		prices.autoscalePer100RU = prices.manualPer100RU * 1.5
+	}
+	if prices.storagePerGB == 0 {
+		prices.storagePerGB = fallbackStoragePricePerGB
+	}
+	return prices, nil
+}
+
+func getRetailPricesPage(ctx context.Context, pageURL string) (retailPricesPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return retailPricesPage{}, fmt.Errorf("failed to build retail prices request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return retailPricesPage{}, fmt.Errorf("failed to call retail prices API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return retailPricesPage{}, fmt.Errorf("retail prices API returned status %d", resp.StatusCode)
+	}
+
+	var page retailPricesPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return retailPricesPage{}, fmt.Errorf("failed to parse retail prices response: %w", err)
+	}
+	return page, nil
+}
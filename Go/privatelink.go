@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runPrivateLinkCommand shows what a private endpoint for the account needs, and lists, approves, or rejects the
+// private endpoint connections made to it.
+func runPrivateLinkCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("private-link")
+	pendingOnly := fs.Bool("pending", false, "connections: only list connections waiting for approval")
+	description := fs.String("description", "", "approve, reject: justification recorded on the connection (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: private-link [resources] | private-link [-pending] connections | private-link -description <text> (approve | reject) <connection name>")
+		fmt.Fprintln(fs.Output(), "resources (default) lists the account's private link group IDs with their required members and DNS zone names.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "", "resources":
+		listPrivateLinkResources(ctx)
+	case "connections":
+		listPrivateEndpointConnections(ctx, *pendingOnly)
+	case "approve", "reject":
+		if fs.NArg() != 2 || strings.TrimSpace(*description) == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		setPrivateEndpointConnectionStatus(ctx, fs.Arg(1), fs.Arg(0) == "approve", *description)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// listPrivateLinkResources prints each private link group ID the account exposes (Sql, MongoDB, Analytical, ...).
+func listPrivateLinkResources(ctx context.Context) {
+	resources, err := getPrivateLinkResources(ctx)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(resources) == 0 {
+		fmt.Println("The account exposes no private link resources.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP ID\tREQUIRED MEMBERS\tDNS ZONES")
+	for _, r := range resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", stringValue(r.GroupID), orDash(joinStrings(r.RequiredMembers)), orDash(joinStrings(r.RequiredZoneNames)))
+	}
+	_ = tw.Flush()
+}
+
+// getPrivateLinkResources returns the group IDs a private endpoint to the account can target, with the member names
+// and private DNS zones each one needs. The zone names depend on the cloud, so read them here rather than hardcoding
+// privatelink.documents.azure.com.
+func getPrivateLinkResources(ctx context.Context) ([]*armcosmos.PrivateLinkResourceProperties, error) {
+	client, err := armcosmos.NewPrivateLinkResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create private link resources client: %w", err)
+	}
+
+	resources := make([]*armcosmos.PrivateLinkResourceProperties, 0)
+	pager := client.NewListByDatabaseAccountPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list private link resources: %w", err)
+		}
+		for _, r := range page.Value {
+			if r != nil && r.Properties != nil {
+				resources = append(resources, r.Properties)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// listPrivateEndpointConnections prints the private endpoints connected to the account and their approval state.
+// Endpoints created by someone without rights on the account (for example, from another tenant) start out Pending.
+func listPrivateEndpointConnections(ctx context.Context, pendingOnly bool) {
+	client, err := armcosmos.NewPrivateEndpointConnectionsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create private endpoint connections client: %v", err)
+	}
+
+	connections := make([]*armcosmos.PrivateEndpointConnection, 0)
+	pager := client.NewListByDatabaseAccountPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list private endpoint connections: %v", err)
+		}
+		for _, c := range page.Value {
+			if c == nil || c.Properties == nil {
+				continue
+			}
+			if pendingOnly && privateEndpointConnectionStatus(c) != "Pending" {
+				continue
+			}
+			connections = append(connections, c)
+		}
+	}
+	if len(connections) == 0 {
+		if pendingOnly {
+			fmt.Println("No private endpoint connections are waiting for approval.")
+		} else {
+			fmt.Println("No private endpoint connections on the account.")
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tGROUP ID\tSTATUS\tDESCRIPTION\tPRIVATE ENDPOINT")
+	for _, c := range connections {
+		description := ""
+		if state := c.Properties.PrivateLinkServiceConnectionState; state != nil {
+			description = stringValue(state.Description)
+		}
+		endpoint := ""
+		if c.Properties.PrivateEndpoint != nil {
+			endpoint = stringValue(c.Properties.PrivateEndpoint.ID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", stringValue(c.Name), orDash(stringValue(c.Properties.GroupID)), privateEndpointConnectionStatus(c), orDash(description), orDash(endpoint))
+	}
+	_ = tw.Flush()
+}
+
+// setPrivateEndpointConnectionStatus approves or rejects a private endpoint connection. Only pending connections can be
+// approved; a rejected connection can't be approved again, and the endpoint owner has to recreate it.
+func setPrivateEndpointConnectionStatus(ctx context.Context, name string, approve bool, description string) {
+	client, err := armcosmos.NewPrivateEndpointConnectionsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create private endpoint connections client: %v", err)
+	}
+
+	current, err := client.Get(ctx, resourceGroupName, accountName, name, nil)
+	if err != nil {
+		log.Fatalf("failed to get private endpoint connection %s: %v", name, err)
+	}
+	connection := current.PrivateEndpointConnection
+	if connection.Properties == nil {
+		connection.Properties = &armcosmos.PrivateEndpointConnectionProperties{}
+	}
+
+	status, want := privateEndpointConnectionStatus(&connection), "Rejected"
+	if approve {
+		want = "Approved"
+	}
+	switch {
+	case status == want:
+		fmt.Printf("Private endpoint connection %s is already %s.\n", name, want)
+		return
+	case approve && status != "Pending":
+		log.Fatalf("private endpoint connection %s is %s; only pending connections can be approved", name, status)
+	case !approve && status != "Pending" && status != "Approved":
+		log.Fatalf("private endpoint connection %s is %s and can't be rejected", name, status)
+	}
+
+	connection.Properties.PrivateLinkServiceConnectionState = &armcosmos.PrivateLinkServiceConnectionStateProperty{
+		Status:      to.Ptr(want),
+		Description: to.Ptr(description),
+	}
+	// ProvisioningState is read-only; leave it out of the request.
+	connection.Properties.ProvisioningState = nil
+
+	poller, err := client.BeginCreateOrUpdate(ctx, resourceGroupName, accountName, name, connection, nil)
+	if err != nil {
+		log.Fatalf("failed to update private endpoint connection %s: %v", name, err)
+	}
+	if _, err := pollUntilDone(ctx, poller); err != nil {
+		log.Fatalf("failed to update private endpoint connection %s: %v", name, err)
+	}
+	fmt.Printf("Private endpoint connection %s: %s -> %s (%s)\n", name, status, want, description)
+}
+
+func privateEndpointConnectionStatus(c *armcosmos.PrivateEndpointConnection) string {
+	if c.Properties == nil || c.Properties.PrivateLinkServiceConnectionState == nil || c.Properties.PrivateLinkServiceConnectionState.Status == nil {
+		return "Unknown"
+	}
+	return *c.Properties.PrivateLinkServiceConnectionState.Status
+}
+
+func joinStrings(values []*string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			parts = append(parts, *v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/viper"
+)
+
+// promotionOverrideSettings are the settings a target profile may change when promote applies it over the source
+// spec: names, throughput, regions, and tags. Everything else (databases, containers, indexing, consistency, ...)
+// comes from the spec, so what was tested is what ships.
+var promotionOverrideSettings = []string{
+	"SubscriptionId",
+	"ResourceGroupName",
+	"AccountName",
+	"LogAnalyticsWorkspaceName",
+	"FleetName",
+	"AlertEmailAddress",
+	"MaxAutoScaleThroughput",
+	"ThroughputBudget",
+	"ThroughputSchedule",
+	"BudgetAmount",
+	"Location",
+	"Regions",
+	"Tags",
+}
+
+// promoteProfile is the target profile of a running promote command, applied over -profile with only the
+// promotionOverrideSettings.
+var promoteProfile string
+
+// applyProfiles applies the -profile entry of the Profiles setting over config.json, then, during promote, the
+// target profile's overrides.
+func applyProfiles() error {
+	if *profileName != "" {
+		settings, err := profileSettings(*profileName)
+		if err != nil {
+			return err
+		}
+		for key, value := range settings {
+			viper.Set(key, value)
+		}
+	}
+	if promoteProfile == "" {
+		return nil
+	}
+	settings, err := profileSettings(promoteProfile)
+	if err != nil {
+		return err
+	}
+	if unsupported := unsupportedOverrides(settings); len(unsupported) > 0 {
+		return fmt.Errorf("profile %s sets %s, which promote doesn't override; only %s can differ from the spec", promoteProfile, strings.Join(unsupported, ", "), strings.Join(promotionOverrideSettings, ", "))
+	}
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	return nil
+}
+
+// profileSettings returns the settings of one entry of Profiles. Keys are lower case, as viper stores them.
+func profileSettings(name string) (map[string]any, error) {
+	profiles := viper.GetStringMap("Profiles")
+	raw, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		available := make([]string, 0, len(profiles))
+		for n := range profiles {
+			available = append(available, n)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("no profile %q in Profiles (found: %s)", name, strings.Join(available, ", "))
+	}
+	settings, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("Profiles.%s must be an object of settings", name)
+	}
+	if _, ok := settings["profiles"]; ok {
+		return nil, fmt.Errorf("Profiles.%s can't contain Profiles", name)
+	}
+	return settings, nil
+}
+
+// unsupportedOverrides returns the settings, sorted, that are not in promotionOverrideSettings.
+func unsupportedOverrides(settings map[string]any) []string {
+	allowed := map[string]bool{}
+	for _, key := range promotionOverrideSettings {
+		allowed[strings.ToLower(key)] = true
+	}
+	var unsupported []string
+	for key := range settings {
+		if !allowed[key] {
+			unsupported = append(unsupported, key)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// runPromoteCommand provisions the spec of the current configuration (config.json with -profile) as the target
+// profile, which may change only the promotionOverrideSettings, and then runs the full sample against it.
+func runPromoteCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("promote")
+	target := fs.String("to", "", "Profile to promote to, for example prod (required)")
+	yes := fs.Bool("yes", false, "Provision or update the target; without it, promote only prints the plan")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: [-profile <source>] promote -to <profile> [-yes]")
+		fmt.Fprintln(fs.Output(), "Provisions the source profile's spec under the target profile's names, throughput, regions, and tags.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *target == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if strings.EqualFold(*target, *profileName) {
+		log.Fatalf("-to must be a different profile than -profile %s", *profileName)
+	}
+	source := *profileName
+	if source == "" {
+		source = "config.json"
+	}
+
+	before := promotionSettingValues()
+	sourceAccount, sourceGroup, sourceSubscription := accountName, resourceGroupName, subscriptionID
+	promoteProfile = *target
+	loadConfiguration()
+	if accountName == "" {
+		log.Fatalf("Profile %s must set AccountName", *target)
+	}
+	if accountName == sourceAccount && resourceGroupName == sourceGroup && subscriptionID == sourceSubscription {
+		log.Fatalf("Profile %s uses the same account as %s (%s); set its AccountName or ResourceGroupName", *target, source, accountName)
+	}
+
+	after := promotionSettingValues()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "SETTING\t%s\t%s\n", strings.ToUpper(source), strings.ToUpper(*target))
+	for _, key := range promotionOverrideSettings {
+		if before[key] != after[key] {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", key, orDash(before[key]), orDash(after[key]))
+		}
+	}
+	_ = tw.Flush()
+	var resources []string
+	for _, db := range databases {
+		for _, c := range db.Containers {
+			resources = append(resources, db.Name+"/"+c.Name)
+		}
+	}
+	fmt.Printf("Spec from %s: %d database(s), containers %s.\n", source, len(databases), strings.Join(resources, ", "))
+
+	if !*yes {
+		fmt.Printf("Would provision or update %s/%s with this spec. Run with -yes to apply.\n", resourceGroupName, accountName)
+		return
+	}
+	log.Printf("Promoting %s to %s (%s/%s)", source, *target, resourceGroupName, accountName)
+	runFullSample(ctx)
+}
+
+// promotionSettingValues returns the current value of each promotion override setting, as compact JSON.
+func promotionSettingValues() map[string]string {
+	values := make(map[string]string, len(promotionOverrideSettings))
+	for _, key := range promotionOverrideSettings {
+		if !viper.IsSet(key) {
+			continue
+		}
+		if fromKeyVault(key) {
+			values[key] = "(from Key Vault)"
+			continue
+		}
+		data, err := json.Marshal(viper.Get(key))
+		if err != nil {
+			values[key] = fmt.Sprint(viper.Get(key))
+			continue
+		}
+		values[key] = strings.Trim(string(data), `"`)
+	}
+	return values
+}
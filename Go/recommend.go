@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// autoscaleBreakEvenUtilization is the average share of its peak a container must use for manual throughput to cost
+// less than autoscale, which bills 1.5x the manual rate but only for the RU/s it scaled to each hour.
+const autoscaleBreakEvenUtilization = 1 / 1.5
+
+// throughputRecommendation is the suggested throughput for one container and the data it is based on.
+type throughputRecommendation struct {
+	container      string
+	currentRU      int
+	autoscale      bool
+	minimumRU      int
+	hours          int
+	saturatedHours int
+	averageRU      float64
+	p99RU          float64
+	peakRU         float64
+	recommendedRU  int
+	recommendAuto  bool
+	confidence     string
+	reasons        []string
+}
+
+// runRecommendThroughputCommand suggests an autoscale max or manual RU/s for each container with dedicated
+// throughput in the account, from its hourly NormalizedRUConsumption over the last -days days.
+func runRecommendThroughputCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("recommend-throughput")
+	days := fs.Int("days", 14, "How many days of RU consumption to analyze (1-30; 7 or more covers weekly peaks)")
+	headroom := fs.Int("headroom", 20, "Percent added on top of the 99th percentile hourly peak")
+	report := addReportFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: recommend-throughput [-days 14] [-headroom 20]")
+		fmt.Fprintf(fs.Output(), "Suggests throughput for each container in %s with its own throughput.\n", accountName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *days < 1 || *days > 30 {
+		log.Fatalf("-days must be between 1 and 30 (got %d)", *days)
+	}
+	if *headroom < 0 || *headroom > 200 {
+		log.Fatalf("-headroom must be between 0 and 200 percent (got %d)", *headroom)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+	resources, err := listSQLInventory(ctx, sqlClient, resourceGroupName, accountName)
+	if err != nil {
+		log.Fatalf("failed to list containers: %v", err)
+	}
+
+	window := time.Duration(*days) * 24 * time.Hour
+	var recommendations []throughputRecommendation
+	var shared []string
+	for _, r := range resources {
+		if r.kind != "Container" {
+			continue
+		}
+		currentRU, autoscale, minimumRU, ok := provisionedThroughput(r.throughput)
+		if !ok {
+			shared = append(shared, r.name)
+			continue
+		}
+		database, container, _ := strings.Cut(r.name, "/")
+		hourlyPeaks, saturatedHours, err := readHourlyPeaks(ctx, database, container, currentRU, window)
+		if err != nil {
+			log.Printf("Could not read RU consumption for %s: %v", r.name, err)
+			continue
+		}
+		rec := recommendThroughput(hourlyPeaks, float64(100+*headroom)/100, *days*24)
+		rec.container, rec.currentRU, rec.autoscale, rec.minimumRU, rec.saturatedHours = r.name, currentRU, autoscale, minimumRU, saturatedHours
+		rec.finish()
+		recommendations = append(recommendations, rec)
+	}
+
+	table := reportTable{
+		title:   fmt.Sprintf("Throughput recommendations from the last %d day(s) of hourly NormalizedRUConsumption (+%d%% headroom)", *days, *headroom),
+		headers: []string{"CONTAINER", "CURRENT", "HOURS", "SATURATED", "AVG RU/S", "P99 RU/S", "PEAK RU/S", "RECOMMENDED", "CONFIDENCE"},
+	}
+	for _, rec := range recommendations {
+		if rec.hours == 0 {
+			table.addRow(rec.container, throughputLabel(rec.currentRU, rec.autoscale), "0", "-", "-", "-", "-", "-", rec.confidence)
+		} else {
+			table.addRow(rec.container, throughputLabel(rec.currentRU, rec.autoscale), fmt.Sprintf("%d/%d", rec.hours, *days*24), strconv.Itoa(rec.saturatedHours),
+				fmt.Sprintf("%.0f", rec.averageRU), fmt.Sprintf("%.0f", rec.p99RU), fmt.Sprintf("%.0f", rec.peakRU),
+				throughputLabel(rec.recommendedRU, rec.recommendAuto), rec.confidence)
+		}
+		for _, reason := range rec.reasons {
+			table.addNote("%s: %s", rec.container, reason)
+		}
+	}
+	if len(shared) > 0 {
+		table.addNote("Skipped %d container(s) without their own throughput (shared database throughput or serverless): %s", len(shared), strings.Join(shared, ", "))
+	}
+	table.addNote("RU/s per hour is the busiest partition's NormalizedRUConsumption times the current throughput; if throughput changed during the window, those hours are scaled by today's value.")
+	table.addNote("Autoscale is suggested when the average hourly peak is below %.0f%% of the P99, where it costs less than manual.", autoscaleBreakEvenUtilization*100)
+	if err := writeReport(report, "Throughput recommendations for "+accountName, table); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// provisionedThroughput returns the autoscale max (and true) or manual RU/s in a throughput resource and the minimum
+// the service allows it to be set to; ok is false when the resource has no dedicated throughput.
+func provisionedThroughput(properties *armcosmos.ThroughputSettingsGetProperties) (ru int, autoscale bool, minimum int, ok bool) {
+	if properties == nil || properties.Resource == nil {
+		return 0, false, 0, false
+	}
+	r := properties.Resource
+	if r.MinimumThroughput != nil {
+		minimum, _ = strconv.Atoi(*r.MinimumThroughput)
+	}
+	if r.AutoscaleSettings != nil && r.AutoscaleSettings.MaxThroughput != nil {
+		return int(*r.AutoscaleSettings.MaxThroughput), true, minimum, true
+	}
+	if r.Throughput != nil {
+		return int(*r.Throughput), false, minimum, true
+	}
+	return 0, false, 0, false
+}
+
+// readHourlyPeaks returns the RU/s the container needed in each hour of the window, and how many of those hours its
+// busiest partition was at 100% (so the real demand was higher than what could be measured).
+func readHourlyPeaks(ctx context.Context, database string, container string, provisionedRU int, window time.Duration) ([]float64, int, error) {
+	metric, err := queryAccountMetric(ctx, metricQuery{name: "NormalizedRUConsumption", aggregation: "Maximum"}, containerMetricFilter(database, container), window, time.Hour)
+	if err != nil {
+		return nil, 0, err
+	}
+	var hourlyPeaks []float64
+	saturated := 0
+	for _, series := range metric.Timeseries {
+		if series == nil {
+			continue
+		}
+		for _, point := range series.Data {
+			value, ok := metricValue(point, "Maximum")
+			if !ok {
+				continue
+			}
+			if value >= 100 {
+				saturated++
+			}
+			hourlyPeaks = append(hourlyPeaks, value/100*float64(provisionedRU))
+		}
+	}
+	return hourlyPeaks, saturated, nil
+}
+
+// recommendThroughput sizes throughput for the 99th percentile hourly peak times headroom, rounded up to a value the
+// service accepts, and picks autoscale or manual by how far the average hour sits below that peak.
+func recommendThroughput(hourlyPeaks []float64, headroom float64, expectedHours int) throughputRecommendation {
+	rec := throughputRecommendation{hours: len(hourlyPeaks)}
+	if rec.hours == 0 {
+		rec.confidence = "none"
+		rec.reasons = append(rec.reasons, "no RU consumption data in the window; run some traffic first")
+		return rec
+	}
+
+	sorted := append([]float64(nil), hourlyPeaks...)
+	sort.Float64s(sorted)
+	for _, peak := range sorted {
+		rec.averageRU += peak
+	}
+	rec.averageRU /= float64(rec.hours)
+	rec.p99RU = sorted[int(math.Ceil(0.99*float64(rec.hours)))-1]
+	rec.peakRU = sorted[rec.hours-1]
+
+	target := rec.p99RU * headroom
+	rec.recommendAuto = rec.p99RU == 0 || rec.averageRU/rec.p99RU < autoscaleBreakEvenUtilization
+	if rec.recommendAuto {
+		rec.recommendedRU = max(minAutoscaleMaxThroughput, int(math.Ceil(target/1000))*1000)
+	} else {
+		rec.recommendedRU = max(minManualThroughput, int(math.Ceil(target/100))*100)
+	}
+	rec.recommendedRU = min(rec.recommendedRU, maxContainerThroughput)
+
+	coverage := float64(rec.hours) / float64(expectedHours)
+	switch {
+	case rec.hours < 24 || coverage < 0.5:
+		rec.confidence = "low"
+	case rec.hours >= 7*24 && coverage >= 0.9:
+		rec.confidence = "high"
+	default:
+		rec.confidence = "medium"
+	}
+	if coverage < 0.9 {
+		rec.reasons = append(rec.reasons, fmt.Sprintf("only %d of %d hours have data", rec.hours, expectedHours))
+	}
+	if rec.hours < 7*24 {
+		rec.reasons = append(rec.reasons, "less than a week of data may miss weekly peaks")
+	}
+	return rec
+}
+
+// finish applies what is known about the container itself: saturated hours hide the real demand, so the
+// recommendation never drops below the current throughput then, and manual throughput never below the minimum the
+// service reports for the container.
+func (rec *throughputRecommendation) finish() {
+	if rec.hours == 0 {
+		return
+	}
+	if rec.saturatedHours > 0 {
+		rec.reasons = append(rec.reasons, fmt.Sprintf("the busiest partition was at 100%% in %d hour(s), so real demand was higher than measured", rec.saturatedHours))
+		if rec.recommendedRU < rec.currentRU {
+			rec.recommendedRU = rec.currentRU
+			rec.reasons = append(rec.reasons, "keeping the current throughput instead of scaling down")
+		}
+		if float64(rec.saturatedHours) > 0.05*float64(rec.hours) {
+			rec.confidence = "low"
+		} else if rec.confidence == "high" {
+			rec.confidence = "medium"
+		}
+	}
+	if !rec.recommendAuto && rec.recommendedRU < rec.minimumRU {
+		rec.recommendedRU = rec.minimumRU
+		rec.reasons = append(rec.reasons, fmt.Sprintf("raised to the minimum of %d RU/s the service allows for this container", rec.minimumRU))
+	}
+	if rec.recommendedRU == rec.currentRU && rec.recommendAuto == rec.autoscale {
+		rec.reasons = append(rec.reasons, "the current throughput already fits")
+	}
+}
+
+// throughputLabel formats RU/s with its mode, for example "autoscale 4000" or "manual 400".
+func throughputLabel(ru int, autoscale bool) string {
+	if autoscale {
+		return fmt.Sprintf("autoscale %d", ru)
+	}
+	return fmt.Sprintf("manual %d", ru)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+	"github.com/spf13/viper"
+)
+
+// regionConfig is one entry of the Regions setting: a region the account is replicated to.
+type regionConfig struct {
+	Name             string
+	IsZoneRedundant  bool
+	FailoverPriority *int32
+}
+
+// loadRegionConfigs reads the Regions setting. Without it, the account has a single region, Location, without zone
+// redundancy. Regions without a FailoverPriority get their position in the list, so the first entry is the write
+// region.
+func loadRegionConfigs() error {
+	if !viper.IsSet("Regions") {
+		regions = []regionConfig{{Name: location, FailoverPriority: to.Ptr[int32](0)}}
+		return nil
+	}
+
+	if err := viper.UnmarshalKey("Regions", &regions); err != nil {
+		return fmt.Errorf("failed to read Regions: %w", err)
+	}
+	if len(regions) == 0 {
+		return fmt.Errorf("Regions must contain at least one region")
+	}
+	seen := map[string]bool{}
+	for i := range regions {
+		r := &regions[i]
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			return fmt.Errorf("Regions[%d]: Name is required", i)
+		}
+		if seen[normalizeRegion(r.Name)] {
+			return fmt.Errorf("Regions[%d]: duplicate region %q", i, r.Name)
+		}
+		seen[normalizeRegion(r.Name)] = true
+	}
+	return validateFailoverPriorities()
+}
+
+// validateFailoverPriorities checks that FailoverPriority is set on all regions or none, and that the priorities are
+// 0 (the write region) through len(Regions)-1 with no duplicates or gaps, which is what ARM requires.
+func validateFailoverPriorities() error {
+	set := 0
+	for _, r := range regions {
+		if r.FailoverPriority != nil {
+			set++
+		}
+	}
+	if set == 0 {
+		for i := range regions {
+			regions[i].FailoverPriority = to.Ptr(int32(i))
+		}
+		return nil
+	}
+	if set != len(regions) {
+		return fmt.Errorf("set FailoverPriority on every region or on none")
+	}
+
+	byPriority := make([]string, len(regions))
+	for i, r := range regions {
+		p := *r.FailoverPriority
+		if p < 0 || int(p) >= len(regions) {
+			return fmt.Errorf("Regions[%d]: FailoverPriority must be between 0 and %d (got %d); priorities must be contiguous from 0", i, len(regions)-1, p)
+		}
+		if byPriority[p] != "" {
+			return fmt.Errorf("Regions[%d]: FailoverPriority %d is also used by %s; priorities must be unique", i, p, byPriority[p])
+		}
+		byPriority[p] = r.Name
+	}
+	return nil
+}
+
+// accountLocations returns the configured regions as account locations.
+func accountLocations() []*armcosmos.Location {
+	locations := make([]*armcosmos.Location, 0, len(regions))
+	for _, r := range regions {
+		locations = append(locations, &armcosmos.Location{
+			LocationName:     to.Ptr(r.Name),
+			FailoverPriority: to.Ptr(*r.FailoverPriority),
+			IsZoneRedundant:  to.Ptr(r.IsZoneRedundant),
+		})
+	}
+	return locations
+}
+
+// checkZoneRedundancySupport returns an error for each region configured with IsZoneRedundant that doesn't support
+// availability zones for Cosmos DB, which ARM would otherwise reject only after the account update starts.
+func checkZoneRedundancySupport(ctx context.Context, locationsClient *armcosmos.LocationsClient) []error {
+	problems := make([]error, 0)
+	for _, r := range regions {
+		if !r.IsZoneRedundant {
+			continue
+		}
+		resp, err := locationsClient.Get(ctx, r.Name, nil)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("could not check availability zone support in %s: %w", r.Name, err))
+			continue
+		}
+		if resp.Properties == nil || resp.Properties.SupportsAvailabilityZone == nil || !*resp.Properties.SupportsAvailabilityZone {
+			problems = append(problems, fmt.Errorf("region %s does not support availability zones for Cosmos DB; set IsZoneRedundant to false for it", r.Name))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// reportTable is one table of a command's output, kept as text cells so it can be rendered to the console, CSV, or
+// HTML.
+type reportTable struct {
+	title   string
+	headers []string
+	rows    [][]string
+	// notes are printed below the table on the console and in HTML; CSV carries only the data.
+	notes []string
+}
+
+func (t *reportTable) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *reportTable) addNote(format string, a ...any) {
+	t.notes = append(t.notes, fmt.Sprintf(format, a...))
+}
+
+// reportOptions are the -format and -out flags shared by the commands that print reports.
+type reportOptions struct {
+	format *string
+	out    *string
+}
+
+// addReportFlags registers -format and -out on a command's flag set.
+func addReportFlags(fs *flag.FlagSet) reportOptions {
+	return reportOptions{
+		format: fs.String("format", "text", "Output format: text, csv, or html"),
+		out:    fs.String("out", "", "Write the report to this file instead of stdout"),
+	}
+}
+
+// validate checks -format, so a bad value fails before the command makes any calls.
+func (o reportOptions) validate() error {
+	switch strings.ToLower(*o.format) {
+	case "text", "csv", "html":
+		return nil
+	}
+	return fmt.Errorf("invalid -format %q (expected text, csv, or html)", *o.format)
+}
+
+// writeReport renders the tables in the chosen format to stdout or the -out file.
+func writeReport(o reportOptions, title string, tables ...reportTable) error {
+	w := io.Writer(os.Stdout)
+	if *o.out != "" {
+		f, err := os.Create(*o.out)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var err error
+	switch strings.ToLower(*o.format) {
+	case "csv":
+		err = writeCSVReport(w, tables)
+	case "html":
+		err = writeHTMLReport(w, title, tables)
+	default:
+		err = writeTextReport(w, tables)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if *o.out != "" {
+		fmt.Printf("Wrote %s report: %s\n", strings.ToLower(*o.format), *o.out)
+	}
+	return nil
+}
+
+// writeTextReport prints each table with its title and notes, as the commands print to the console.
+func writeTextReport(w io.Writer, tables []reportTable) error {
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if t.title != "" {
+			fmt.Fprintln(w, t.title)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		for _, note := range t.notes {
+			fmt.Fprintln(w, note)
+		}
+	}
+	return nil
+}
+
+// writeCSVReport writes each table's header and rows, with an empty line between tables.
+func writeCSVReport(w io.Writer, tables []reportTable) error {
+	cw := csv.NewWriter(w)
+	for i, t := range tables {
+		if i > 0 {
+			if err := cw.Write(nil); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(t.headers); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// htmlReportTemplate is a self-contained page, so the file can be mailed or opened without the sample.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Segoe UI, Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 0.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+tr:nth-child(even) td { background: #fafafa; }
+p.meta, p.note { color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Subscription {{.Subscription}}, generated {{.Generated}}</p>
+{{range .Tables}}
+{{if .Title}}<h2>{{.Title}}</h2>{{end}}
+<table>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{range .Notes}}<p class="note">{{.}}</p>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// writeHTMLReport writes the tables as a single HTML page.
+func writeHTMLReport(w io.Writer, title string, tables []reportTable) error {
+	type htmlTable struct {
+		Title   string
+		Headers []string
+		Rows    [][]string
+		Notes   []string
+	}
+	page := struct {
+		Title        string
+		Subscription string
+		Generated    string
+		Tables       []htmlTable
+	}{
+		Title:        title,
+		Subscription: subscriptionID,
+		Generated:    time.Now().UTC().Format(time.RFC1123),
+	}
+	for _, t := range tables {
+		page.Tables = append(page.Tables, htmlTable{Title: t.title, Headers: t.headers, Rows: t.rows, Notes: t.notes})
+	}
+	return htmlReportTemplate.Execute(w, page)
+}
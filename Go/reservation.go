@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/reservations/armreservations"
+)
+
+// runReservedCapacityCommand quotes, and with -yes purchases, Cosmos DB reserved capacity sized to the account's
+// provisioned throughput.
+func runReservedCapacityCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("reserved-capacity")
+	ru := fs.Int("ru", 0, "RU/s to reserve across all regions; default: the account's provisioned throughput")
+	term := fs.String("term", "P1Y", "Reservation term: P1Y or P3Y")
+	billingPlan := fs.String("billing-plan", "Upfront", "Billing plan: Upfront or Monthly")
+	shared := fs.Bool("shared", false, "Apply the reservation to all subscriptions in the billing context instead of this subscription")
+	sku := fs.String("sku", "Cosmos_DB_100_RU", "Reservation SKU; the quantity is in units of this SKU's RU/s")
+	skuRU := fs.Int("sku-ru", 100, "RU/s per unit of -sku")
+	yes := fs.Bool("yes", false, "Purchase the reservation; without it, only print the price quote")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: reserved-capacity [-ru <RU/s>] [-term P1Y|P3Y] [-billing-plan Upfront|Monthly] [-shared] [-yes]")
+		fmt.Fprintln(fs.Output(), "Prints a price quote for reserved capacity matching the account's provisioned throughput. -yes purchases it.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if (*term != "P1Y" && *term != "P3Y") || (*billingPlan != "Upfront" && *billingPlan != "Monthly") || *skuRU <= 0 || *ru < 0 || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *ru == 0 {
+		total, err := getReservableThroughput(ctx)
+		if err != nil {
+			log.Fatalf("failed to read provisioned throughput: %v", err)
+		}
+		*ru = total
+	}
+	// Round down: RU/s above the reservation are billed at the regular rate, while unused reserved RU/s are wasted.
+	quantity := *ru / *skuRU
+	if quantity == 0 {
+		log.Fatalf("%d RU/s is less than one %s unit (%d RU/s); nothing to reserve", *ru, *sku, *skuRU)
+	}
+
+	request := armreservations.PurchaseRequest{
+		SKU: &armreservations.SKUName{Name: sku},
+		Properties: &armreservations.PurchaseRequestProperties{
+			ReservedResourceType: to.Ptr(armreservations.ReservedResourceTypeCosmosDb),
+			BillingScopeID:       to.Ptr(getAssignableScope(Subscription)),
+			Term:                 to.Ptr(armreservations.ReservationTerm(*term)),
+			BillingPlan:          to.Ptr(armreservations.ReservationBillingPlan(*billingPlan)),
+			Quantity:             to.Ptr(int32(quantity)),
+			DisplayName:          to.Ptr(fmt.Sprintf("%s-%d-RU", accountName, quantity**skuRU)),
+			AppliedScopeType:     to.Ptr(armreservations.AppliedScopeTypeSingle),
+			AppliedScopes:        []*string{to.Ptr(getAssignableScope(Subscription))},
+		},
+	}
+	if *shared {
+		request.Properties.AppliedScopeType = to.Ptr(armreservations.AppliedScopeTypeShared)
+		request.Properties.AppliedScopes = nil
+	}
+
+	// Reservations are managed by the Microsoft.Capacity resource provider, at the tenant level.
+	client, err := armreservations.NewReservationOrderClient(credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create reservations client: %v", err)
+	}
+	quote, err := client.Calculate(ctx, request, nil)
+	if err != nil {
+		log.Fatalf("failed to get a price quote: %v", err)
+	}
+	printReservationQuote(request, quote.CalculatePriceResponse, *skuRU)
+
+	if !*yes {
+		fmt.Println("Dry run: nothing was purchased. Re-run with -yes to buy this reservation.")
+		return
+	}
+	var orderID string
+	if quote.Properties != nil {
+		orderID = stringValue(quote.Properties.ReservationOrderID)
+	}
+	if orderID == "" {
+		log.Fatalf("the price quote returned no reservation order ID")
+	}
+
+	fmt.Printf("Purchasing reservation order %s...\n", orderID)
+	poller, err := client.BeginPurchase(ctx, orderID, request, nil)
+	if err != nil {
+		log.Fatalf("failed to purchase reservation: %v", err)
+	}
+	order, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		log.Fatalf("failed to purchase reservation: %v", err)
+	}
+	recordResource("Microsoft.Capacity/reservationOrders", order.ID)
+	displayName, state := "", ""
+	if order.Properties != nil {
+		displayName, state = stringValue(order.Properties.DisplayName), enumValue(order.Properties.ProvisioningState)
+	}
+	fmt.Printf("Purchased reservation %s (%s): %s\n", displayName, state, stringValue(order.ID))
+}
+
+func printReservationQuote(request armreservations.PurchaseRequest, quote armreservations.CalculatePriceResponse, skuRU int) {
+	p := request.Properties
+	quantity := int(*p.Quantity)
+	scope := "this subscription"
+	if *p.AppliedScopeType == armreservations.AppliedScopeTypeShared {
+		scope = "all subscriptions in the billing context"
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Reservation:\t%d RU/s (%d x %s)\n", quantity*skuRU, quantity, stringValue(request.SKU.Name))
+	fmt.Fprintf(tw, "Term / billing:\t%s, %s\n", enumValue(p.Term), enumValue(p.BillingPlan))
+	fmt.Fprintf(tw, "Applies to:\t%s\n", scope)
+	if q := quote.Properties; q != nil {
+		if total := q.BillingCurrencyTotal; total != nil && total.Amount != nil {
+			fmt.Fprintf(tw, "Total price:\t%.2f %s\n", *total.Amount, stringValue(total.CurrencyCode))
+		}
+		if len(q.PaymentSchedule) > 1 && q.PaymentSchedule[0] != nil {
+			if first := q.PaymentSchedule[0].PricingCurrencyTotal; first != nil && first.Amount != nil {
+				fmt.Fprintf(tw, "Payments:\t%d x %.2f %s\n", len(q.PaymentSchedule), *first.Amount, stringValue(first.CurrencyCode))
+			}
+		}
+	}
+	_ = tw.Flush()
+}
+
+// getReservableThroughput sums the manual RU/s of every NoSQL database and container with its own throughput, across
+// all regions. Autoscale resources count at their minimum (10% of the max, billed at 1.5x), which they always use;
+// reserve for more with -ru if they usually run higher.
+func getReservableThroughput(ctx context.Context) (int, error) {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cosmos db account client: %w", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cosmos db account: %w", err)
+	}
+	if isServerless(account.DatabaseAccountGetResults) {
+		return 0, fmt.Errorf("account %s is serverless; reserved capacity only applies to provisioned throughput", accountName)
+	}
+	regionCount := 1
+	if account.Properties != nil && len(account.Properties.Locations) > 0 {
+		regionCount = len(account.Properties.Locations)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cosmos db sql client: %w", err)
+	}
+
+	// Throughput reads return 404 for databases and containers without their own throughput.
+	var respErr *azcore.ResponseError
+	total := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tTHROUGHPUT\tRESERVABLE RU/S")
+	add := func(name string, properties *armcosmos.ThroughputSettingsGetProperties) {
+		if properties == nil || properties.Resource == nil {
+			return
+		}
+		r := properties.Resource
+		ru := 0
+		switch {
+		case r.AutoscaleSettings != nil && r.AutoscaleSettings.MaxThroughput != nil:
+			ru = int(*r.AutoscaleSettings.MaxThroughput) / 10 * 3 / 2
+		case r.Throughput != nil:
+			ru = int(*r.Throughput)
+		}
+		total += ru
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", name, describeThroughputSettings(properties), ru)
+	}
+
+	databases := sqlClient.NewListSQLDatabasesPager(resourceGroupName, accountName, nil)
+	for databases.More() {
+		page, err := databases.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list databases: %w", err)
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			shared, err := sqlClient.GetSQLDatabaseThroughput(ctx, resourceGroupName, accountName, *db.Name, nil)
+			if err == nil {
+				add(*db.Name, shared.Properties)
+			} else if !(errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound) {
+				return 0, fmt.Errorf("failed to get throughput of %s: %w", *db.Name, err)
+			}
+
+			containers := sqlClient.NewListSQLContainersPager(resourceGroupName, accountName, *db.Name, nil)
+			for containers.More() {
+				page, err := containers.NextPage(ctx)
+				if err != nil {
+					return 0, fmt.Errorf("failed to list containers of %s: %w", *db.Name, err)
+				}
+				for _, c := range page.Value {
+					if c == nil || c.Name == nil {
+						continue
+					}
+					dedicated, err := sqlClient.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, *db.Name, *c.Name, nil)
+					if err == nil {
+						add(*db.Name+"/"+*c.Name, dedicated.Properties)
+					} else if !(errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound) {
+						return 0, fmt.Errorf("failed to get throughput of %s/%s: %w", *db.Name, *c.Name, err)
+					}
+				}
+			}
+		}
+	}
+	_ = tw.Flush()
+
+	fmt.Printf("Reservable throughput: %d RU/s x %d region(s) = %d RU/s\n", total, regionCount, total*regionCount)
+	return total * regionCount, nil
+}
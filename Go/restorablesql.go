@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runRestorableSQLCommand lists the SQL database (and, for one database, container) create/delete events of a
+// restorable account instance, and the databases and containers that can be restored as of a point in time. Use it
+// to pick the -timestamp and resource names for restore and restore-deleted.
+func runRestorableSQLCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("restorable-sql")
+	instanceID := fs.String("instance-id", "", "Restorable account instance ID; defaults to the configured account's (use restore-info, or this for a deleted account)")
+	accountLocation := fs.String("location", "", "Location of the restorable account; defaults to the configured account's location")
+	database := fs.String("database", "", "Also list the container events of this database")
+	timestamp := fs.String("timestamp", "", "List the resources that can be restored as of this RFC 3339 time instead of now")
+	_ = fs.Parse(args)
+
+	var restoreTime *time.Time
+	if *timestamp != "" {
+		t, err := time.Parse(time.RFC3339, *timestamp)
+		if err != nil {
+			log.Fatalf("Invalid -timestamp: %v", err)
+		}
+		restoreTime = &t
+	}
+
+	id, region := *instanceID, *accountLocation
+	if id == "" {
+		accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+		if err != nil {
+			log.Fatalf("failed to create cosmos db account client: %v", err)
+		}
+		account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+		if err != nil {
+			log.Fatalf("failed to get cosmos db account: %v", err)
+		}
+		if account.Properties == nil || account.Properties.InstanceID == nil {
+			log.Fatalf("account %s has no instance ID", accountName)
+		}
+		id = *account.Properties.InstanceID
+		if region == "" {
+			region = stringValue(account.Location)
+		}
+	}
+	if region == "" {
+		region = location
+	}
+
+	databaseEvents, err := listRestorableSQLDatabaseEvents(ctx, region, id)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Printf("SQL database events of instance %s:\n", id)
+	printRestorableEvents(databaseEvents)
+
+	if *database != "" {
+		// The container feed is keyed by the database's resource ID, which the database events carry even after the
+		// database itself is deleted.
+		rid := ""
+		for _, e := range databaseEvents {
+			if e.name == *database {
+				rid = e.ownerResourceID
+			}
+		}
+		if rid == "" {
+			log.Fatalf("database %s has no events in instance %s", *database, id)
+		}
+		containerEvents, err := listRestorableSQLContainerEvents(ctx, region, id, rid)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		fmt.Printf("\nContainer events of database %s:\n", *database)
+		printRestorableEvents(containerEvents)
+	}
+
+	resources, err := listRestorableSQLResources(ctx, region, id, restoreTime)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	when := "now"
+	if restoreTime != nil {
+		when = "as of " + restoreTime.UTC().Format(time.RFC3339)
+	}
+	fmt.Printf("\nRestorable resources %s:\n", when)
+	if len(resources) == 0 {
+		fmt.Println("(none)")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATABASE\tCONTAINERS")
+	for _, r := range resources {
+		if r == nil {
+			continue
+		}
+		containers := make([]string, 0, len(r.CollectionNames))
+		for _, name := range r.CollectionNames {
+			containers = append(containers, stringValue(name))
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", stringValue(r.DatabaseName), orDash(strings.Join(containers, ", ")))
+	}
+	_ = tw.Flush()
+}
+
+// restorableEvent is one create, delete, replace, or system event from a restorable resource feed.
+type restorableEvent struct {
+	timestamp       string
+	operation       string
+	name            string
+	ownerResourceID string
+	canUndelete     string
+}
+
+func printRestorableEvents(events []restorableEvent) {
+	if len(events) == 0 {
+		fmt.Println("(none)")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tOPERATION\tNAME\tCAN UNDELETE")
+	for _, e := range events {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.timestamp, e.operation, e.name, orDash(e.canUndelete))
+	}
+	_ = tw.Flush()
+}
+
+// listRestorableSQLDatabaseEvents returns the SQL database event feed of a restorable account instance.
+func listRestorableSQLDatabaseEvents(ctx context.Context, region string, instanceID string) ([]restorableEvent, error) {
+	client, err := armcosmos.NewRestorableSQLDatabasesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable sql databases client: %w", err)
+	}
+	events := make([]restorableEvent, 0)
+	pager := client.NewListPager(region, instanceID, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list database events: %w", err)
+		}
+		for _, item := range page.Value {
+			if item == nil || item.Properties == nil || item.Properties.Resource == nil {
+				continue
+			}
+			r := item.Properties.Resource
+			events = append(events, restorableEvent{
+				timestamp:       stringValue(r.EventTimestamp),
+				operation:       enumValue(r.OperationType),
+				name:            stringValue(r.OwnerID),
+				ownerResourceID: stringValue(r.OwnerResourceID),
+				canUndelete:     stringValue(r.CanUndelete),
+			})
+		}
+	}
+	return events, nil
+}
+
+// listRestorableSQLContainerEvents returns the container event feed of one database, identified by its resource ID.
+func listRestorableSQLContainerEvents(ctx context.Context, region string, instanceID string, databaseRid string) ([]restorableEvent, error) {
+	client, err := armcosmos.NewRestorableSQLContainersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable sql containers client: %w", err)
+	}
+	events := make([]restorableEvent, 0)
+	pager := client.NewListPager(region, instanceID, &armcosmos.RestorableSQLContainersClientListOptions{RestorableSQLDatabaseRid: &databaseRid})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list container events: %w", err)
+		}
+		for _, item := range page.Value {
+			if item == nil || item.Properties == nil || item.Properties.Resource == nil {
+				continue
+			}
+			r := item.Properties.Resource
+			events = append(events, restorableEvent{
+				timestamp:       stringValue(r.EventTimestamp),
+				operation:       enumValue(r.OperationType),
+				name:            stringValue(r.OwnerID),
+				ownerResourceID: stringValue(r.OwnerResourceID),
+				canUndelete:     stringValue(r.CanUndelete),
+			})
+		}
+	}
+	return events, nil
+}
+
+// listRestorableSQLResources returns the databases and containers that existed at restoreTime (or now, when nil) and
+// so can be restored.
+func listRestorableSQLResources(ctx context.Context, region string, instanceID string, restoreTime *time.Time) ([]*armcosmos.RestorableSQLResourcesGetResult, error) {
+	client, err := armcosmos.NewRestorableSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable sql resources client: %w", err)
+	}
+	options := &armcosmos.RestorableSQLResourcesClientListOptions{RestoreLocation: &region}
+	if restoreTime != nil {
+		options.RestoreTimestampInUTC = to.Ptr(restoreTime.UTC().Format(time.RFC3339))
+	}
+	resources := make([]*armcosmos.RestorableSQLResourcesGetResult, 0)
+	pager := client.NewListPager(region, instanceID, options)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list restorable resources: %w", err)
+		}
+		resources = append(resources, page.Value...)
+	}
+	return resources, nil
+}
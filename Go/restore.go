@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// runRestoreCommand restores the configured (continuous backup) account to a point in time as a new account, which
+// can be in a different resource group and, for accounts with more than one region, in one of the source's other
+// regions (cross-region restore).
+func runRestoreCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("restore")
+	targetAccount := fs.String("target-account", "", "Name of the new account to restore into (required)")
+	targetResourceGroup := fs.String("target-resource-group", "", "Existing resource group for the new account (default: ResourceGroupName)")
+	targetLocation := fs.String("target-location", "", "Region for the new account; must be one of the source account's regions (default: the source's write region)")
+	timestamp := fs.String("timestamp", "", "Point in time to restore to, in RFC 3339 format such as 2026-01-02T15:04:05Z (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: restore -target-account <name> -timestamp <RFC 3339 time> [-target-resource-group <name>] [-target-location <region>]")
+		fmt.Fprintln(fs.Output(), "Restores the account to a point in time as a new account (requires continuous backup).")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *targetAccount == "" || *timestamp == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	restoreTime, err := time.Parse(time.RFC3339, *timestamp)
+	if err != nil {
+		log.Fatalf("Invalid -timestamp: %v", err)
+	}
+	if *targetResourceGroup == "" {
+		*targetResourceGroup = resourceGroupName
+	}
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	source, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+	if source.Properties == nil || source.Properties.InstanceID == nil {
+		log.Fatalf("Account %s has no instance ID", accountName)
+	}
+	if _, ok := source.Properties.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy); !ok {
+		log.Fatalf("Account %s uses periodic backup; point-in-time restore requires continuous backup.", accountName)
+	}
+
+	restorableClient, err := armcosmos.NewRestorableDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create restorable database accounts client: %v", err)
+	}
+	restorable, err := restorableClient.GetByLocation(ctx, stringValue(source.Location), *source.Properties.InstanceID, nil)
+	if err != nil {
+		log.Fatalf("failed to get restorable database account: %v", err)
+	}
+
+	if *targetLocation == "" {
+		*targetLocation = writeRegion(source.DatabaseAccountGetResults)
+	}
+	restoreLocation, err := validateRestoreLocation(restorable.RestorableDatabaseAccountGetResult, *targetLocation)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := validateRestoreTimestamp(restorable.RestorableDatabaseAccountGetResult, restoreTime); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := checkRestoreTarget(ctx, accountClient, *targetResourceGroup, *targetAccount); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	restoreParameters := &armcosmos.RestoreParameters{
+		RestoreMode:           to.Ptr(armcosmos.RestoreModePointInTime),
+		RestoreSource:         restorable.ID,
+		RestoreTimestampInUTC: to.Ptr(restoreTime.UTC()),
+	}
+	// Restoring into a region other than the write region reads that region's backup (cross-region restore).
+	if !strings.EqualFold(normalizeRegion(restoreLocation), normalizeRegion(writeRegion(source.DatabaseAccountGetResults))) {
+		restoreParameters.SourceBackupLocation = to.Ptr(restoreLocation)
+	}
+
+	properties := armcosmos.DatabaseAccountCreateUpdateParameters{
+		Location: to.Ptr(restoreLocation),
+		Tags:     sampleTags(ctx),
+		Properties: &armcosmos.DatabaseAccountCreateUpdateProperties{
+			Locations: []*armcosmos.Location{{
+				LocationName:     to.Ptr(restoreLocation),
+				FailoverPriority: to.Ptr[int32](0),
+			}},
+			DatabaseAccountOfferType: to.Ptr("Standard"),
+			DisableLocalAuth:         to.Ptr(true),
+			CreateMode:               to.Ptr(armcosmos.CreateModeRestore),
+			RestoreParameters:        restoreParameters,
+			BackupPolicy:             source.Properties.BackupPolicy,
+		},
+	}
+
+	fmt.Printf("Restoring %s as of %s into %s/%s in %s...\n", accountName, restoreTime.UTC().Format(time.RFC3339), *targetResourceGroup, *targetAccount, restoreLocation)
+	poller, err := accountClient.BeginCreateOrUpdate(withIfNoneMatch(ctx), *targetResourceGroup, *targetAccount, properties, nil)
+	if err != nil {
+		log.Fatalf("failed to begin restore: %v", describeConcurrencyError(err, "Account "+*targetAccount))
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	if err != nil {
+		log.Fatalf("failed to restore the account: %v", err)
+	}
+	recordResource("Microsoft.DocumentDB/databaseAccounts", resp.ID)
+	fmt.Printf("Restored account: %s\n", stringValue(resp.ID))
+}
+
+// validateRestoreLocation checks that region is one of the restorable account's regions, the only regions with a
+// backup to restore from, and returns it as the service spells it.
+func validateRestoreLocation(restorable armcosmos.RestorableDatabaseAccountGetResult, region string) (string, error) {
+	available := make([]string, 0)
+	if restorable.Properties != nil {
+		for _, l := range restorable.Properties.RestorableLocations {
+			if l == nil || l.LocationName == nil || l.DeletionTime != nil {
+				continue
+			}
+			if normalizeRegion(*l.LocationName) == normalizeRegion(region) {
+				return *l.LocationName, nil
+			}
+			available = append(available, *l.LocationName)
+		}
+	}
+	return "", fmt.Errorf("target region %q is not one of the source account's regions; choose one of: %s", region, strings.Join(available, ", "))
+}
+
+// validateRestoreTimestamp checks that t falls inside the restorable window.
+func validateRestoreTimestamp(restorable armcosmos.RestorableDatabaseAccountGetResult, t time.Time) error {
+	if t.After(time.Now()) {
+		return fmt.Errorf("restore timestamp %s is in the future", t.UTC().Format(time.RFC3339))
+	}
+	if restorable.Properties != nil && restorable.Properties.OldestRestorableTime != nil && t.Before(*restorable.Properties.OldestRestorableTime) {
+		return fmt.Errorf("restore timestamp %s is before the earliest restorable time %s", t.UTC().Format(time.RFC3339), restorable.Properties.OldestRestorableTime.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkRestoreTarget checks that the target resource group exists and the target account name is free (used by
+// restore and clone).
+func checkRestoreTarget(ctx context.Context, accountClient *armcosmos.DatabaseAccountsClient, group string, name string) error {
+	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create resource group client: %w", err)
+	}
+	exists, err := resourceGroupClient.CheckExistence(ctx, group, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check resource group %s: %w", group, err)
+	}
+	if !exists.Success {
+		return fmt.Errorf("target resource group %s does not exist", group)
+	}
+
+	taken, err := accountClient.CheckNameExists(ctx, name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check account name availability: %w", err)
+	}
+	if taken.Success {
+		return fmt.Errorf("account name %s is already in use; the target must be a new account", name)
+	}
+	return nil
+}
+
+// writeRegion returns the account's (first) write region.
+func writeRegion(account armcosmos.DatabaseAccountGetResults) string {
+	if account.Properties != nil {
+		for _, l := range account.Properties.WriteLocations {
+			if l != nil && l.LocationName != nil {
+				return *l.LocationName
+			}
+		}
+	}
+	return stringValue(account.Location)
+}
+
+// normalizeRegion turns a display name such as "East US" into the "eastus" form.
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.ReplaceAll(region, " ", ""))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runRestoreInfoCommand prints the account's instance ID, backup mode, and (for continuous backup) restorable window.
+func runRestoreInfoCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("restore-info")
+	_ = fs.Parse(args)
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+
+	printRestoreInfo(ctx, account.DatabaseAccountGetResults)
+}
+
+// printRestoreInfo prints what a point-in-time restore script needs: the account's instance ID and, for continuous
+// backup accounts, the earliest time it can be restored to. The values are also recorded in the run summary.
+func printRestoreInfo(ctx context.Context, account armcosmos.DatabaseAccountGetResults) {
+	if account.Properties == nil || account.Properties.InstanceID == nil {
+		return
+	}
+
+	info := &summaryRestore{InstanceID: *account.Properties.InstanceID, BackupMode: "Periodic"}
+	fmt.Printf("Account instance ID: %s\n", info.InstanceID)
+
+	continuous, ok := account.Properties.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy)
+	if !ok {
+		fmt.Println("Backup mode: Periodic (point-in-time restore requires continuous backup)")
+		summary.Restore = info
+		return
+	}
+
+	info.BackupMode = "Continuous"
+	if continuous.ContinuousModeProperties != nil && continuous.ContinuousModeProperties.Tier != nil {
+		info.BackupMode = string(*continuous.ContinuousModeProperties.Tier)
+	}
+	fmt.Printf("Backup mode: %s\n", info.BackupMode)
+
+	oldest, err := getOldestRestorableTime(ctx, info.InstanceID)
+	if err != nil {
+		log.Printf("Could not read the restorable window: %v", err)
+	} else if oldest != nil {
+		info.OldestRestorableTime = oldest
+		fmt.Printf("Earliest restorable time: %s\n", oldest.UTC().Format(time.RFC3339))
+	}
+	summary.Restore = info
+}
+
+// getOldestRestorableTime reads the restorable database account for the instance ID and returns its oldest restorable time.
+func getOldestRestorableTime(ctx context.Context, instanceID string) (*time.Time, error) {
+	restorableClient, err := armcosmos.NewRestorableDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable database accounts client: %w", err)
+	}
+
+	resp, err := restorableClient.GetByLocation(ctx, location, instanceID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restorable database account: %w", err)
+	}
+	if resp.Properties == nil {
+		return nil, nil
+	}
+	return resp.Properties.OldestRestorableTime, nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+)
+
+// resourceRestorer recreates a deleted database (child == "") or a deleted child resource (container, graph, ...)
+// of one API from the account's continuous backup, and returns the restored resource's ID. APIs without a database
+// level, such as Table, take the resource name as database.
+type resourceRestorer func(ctx context.Context, params *armcosmos.ResourceRestoreParameters, database string, child string) (string, error)
+
+// resourceRestorers are the APIs restore-deleted supports, keyed by the -api flag value.
+var resourceRestorers = map[string]resourceRestorer{
+	"sql":     restoreSQLResource,
+	"gremlin": restoreGremlinResource,
+	"table":   restoreTableResource,
+	"mongodb": restoreMongoDBResource,
+}
+
+// runRestoreDeletedCommand recovers a deleted database or container-level resource into the same account by
+// recreating it with CreateMode Restore, as of a point in time before it was deleted.
+func runRestoreDeletedCommand(ctx context.Context, args []string) {
+	apis := make([]string, 0, len(resourceRestorers))
+	for name := range resourceRestorers {
+		apis = append(apis, name)
+	}
+	sort.Strings(apis)
+
+	fs := newCommandFlagSet("restore-deleted")
+	api := fs.String("api", "sql", "API of the deleted resource: "+strings.Join(apis, ", "))
+	timestamp := fs.String("timestamp", "", "Point in time to restore to, before the resource was deleted, in RFC 3339 format (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: restore-deleted [-api <api>] -timestamp <RFC 3339 time> <database>[/<container, graph, or collection>] | <table>")
+		fmt.Fprintln(fs.Output(), "Recreates a deleted resource in the account from continuous backup. Restore the database first if it was deleted too.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	restore, ok := resourceRestorers[strings.ToLower(*api)]
+	if !ok || *timestamp == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	database, child, _ := strings.Cut(fs.Arg(0), "/")
+	if database == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	restoreTime, err := time.Parse(time.RFC3339, *timestamp)
+	if err != nil {
+		log.Fatalf("Invalid -timestamp: %v", err)
+	}
+
+	params, err := inAccountRestoreParameters(ctx, restoreTime)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fmt.Printf("Restoring %s as of %s...\n", fs.Arg(0), restoreTime.UTC().Format(time.RFC3339))
+	id, err := restore(ctx, params, database, child)
+	if err != nil {
+		log.Fatalf("failed to restore %s: %v", fs.Arg(0), describeConcurrencyError(err, fs.Arg(0)))
+	}
+	fmt.Printf("Restored: %s\n", id)
+}
+
+// inAccountRestoreParameters returns restore parameters that point at the configured account's own continuous backup,
+// after checking that the timestamp is in its restorable window.
+func inAccountRestoreParameters(ctx context.Context, restoreTime time.Time) (*armcosmos.ResourceRestoreParameters, error) {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cosmos db account client: %w", err)
+	}
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cosmos db account: %w", err)
+	}
+	if account.Properties == nil || account.Properties.InstanceID == nil {
+		return nil, fmt.Errorf("account %s has no instance ID", accountName)
+	}
+	if _, ok := account.Properties.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy); !ok {
+		return nil, fmt.Errorf("account %s uses periodic backup; restoring deleted resources requires continuous backup", accountName)
+	}
+
+	restorableClient, err := armcosmos.NewRestorableDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorable database accounts client: %w", err)
+	}
+	restorable, err := restorableClient.GetByLocation(ctx, stringValue(account.Location), *account.Properties.InstanceID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restorable database account: %w", err)
+	}
+	if err := validateRestoreTimestamp(restorable.RestorableDatabaseAccountGetResult, restoreTime); err != nil {
+		return nil, err
+	}
+
+	return &armcosmos.ResourceRestoreParameters{
+		RestoreSource:         restorable.ID,
+		RestoreTimestampInUTC: to.Ptr(restoreTime.UTC()),
+	}, nil
+}
+
+// restoreSQLResource restores a deleted SQL database, or a deleted container in an existing database.
+func restoreSQLResource(ctx context.Context, params *armcosmos.ResourceRestoreParameters, database string, container string) (string, error) {
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cosmos db sql client: %w", err)
+	}
+
+	// If-None-Match: the restore must not overwrite a resource that exists again under the same name.
+	if container == "" {
+		body := armcosmos.SQLDatabaseCreateUpdateParameters{
+			Properties: &armcosmos.SQLDatabaseCreateUpdateProperties{
+				Resource: &armcosmos.SQLDatabaseResource{ID: to.Ptr(database), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+			},
+		}
+		poller, err := sqlClient.BeginCreateUpdateSQLDatabase(withIfNoneMatch(ctx), resourceGroupName, accountName, database, body, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return stringValue(resp.ID), err
+	}
+
+	body := armcosmos.SQLContainerCreateUpdateParameters{
+		Properties: &armcosmos.SQLContainerCreateUpdateProperties{
+			Resource: &armcosmos.SQLContainerResource{ID: to.Ptr(container), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+		},
+	}
+	poller, err := sqlClient.BeginCreateUpdateSQLContainer(withIfNoneMatch(ctx), resourceGroupName, accountName, database, container, body, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	return stringValue(resp.ID), err
+}
+
+// restoreGremlinResource restores a deleted Gremlin database, or a deleted graph in an existing database.
+func restoreGremlinResource(ctx context.Context, params *armcosmos.ResourceRestoreParameters, database string, graph string) (string, error) {
+	gremlinClient, err := armcosmos.NewGremlinResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cosmos db gremlin client: %w", err)
+	}
+
+	if graph == "" {
+		body := armcosmos.GremlinDatabaseCreateUpdateParameters{
+			Properties: &armcosmos.GremlinDatabaseCreateUpdateProperties{
+				Resource: &armcosmos.GremlinDatabaseResource{ID: to.Ptr(database), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+			},
+		}
+		poller, err := gremlinClient.BeginCreateUpdateGremlinDatabase(withIfNoneMatch(ctx), resourceGroupName, accountName, database, body, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return stringValue(resp.ID), err
+	}
+
+	body := armcosmos.GremlinGraphCreateUpdateParameters{
+		Properties: &armcosmos.GremlinGraphCreateUpdateProperties{
+			Resource: &armcosmos.GremlinGraphResource{ID: to.Ptr(graph), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+		},
+	}
+	poller, err := gremlinClient.BeginCreateUpdateGremlinGraph(withIfNoneMatch(ctx), resourceGroupName, accountName, database, graph, body, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	return stringValue(resp.ID), err
+}
+
+// restoreTableResource restores a deleted table. Tables have no database level, so there is no child resource.
+func restoreTableResource(ctx context.Context, params *armcosmos.ResourceRestoreParameters, table string, child string) (string, error) {
+	if child != "" {
+		return "", fmt.Errorf("tables have no child resources; pass just the table name")
+	}
+	tableClient, err := armcosmos.NewTableResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cosmos db table client: %w", err)
+	}
+
+	body := armcosmos.TableCreateUpdateParameters{
+		Properties: &armcosmos.TableCreateUpdateProperties{
+			Resource: &armcosmos.TableResource{ID: to.Ptr(table), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+		},
+	}
+	poller, err := tableClient.BeginCreateUpdateTable(withIfNoneMatch(ctx), resourceGroupName, accountName, table, body, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	return stringValue(resp.ID), err
+}
+
+// restoreMongoDBResource restores a deleted MongoDB database, or a deleted collection in an existing database.
+func restoreMongoDBResource(ctx context.Context, params *armcosmos.ResourceRestoreParameters, database string, collection string) (string, error) {
+	mongoClient, err := armcosmos.NewMongoDBResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cosmos db mongodb client: %w", err)
+	}
+
+	if collection == "" {
+		body := armcosmos.MongoDBDatabaseCreateUpdateParameters{
+			Properties: &armcosmos.MongoDBDatabaseCreateUpdateProperties{
+				Resource: &armcosmos.MongoDBDatabaseResource{ID: to.Ptr(database), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+			},
+		}
+		poller, err := mongoClient.BeginCreateUpdateMongoDBDatabase(withIfNoneMatch(ctx), resourceGroupName, accountName, database, body, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return stringValue(resp.ID), err
+	}
+
+	body := armcosmos.MongoDBCollectionCreateUpdateParameters{
+		Properties: &armcosmos.MongoDBCollectionCreateUpdateProperties{
+			Resource: &armcosmos.MongoDBCollectionResource{ID: to.Ptr(collection), CreateMode: to.Ptr(armcosmos.CreateModeRestore), RestoreParameters: params},
+		},
+	}
+	poller, err := mongoClient.BeginCreateUpdateMongoDBCollection(withIfNoneMatch(ctx), resourceGroupName, accountName, database, collection, body, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	return stringValue(resp.ID), err
+}
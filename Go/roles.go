@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runRolesCommand shows the account's SQL role definitions: each role's permissions, and which of the known data
+// actions each role grants, so the built-in reader and contributor can be compared before writing a custom role.
+func runRolesCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("roles")
+	custom := fs.Bool("custom", false, "Include the account's custom role definitions")
+	report := addReportFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: roles [-custom] [-format text|csv|html] [-out <file>] show")
+		fmt.Fprintf(fs.Output(), "Shows the built-in SQL role definitions of %s (and, with -custom, its custom ones) and the data actions each grants.\n", accountName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || fs.Arg(0) != "show" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := report.validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role definition client: %v", err)
+	}
+	var roles []*armcosmos.SQLRoleDefinitionGetResults
+	pager := sqlClient.NewListSQLRoleDefinitionsPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list SQL role definitions: %v", err)
+		}
+		for _, d := range page.Value {
+			if d == nil || d.Properties == nil {
+				continue
+			}
+			builtIn := d.Properties.Type != nil && *d.Properties.Type == armcosmos.RoleDefinitionTypeBuiltInRole
+			if builtIn || *custom {
+				roles = append(roles, d)
+			}
+		}
+	}
+	if len(roles) == 0 {
+		fmt.Printf("No SQL role definitions found on %s.\n", accountName)
+		return
+	}
+	// Built-in roles first, then by name.
+	sort.SliceStable(roles, func(i, j int) bool {
+		ti, tj := enumValue(roles[i].Properties.Type), enumValue(roles[j].Properties.Type)
+		if ti != tj {
+			return ti == string(armcosmos.RoleDefinitionTypeBuiltInRole)
+		}
+		return stringValue(roles[i].Properties.RoleName) < stringValue(roles[j].Properties.RoleName)
+	})
+
+	permissions := reportTable{title: "Role definitions", headers: []string{"ROLE", "TYPE", "ID", "DATA ACTION", "EFFECT"}}
+	matrix := reportTable{title: "Known data actions by role", headers: []string{"DATA ACTION"}}
+	for _, role := range roles {
+		p := role.Properties
+		name, id := stringValue(p.RoleName), path.Base(stringValue(role.ID))
+		matrix.headers = append(matrix.headers, strings.ToUpper(name))
+		rows := 0
+		for _, permission := range p.Permissions {
+			if permission == nil {
+				continue
+			}
+			for _, action := range permission.DataActions {
+				permissions.addRow(name, enumValue(p.Type), id, stringValue(action), "allow")
+				rows++
+			}
+			for _, action := range permission.NotDataActions {
+				permissions.addRow(name, enumValue(p.Type), id, stringValue(action), "deny")
+				rows++
+			}
+		}
+		if rows == 0 {
+			permissions.addRow(name, enumValue(p.Type), id, "-", "-")
+		}
+	}
+	for _, action := range sqlDataActions {
+		row := []string{strings.TrimPrefix(action, "Microsoft.DocumentDB/databaseAccounts/")}
+		for _, role := range roles {
+			result := evaluateSQLRoleAssignment(role, "/", action, "/")
+			if strings.HasPrefix(result, "allows") {
+				row = append(row, "yes")
+			} else {
+				row = append(row, "no")
+			}
+		}
+		matrix.addRow(row...)
+	}
+	matrix.addNote("A * in a data action matches any characters; deny entries (not-data actions) remove actions the same permission allows.")
+	matrix.addNote("Roles apply at the scope they are assigned to: the account, a database (/dbs/<name>), or a container (/dbs/<name>/colls/<name>).")
+
+	if err := writeReport(report, fmt.Sprintf("SQL role definitions of %s", accountName), permissions, matrix); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// runIDTagName is the tag that links every resource the sample creates to the run that created (or last updated) it.
+const runIDTagName = "cosmos-sample-run-id"
+
+// runID identifies this run. Set with -run-id; generated from the start time otherwise.
+var runID string
+
+// initializeRunID sets the run ID from the -run-id flag or the current time.
+func initializeRunID() {
+	runID = *runIDFlag
+	if runID == "" {
+		runID = "run-" + time.Now().UTC().Format("20060102-150405")
+	}
+	summary.RunID = runID
+}
+
+// sampleTags returns the tags set on every resource the sample creates: the Tags setting, owner (the signed-in
+// identity unless Tags sets it), and the run ID. It stops the sample when a RequiredTags tag is empty.
+func sampleTags(ctx context.Context) map[string]*string {
+	owner := ""
+	if configuredTags["owner"] == "" {
+		owner = getCurrentUserEmailBestEffort(ctx)
+	}
+	tags, err := buildResourceTags(owner)
+	if err != nil {
+		log.Fatalf("Invalid tags: %v", err)
+	}
+	result := make(map[string]*string, len(tags))
+	for name, value := range tags {
+		result[name] = to.Ptr(value)
+	}
+	return result
+}
+
+// taggedResource is a resource (or resource group) carrying the run ID tag.
+type taggedResource struct {
+	id           string
+	resourceType string
+	runID        string
+}
+
+// runRunsCommand lists sample runs in the subscription, shows the resources of one run, or deletes them.
+func runRunsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("runs")
+	yes := fs.Bool("yes", false, "Delete without asking (cleanup only); without it, cleanup only lists what it would delete")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: runs [-yes] [list | show <run id> | cleanup <run id>]")
+		fmt.Fprintf(fs.Output(), "Finds resources tagged %s=<run id> across the subscription.\n", runIDTagName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "", "list":
+		listSampleRuns(ctx)
+	case "show":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		showSampleRun(ctx, fs.Arg(1))
+	case "cleanup":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		cleanupSampleRun(ctx, fs.Arg(1), *yes)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// listSampleRuns prints each run ID found in the subscription with its resource count.
+func listSampleRuns(ctx context.Context) {
+	resources, err := findTaggedResources(ctx, "")
+	if err != nil {
+		log.Fatalf("failed to find tagged resources: %v", err)
+	}
+	if len(resources) == 0 {
+		fmt.Printf("No resources tagged %s in subscription %s.\n", runIDTagName, subscriptionID)
+		return
+	}
+
+	counts := map[string]int{}
+	for _, r := range resources {
+		counts[r.runID]++
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN ID\tRESOURCES")
+	for _, id := range ids {
+		fmt.Fprintf(tw, "%s\t%d\n", id, counts[id])
+	}
+	_ = tw.Flush()
+}
+
+// showSampleRun prints the resources tagged with the run ID.
+func showSampleRun(ctx context.Context, id string) {
+	resources, err := findTaggedResources(ctx, id)
+	if err != nil {
+		log.Fatalf("failed to find tagged resources: %v", err)
+	}
+	if len(resources) == 0 {
+		fmt.Printf("No resources tagged %s=%s in subscription %s.\n", runIDTagName, id, subscriptionID)
+		return
+	}
+	printTaggedResources(resources)
+}
+
+// cleanupSampleRun deletes the resources tagged with the run ID: alerts and action groups first, then accounts (after
+// removing the sample's lock), other resources, and finally resource groups the run created.
+func cleanupSampleRun(ctx context.Context, id string, yes bool) {
+	resources, err := findTaggedResources(ctx, id)
+	if err != nil {
+		log.Fatalf("failed to find tagged resources: %v", err)
+	}
+	if len(resources) == 0 {
+		fmt.Printf("No resources tagged %s=%s in subscription %s.\n", runIDTagName, id, subscriptionID)
+		return
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		return cleanupOrder(resources[i].resourceType) < cleanupOrder(resources[j].resourceType)
+	})
+	printTaggedResources(resources)
+	if !yes {
+		fmt.Println("Run with -yes to delete these resources.")
+		return
+	}
+
+	resourcesClient, err := armresources.NewClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create resources client: %v", err)
+	}
+	providersClient, err := armresources.NewProvidersClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create providers client: %v", err)
+	}
+	locksClient, err := armlocks.NewManagementLocksClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create management locks client: %v", err)
+	}
+	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create resource group client: %v", err)
+	}
+
+	failed := 0
+	for _, r := range resources {
+		if r.resourceType == "Microsoft.Resources/resourceGroups" {
+			// A later run can reuse the group without retagging it, so deleting it could take other runs with it.
+			others, err := untaggedGroupResources(ctx, resourcesClient, r)
+			if err != nil {
+				log.Printf("Could not delete %s: %v", r.id, err)
+				failed++
+				continue
+			}
+			if len(others) > 0 {
+				log.Printf("Keeping resource group %s: it contains %d resource(s) not tagged %s=%s, such as %s", r.id, len(others), runIDTagName, r.runID, others[0])
+				continue
+			}
+		}
+		if err := deleteTaggedResource(ctx, r, resourcesClient, providersClient, locksClient, resourceGroupClient); err != nil {
+			log.Printf("Could not delete %s: %v", r.id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Deleted: %s\n", r.id)
+	}
+	if failed > 0 {
+		log.Fatalf("failed to delete %d of %d resource(s) for run %s", failed, len(resources), id)
+	}
+}
+
+// deleteTaggedResource deletes one tagged resource or resource group.
+func deleteTaggedResource(ctx context.Context, r taggedResource, resourcesClient *armresources.Client, providersClient *armresources.ProvidersClient, locksClient *armlocks.ManagementLocksClient, resourceGroupClient *armresources.ResourceGroupsClient) error {
+	if r.resourceType == "Microsoft.Resources/resourceGroups" {
+		pollerResp, err := resourceGroupClient.BeginDelete(ctx, r.id[strings.LastIndex(r.id, "/")+1:], nil)
+		if err != nil {
+			return err
+		}
+		_, err = pollUntilDone(ctx, pollerResp)
+		return err
+	}
+
+	if strings.EqualFold(r.resourceType, "Microsoft.DocumentDB/databaseAccounts") {
+		if _, err := locksClient.DeleteByScope(ctx, r.id, accountLockName, nil); err != nil {
+			var respErr *azcore.ResponseError
+			if !errors.As(err, &respErr) || respErr.StatusCode != 404 {
+				return fmt.Errorf("failed to delete management lock: %w", err)
+			}
+		}
+	}
+
+	apiVersion, err := getResourceAPIVersion(ctx, providersClient, r.resourceType)
+	if err != nil {
+		return err
+	}
+	pollerResp, err := resourcesClient.BeginDeleteByID(ctx, r.id, apiVersion, nil)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone(ctx, pollerResp)
+	return err
+}
+
+// untaggedGroupResources returns the IDs of the resources in a tagged resource group that don't carry the group's run
+// ID tag.
+func untaggedGroupResources(ctx context.Context, resourcesClient *armresources.Client, group taggedResource) ([]string, error) {
+	var others []string
+	pager := resourcesClient.NewListByResourceGroupPager(group.id[strings.LastIndex(group.id, "/")+1:], nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the resource group's resources: %w", err)
+		}
+		for _, res := range page.Value {
+			if res != nil && res.ID != nil && stringValue(res.Tags[runIDTagName]) != group.runID {
+				others = append(others, *res.ID)
+			}
+		}
+	}
+	return others, nil
+}
+
+// findTaggedResources returns the resources and resource groups tagged with the run ID, or with any run ID when id is "".
+func findTaggedResources(ctx context.Context, id string) ([]taggedResource, error) {
+	filter := fmt.Sprintf("tagName eq '%s'", runIDTagName)
+	if id != "" {
+		filter += fmt.Sprintf(" and tagValue eq '%s'", id)
+	}
+
+	resourcesClient, err := armresources.NewClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resources client: %w", err)
+	}
+	resourceGroupClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource group client: %w", err)
+	}
+
+	found := make([]taggedResource, 0)
+	pager := resourcesClient.NewListPager(&armresources.ClientListOptions{Filter: to.Ptr(filter)})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		for _, r := range page.Value {
+			if r != nil && r.ID != nil {
+				found = append(found, taggedResource{id: *r.ID, resourceType: stringValue(r.Type), runID: stringValue(r.Tags[runIDTagName])})
+			}
+		}
+	}
+
+	groupPager := resourceGroupClient.NewListPager(&armresources.ResourceGroupsClientListOptions{Filter: to.Ptr(filter)})
+	for groupPager.More() {
+		page, err := groupPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resource groups: %w", err)
+		}
+		for _, g := range page.Value {
+			if g != nil && g.ID != nil {
+				found = append(found, taggedResource{id: *g.ID, resourceType: "Microsoft.Resources/resourceGroups", runID: stringValue(g.Tags[runIDTagName])})
+			}
+		}
+	}
+	return found, nil
+}
+
+// getResourceAPIVersion returns the newest stable api-version the resource provider supports for the resource type.
+func getResourceAPIVersion(ctx context.Context, providersClient *armresources.ProvidersClient, resourceType string) (string, error) {
+	namespace, typeName, ok := strings.Cut(resourceType, "/")
+	if !ok {
+		return "", fmt.Errorf("unexpected resource type %q", resourceType)
+	}
+
+	provider, err := providersClient.Get(ctx, namespace, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get resource provider %s: %w", namespace, err)
+	}
+	for _, t := range provider.ResourceTypes {
+		if t == nil || !strings.EqualFold(stringValue(t.ResourceType), typeName) {
+			continue
+		}
+		versions := make([]string, 0, len(t.APIVersions))
+		for _, v := range t.APIVersions {
+			if v != nil && !strings.Contains(*v, "preview") {
+				versions = append(versions, *v)
+			}
+		}
+		if len(versions) > 0 {
+			sort.Strings(versions)
+			return versions[len(versions)-1], nil
+		}
+	}
+	return "", fmt.Errorf("no stable api-version found for %s", resourceType)
+}
+
+// cleanupOrder sorts resources so dependents are deleted before what they depend on, and resource groups last.
+func cleanupOrder(resourceType string) int {
+	switch strings.ToLower(resourceType) {
+	case "microsoft.insights/metricalerts":
+		return 0
+	case "microsoft.insights/actiongroups":
+		return 1
+	case "microsoft.documentdb/databaseaccounts":
+		return 2
+	case "microsoft.resources/resourcegroups":
+		return 4
+	}
+	return 3
+}
+
+func printTaggedResources(resources []taggedResource) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN ID\tTYPE\tID")
+	for _, r := range resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.runID, r.resourceType, r.id)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// runScaleCommand sets the throughput of any database or container in the account to an absolute value, using the
+// autoscale or manual update that matches how the resource is provisioned today.
+func runScaleCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("scale")
+	database := fs.String("database", databaseName, "Database to scale, or the database of -container")
+	container := fs.String("container", containerName, "Container to scale; omit it with -database to scale the database's shared throughput")
+	maxRU := fs.Int("max-ru", 0, "New autoscale max RU/s, or manual RU/s when the resource uses manual throughput")
+	dryRun := fs.Bool("dry-run", false, "Print the change without making it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: scale [-database <name>] [-container <name>] -max-ru <RU/s> [-dry-run]")
+		fmt.Fprintf(fs.Output(), "Without -database or -container it scales the configured container %s/%s.\n", databaseName, containerName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 || *maxRU <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *maxRU > maxContainerThroughput {
+		log.Fatalf("-max-ru must be at most %d RU/s (got %d)", maxContainerThroughput, *maxRU)
+	}
+
+	// -database on its own names a database with shared throughput; the container default only applies when the
+	// database is the configured one.
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	target := throughputTarget{database: *database, container: *container}
+	if set["database"] && !set["container"] {
+		target.container = ""
+	}
+	if target.database == "" {
+		log.Fatalf("-database is required when DatabaseName is not configured")
+	}
+
+	setThroughput(ctx, target, *dryRun, func(int64, bool) int64 {
+		return int64(*maxRU)
+	})
+}
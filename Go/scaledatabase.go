@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// batchThroughputResult is the outcome of the batch update for one container.
+type batchThroughputResult struct {
+	container string
+	change    throughputChange
+	err       error
+	shared    bool
+}
+
+// runScaleDatabaseCommand applies one throughput change to every container with dedicated throughput in a database,
+// a few at a time, and prints what happened to each.
+func runScaleDatabaseCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("scale-database")
+	database := fs.String("database", databaseName, "Database whose containers are scaled")
+	maxRU := fs.Int("max-ru", 0, "Set each container to this autoscale max or manual RU/s")
+	percent := fs.Float64("percent", 0, "Change each container by this percentage instead, for example 50 or -25")
+	parallel := fs.Int("parallel", 4, "How many containers to update at a time (1-16)")
+	dryRun := fs.Bool("dry-run", false, "Print the changes without making them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: scale-database [-database <name>] (-max-ru <RU/s> | -percent <change>) [-parallel 4] [-dry-run]")
+		fmt.Fprintln(fs.Output(), "Scales every container with its own throughput in the database; containers on shared throughput are skipped.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 || (*maxRU == 0) == (*percent == 0) {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *maxRU < 0 || *maxRU > maxContainerThroughput {
+		log.Fatalf("-max-ru must be between %d and %d RU/s (got %d)", minManualThroughput, maxContainerThroughput, *maxRU)
+	}
+	if *percent <= -100 {
+		log.Fatalf("-percent must be above -100 (got %g)", *percent)
+	}
+	if *parallel < 1 || *parallel > 16 {
+		log.Fatalf("-parallel must be between 1 and 16 (got %d)", *parallel)
+	}
+	if *database == "" {
+		log.Fatalf("-database is required when DatabaseName is not configured")
+	}
+	if *createOnly && !*dryRun {
+		fmt.Println("Skipping the throughput update (-create-only never changes existing resources).")
+		return
+	}
+
+	client, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+	var containers []string
+	pager := client.NewListSQLContainersPager(resourceGroupName, accountName, *database, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list containers in %s: %v", *database, err)
+		}
+		for _, c := range page.Value {
+			if c != nil && c.Name != nil {
+				containers = append(containers, *c.Name)
+			}
+		}
+	}
+	if len(containers) == 0 {
+		fmt.Printf("Database %s has no containers.\n", *database)
+		return
+	}
+
+	newValue := func(current int64, autoscale bool) int64 {
+		if *maxRU > 0 {
+			return int64(*maxRU)
+		}
+		return scaleByPercent(current, *percent, autoscale)
+	}
+	ledger, err := newThroughputLedger(ctx, client)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("Scaling %d container(s) in %s/%s, %d at a time", len(containers), accountName, *database, *parallel)
+
+	results := make([]batchThroughputResult, len(containers))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range *parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				target := throughputTarget{database: *database, container: containers[i]}
+				change, err := applyThroughput(ctx, client, target, *dryRun, ledger, newValue)
+				var respErr *azcore.ResponseError
+				shared := errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+				results[i] = batchThroughputResult{container: containers[i], change: change, err: err, shared: shared}
+			}
+		}()
+	}
+	for i := range containers {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	updated, unchanged, skipped, failed, overBudget := 0, 0, 0, 0, 0
+	table := reportTable{
+		title:   fmt.Sprintf("Throughput changes in %s/%s", accountName, *database),
+		headers: []string{"CONTAINER", "MODE", "FROM", "TO", "RESULT"},
+	}
+	for _, r := range results {
+		mode, from, to := "-", "-", "-"
+		if r.change.kind != "" {
+			mode, from, to = "manual", strconv.FormatInt(r.change.from, 10), strconv.FormatInt(r.change.to, 10)
+			if r.change.autoscale {
+				mode = "autoscale"
+			}
+		}
+		result := "updated"
+		switch {
+		case r.shared:
+			result = "skipped (shared throughput)"
+			skipped++
+		case errors.As(r.err, new(*throughputBudgetError)):
+			result = "over budget"
+			table.addNote("%s: %v", r.container, r.err)
+			overBudget++
+			failed++
+		case r.err != nil:
+			result = "failed"
+			table.addNote("%s: %v", r.container, r.err)
+			failed++
+		case r.change.to == r.change.from:
+			result = "unchanged"
+			unchanged++
+		case *dryRun:
+			result = "would update"
+			updated++
+		default:
+			updated++
+		}
+		table.addRow(r.container, mode, from, to, result)
+	}
+	if err := writeTextReport(os.Stdout, []reportTable{table}); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if overBudget > 0 {
+		fmt.Print(ledger.breakdown())
+	}
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s %d, unchanged %d, skipped %d, failed %d of %d container(s).\n", verb, updated, unchanged, skipped, failed, len(containers))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// scaleByPercent changes current by percent, rounded to the nearest value the mode accepts (a multiple of 1000 RU/s
+// for an autoscale max, 100 RU/s for manual throughput) and no lower than the mode's minimum.
+func scaleByPercent(current int64, percent float64, autoscale bool) int64 {
+	step, floor := 100.0, float64(minManualThroughput)
+	if autoscale {
+		step, floor = 1000, float64(minAutoscaleMaxThroughput)
+	}
+	scaled := math.Round(float64(current)*(100+percent)/100/step) * step
+	return int64(math.Max(scaled, floor))
+}
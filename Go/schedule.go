@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/spf13/viper"
+)
+
+// throughputWindow is one entry of ThroughputSchedule.Windows: from each time Cron fires until another window's
+// Cron fires, the container should have Throughput RU/s (its autoscale max, or manual RU/s).
+type throughputWindow struct {
+	Name       string
+	Cron       string
+	Throughput int
+
+	schedule cronSchedule
+}
+
+var (
+	// throughputWindows is the ThroughputSchedule setting; empty when it isn't set.
+	throughputWindows []throughputWindow
+	// scheduleLocation is the time zone the cron expressions are evaluated in (ThroughputSchedule.TimeZone).
+	scheduleLocation = time.Local
+)
+
+// loadThroughputScheduleSettings reads ThroughputSchedule.TimeZone and ThroughputSchedule.Windows.
+func loadThroughputScheduleSettings() error {
+	throughputWindows = nil
+	scheduleLocation = time.Local
+	if !viper.IsSet("ThroughputSchedule") {
+		return nil
+	}
+	if zone := strings.TrimSpace(viper.GetString("ThroughputSchedule.TimeZone")); zone != "" {
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return fmt.Errorf("ThroughputSchedule TimeZone %q is not a known time zone: %w", zone, err)
+		}
+		scheduleLocation = location
+	}
+	if err := viper.UnmarshalKey("ThroughputSchedule.Windows", &throughputWindows); err != nil {
+		return fmt.Errorf("failed to read ThroughputSchedule Windows: %w", err)
+	}
+	if len(throughputWindows) < 2 {
+		return fmt.Errorf("ThroughputSchedule needs at least 2 Windows (for example business hours and night)")
+	}
+	for i := range throughputWindows {
+		w := &throughputWindows[i]
+		if w.Name = strings.TrimSpace(w.Name); w.Name == "" {
+			w.Name = fmt.Sprintf("window %d", i+1)
+		}
+		schedule, err := parseCron(w.Cron)
+		if err != nil {
+			return fmt.Errorf("ThroughputSchedule window %q: %w", w.Name, err)
+		}
+		w.schedule = schedule
+		if w.Throughput < minManualThroughput || w.Throughput > maxContainerThroughput {
+			return fmt.Errorf("ThroughputSchedule window %q Throughput must be between %d and %d RU/s (got %d)", w.Name, minManualThroughput, maxContainerThroughput, w.Throughput)
+		}
+	}
+	return nil
+}
+
+// activeThroughputWindow returns the window whose cron fired most recently at or before t, and when it fired.
+func activeThroughputWindow(t time.Time) (throughputWindow, time.Time, error) {
+	var active throughputWindow
+	var since time.Time
+	for _, w := range throughputWindows {
+		if fired, ok := w.schedule.previous(t); ok && fired.After(since) {
+			active, since = w, fired
+		}
+	}
+	if since.IsZero() {
+		return throughputWindow{}, time.Time{}, fmt.Errorf("no ThroughputSchedule window started in the year before %s", t.Format(time.RFC3339))
+	}
+	return active, since, nil
+}
+
+// runScaleScheduleCommand sets the container's throughput to the target of the schedule window that is active now.
+// Run it from cron, a pipeline schedule, or an Azure Automation job as often as the windows change.
+func runScaleScheduleCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("scale-schedule")
+	at := fs.String("at", "", "Evaluate the schedule at this time (RFC 3339) instead of now; implies -dry-run")
+	dryRun := fs.Bool("dry-run", false, "Print the active window and target without changing throughput")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: scale-schedule [-dry-run] [-at <time>]")
+		fmt.Fprintf(fs.Output(), "Applies the ThroughputSchedule window active now to %s/%s.\n", databaseName, containerName)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if len(throughputWindows) == 0 {
+		log.Fatalf("ThroughputSchedule is not set in config.json")
+	}
+
+	now := time.Now().In(scheduleLocation)
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			log.Fatalf("invalid -at %q (expected RFC 3339, for example 2025-01-31T22:00:00Z): %v", *at, err)
+		}
+		now, *dryRun = t.In(scheduleLocation), true
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WINDOW\tCRON\tTHROUGHPUT\tLAST STARTED")
+	for _, w := range throughputWindows {
+		started := "-"
+		if fired, ok := w.schedule.previous(now); ok {
+			started = fired.Format("2006-01-02 15:04 MST")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", w.Name, w.Cron, w.Throughput, started)
+	}
+	_ = tw.Flush()
+
+	active, since, err := activeThroughputWindow(now)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Printf("Active window at %s: %s (since %s), target %d RU/s\n", now.Format("2006-01-02 15:04 MST"), active.Name, since.Format("2006-01-02 15:04 MST"), active.Throughput)
+
+	current, autoscale, err := getContainerThroughput(ctx)
+	if err != nil {
+		log.Fatalf("failed to read container throughput: %v", err)
+	}
+	kind := "manual throughput"
+	if autoscale {
+		kind = "autoscale max throughput"
+	}
+	if int64(active.Throughput) == current {
+		fmt.Printf("Container %s is already %d RU/s; nothing to do.\n", kind, current)
+		return
+	}
+	if *dryRun {
+		fmt.Printf("Would change the container %s from %d to %d RU/s.\n", kind, current, active.Throughput)
+		return
+	}
+	setThroughput(ctx, throughputTarget{database: databaseName, container: containerName}, false, func(int64, bool) int64 {
+		return int64(active.Throughput)
+	})
+}
+
+// getContainerThroughput returns the configured container's autoscale max (and true) or manual throughput.
+func getContainerThroughput(ctx context.Context) (int64, bool, error) {
+	client, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return 0, false, err
+	}
+	existing, err := client.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, databaseName, containerName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == 404 {
+			return 0, false, fmt.Errorf("the container has no dedicated throughput (it uses shared database throughput or the account is serverless)")
+		}
+		return 0, false, err
+	}
+	if existing.Properties == nil || existing.Properties.Resource == nil {
+		return 0, false, fmt.Errorf("the container's throughput settings have no resource payload")
+	}
+	r := existing.Properties.Resource
+	if r.AutoscaleSettings != nil && r.AutoscaleSettings.MaxThroughput != nil {
+		return int64(*r.AutoscaleSettings.MaxThroughput), true, nil
+	}
+	if r.Throughput == nil {
+		return 0, false, nil
+	}
+	return int64(*r.Throughput), false, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/spf13/viper"
+)
+
+// keyVaultReferencePrefix marks a setting whose value is read from Key Vault at startup, in the App Service syntax:
+// @Microsoft.KeyVault(SecretUri=https://<vault>.vault.azure.net/secrets/<name>[/<version>]) or
+// @Microsoft.KeyVault(VaultName=<vault>;SecretName=<name>[;SecretVersion=<version>]).
+const keyVaultReferencePrefix = "@Microsoft.KeyVault("
+
+var (
+	// keyVaultSecrets caches resolved references by secret URL, so loading the configuration again (promote) doesn't
+	// read them twice.
+	keyVaultSecrets = map[string]string{}
+	// keyVaultSettings are the settings whose values came from Key Vault; they are never printed.
+	keyVaultSettings = map[string]bool{}
+)
+
+// keyVaultReference is the secret a Key Vault reference points to. An empty version means the latest.
+type keyVaultReference struct {
+	vaultURL string
+	name     string
+	version  string
+}
+
+// String returns the secret URL.
+func (r keyVaultReference) String() string {
+	u := r.vaultURL + "/secrets/" + url.PathEscape(r.name)
+	if r.version != "" {
+		u += "/" + url.PathEscape(r.version)
+	}
+	return u
+}
+
+// resolveKeyVaultReferences replaces every setting that is a Key Vault reference with the secret's value. That
+// includes settings in nested objects (such as Tags) and entries of lists of strings. Profiles are skipped until one
+// is applied.
+func resolveKeyVaultReferences(ctx context.Context) error {
+	clients := map[string]*azsecrets.Client{}
+	resolve := func(key string, value string) (string, error) {
+		if !strings.HasPrefix(value, keyVaultReferencePrefix) {
+			return value, nil
+		}
+		ref, err := parseKeyVaultReference(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+		if secret, ok := keyVaultSecrets[ref.String()]; ok {
+			return secret, nil
+		}
+		client, ok := clients[ref.vaultURL]
+		if !ok {
+			client, err = azsecrets.NewClient(ref.vaultURL, credential, &azsecrets.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: azureCloud}})
+			if err != nil {
+				return "", fmt.Errorf("failed to create Key Vault client for %s: %w", ref.vaultURL, err)
+			}
+			clients[ref.vaultURL] = client
+		}
+		resp, err := client.GetSecret(ctx, ref.name, ref.version, nil)
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to read %s: %w", key, ref, err)
+		}
+		secret := stringValue(resp.Value)
+		keyVaultSecrets[ref.String()] = secret
+		return secret, nil
+	}
+
+	resolved := 0
+	for _, key := range viper.AllKeys() {
+		if strings.HasPrefix(key, "profiles.") {
+			continue
+		}
+		switch value := viper.Get(key).(type) {
+		case string:
+			secret, err := resolve(key, value)
+			if err != nil {
+				return err
+			}
+			if secret != value {
+				viper.Set(key, secret)
+				keyVaultSettings[key] = true
+				resolved++
+			}
+		case []any:
+			changed := false
+			for i, item := range value {
+				s, ok := item.(string)
+				if !ok {
+					continue
+				}
+				secret, err := resolve(fmt.Sprintf("%s[%d]", key, i), s)
+				if err != nil {
+					return err
+				}
+				if secret != s {
+					value[i], changed = secret, true
+				}
+			}
+			if changed {
+				viper.Set(key, value)
+				keyVaultSettings[key] = true
+				resolved++
+			}
+		}
+	}
+	if resolved > 0 {
+		log.Printf("Read %d setting(s) from Key Vault", resolved)
+	}
+	return nil
+}
+
+// parseKeyVaultReference returns the secret a Key Vault reference points to.
+func parseKeyVaultReference(reference string) (keyVaultReference, error) {
+	body, ok := strings.CutSuffix(strings.TrimPrefix(reference, keyVaultReferencePrefix), ")")
+	if !ok {
+		return keyVaultReference{}, fmt.Errorf("Key Vault reference %q is missing its closing parenthesis", reference)
+	}
+	parts := map[string]string{}
+	for _, part := range strings.Split(body, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return keyVaultReference{}, fmt.Errorf("Key Vault reference %q: expected Name=value, got %q", reference, part)
+		}
+		parts[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	if uri := parts["secreturi"]; uri != "" {
+		u, err := url.Parse(strings.TrimSuffix(uri, "/"))
+		segments := []string{}
+		if err == nil {
+			segments = strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+		}
+		if err != nil || u.Scheme != "https" || u.Host == "" || len(segments) < 2 || len(segments) > 3 || segments[0] != "secrets" || segments[1] == "" {
+			return keyVaultReference{}, fmt.Errorf("SecretUri %q must look like https://<vault>.%s/secrets/<name>[/<version>]", uri, keyVaultDNSSuffix())
+		}
+		ref := keyVaultReference{vaultURL: "https://" + u.Host, name: segments[1]}
+		if len(segments) == 3 {
+			ref.version = segments[2]
+		}
+		return ref, nil
+	}
+	if parts["vaultname"] == "" || parts["secretname"] == "" {
+		return keyVaultReference{}, fmt.Errorf("Key Vault reference %q needs SecretUri, or VaultName and SecretName", reference)
+	}
+	return keyVaultReference{
+		vaultURL: fmt.Sprintf("https://%s.%s", parts["vaultname"], keyVaultDNSSuffix()),
+		name:     parts["secretname"],
+		version:  parts["secretversion"],
+	}, nil
+}
+
+// keyVaultDNSSuffix returns the Key Vault DNS suffix for the configured cloud.
+func keyVaultDNSSuffix() string {
+	switch azureCloud.ActiveDirectoryAuthorityHost {
+	case cloud.AzureChina.ActiveDirectoryAuthorityHost:
+		return "vault.azure.cn"
+	case cloud.AzureGovernment.ActiveDirectoryAuthorityHost:
+		return "vault.usgovcloudapi.net"
+	}
+	return "vault.azure.net"
+}
+
+// fromKeyVault reports whether a setting, or any setting nested in it, was read from Key Vault.
+func fromKeyVault(key string) bool {
+	key = strings.ToLower(key)
+	for setting := range keyVaultSettings {
+		if setting == key || strings.HasPrefix(setting, key+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	seedCompanies             = 5
+	seedDepartmentsPerCompany = 4
+	seedUsersPerDepartment    = 25
+	seedWorkers               = 8
+)
+
+var seedDepartments = []string{"engineering", "sales", "marketing", "finance", "support", "operations"}
+
+// seedSampleDocuments upserts count synthetic documents spread across the container's hierarchical partition key
+// (/companyId, /departmentId, /userId). Document ids are deterministic, so seeding again replaces the same documents.
+func seedSampleDocuments(ctx context.Context, count int) {
+	log.Printf("Seeding %d sample documents into %s/%s", count, databaseName, containerName)
+
+	containerClient, err := getDataPlaneContainerClient(ctx)
+	if err != nil {
+		log.Fatalf("failed to create cosmos db data-plane client: %v", err)
+	}
+
+	// The first write waits for the SQL role assignment to propagate, so the workers don't all hit 403s.
+	if err := upsertSampleItemWithRBACRetry(ctx, containerClient, newSeedItem(0)); err != nil {
+		log.Fatalf("failed to seed sample document: %v", err)
+	}
+
+	var written atomic.Int64
+	written.Add(1)
+	errs := make(chan error, seedWorkers)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range seedWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range next {
+				if err := upsertSampleItem(ctx, containerClient, newSeedItem(n)); err != nil {
+					errs <- err
+					return
+				}
+				if w := written.Add(1); w%500 == 0 {
+					log.Printf("Seeded %d/%d documents", w, count)
+				}
+			}
+		}()
+	}
+
+	for n := 1; n < count; n++ {
+		select {
+		case next <- n:
+		case err := <-errs:
+			log.Fatalf("failed to seed sample document: %v", err)
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		log.Fatalf("failed to seed sample document: %v", err)
+	default:
+	}
+
+	fmt.Printf("Seeded %d sample documents into %s/%s\n", written.Load(), databaseName, containerName)
+}
+
+// newSeedItem returns the n-th synthetic document. Documents cycle through a fixed set of companies, departments,
+// and users, so partitions at every level of the hierarchical key hold several documents.
+func newSeedItem(n int) sampleItem {
+	company := n % seedCompanies
+	department := (n / seedCompanies) % seedDepartmentsPerCompany
+	user := (n / (seedCompanies * seedDepartmentsPerCompany)) % seedUsersPerDepartment
+
+	return sampleItem{
+		ID:           fmt.Sprintf("seed-%06d", n),
+		CompanyID:    fmt.Sprintf("company-%02d", company+1),
+		DepartmentID: seedDepartments[(company+department)%len(seedDepartments)],
+		UserID:       fmt.Sprintf("user-%02d-%d-%03d", company+1, department+1, user+1),
+		Message:      fmt.Sprintf("Sample document %d", n),
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// regionalService is the per-region state of an account service.
+type regionalService struct {
+	location string
+	status   string
+	endpoint string
+}
+
+// runServicesCommand lists, shows, or deletes the account's services (dedicated gateway, data transfer, graph compute, materialized views builder).
+func runServicesCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("services")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: services [list | get <name> | delete <name>]")
+		fmt.Fprintln(fs.Output(), "Service names match their type: SqlDedicatedGateway, DataTransfer, GraphAPICompute, MaterializedViewsBuilder.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	serviceClient, err := armcosmos.NewServiceClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db service client: %v", err)
+	}
+
+	switch fs.Arg(0) {
+	case "", "list":
+		listAccountServices(ctx, serviceClient)
+	case "get":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		getAccountService(ctx, serviceClient, fs.Arg(1))
+	case "delete":
+		if fs.NArg() < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		deleteAccountService(ctx, serviceClient, fs.Arg(1))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// listAccountServices prints every service provisioned on the account.
+func listAccountServices(ctx context.Context, serviceClient *armcosmos.ServiceClient) {
+	services := make([]*armcosmos.ServiceResource, 0)
+	pager := serviceClient.NewListPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list services: %v", err)
+		}
+		for _, s := range page.Value {
+			if s != nil && s.Properties != nil {
+				services = append(services, s)
+			}
+		}
+	}
+
+	if len(services) == 0 {
+		fmt.Printf("No services are provisioned on account %s.\n", accountName)
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tSTATUS\tSIZE\tINSTANCES\tCREATED (UTC)")
+	for _, s := range services {
+		p := s.Properties.GetServiceResourceProperties()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", stringValue(s.Name), enumValue(p.ServiceType), enumValue(p.Status), enumValue(p.InstanceSize), int32Value(p.InstanceCount), timeValue(p.CreationTime))
+	}
+	_ = tw.Flush()
+}
+
+// getAccountService prints one service and its regional instances.
+func getAccountService(ctx context.Context, serviceClient *armcosmos.ServiceClient, serviceName string) {
+	resp, err := serviceClient.Get(ctx, resourceGroupName, accountName, serviceName, nil)
+	if err != nil {
+		log.Fatalf("failed to get service %s: %v", serviceName, err)
+	}
+	if resp.Properties == nil {
+		log.Fatalf("service %s returned no properties", serviceName)
+	}
+
+	p := resp.Properties.GetServiceResourceProperties()
+	fmt.Printf("Service:   %s\n", stringValue(resp.Name))
+	fmt.Printf("Type:      %s\n", enumValue(p.ServiceType))
+	fmt.Printf("Status:    %s\n", enumValue(p.Status))
+	fmt.Printf("Size:      %s\n", enumValue(p.InstanceSize))
+	fmt.Printf("Instances: %s\n", int32Value(p.InstanceCount))
+	fmt.Printf("Created:   %s\n", timeValue(p.CreationTime))
+
+	regions := regionalServices(resp.Properties)
+	if len(regions) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tSTATUS\tENDPOINT")
+	for _, r := range regions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.location, r.status, r.endpoint)
+	}
+	_ = tw.Flush()
+}
+
+// deleteAccountService deprovisions a service from the account.
+func deleteAccountService(ctx context.Context, serviceClient *armcosmos.ServiceClient, serviceName string) {
+	log.Printf("Starting service delete (this can take several minutes): service=%s", serviceName)
+
+	pollerResp, err := serviceClient.BeginDelete(ctx, resourceGroupName, accountName, serviceName, nil)
+	if err != nil {
+		log.Fatalf("failed to begin delete service: %v", err)
+	}
+
+	if _, err := pollUntilDone(ctx, pollerResp); err != nil {
+		log.Fatalf("failed to delete service: %v", err)
+	}
+
+	fmt.Printf("Deleted Service: %s\n", serviceName)
+}
+
+// regionalServices returns the per-region instances of a service, including the regional endpoint when the service type has one.
+func regionalServices(properties armcosmos.ServiceResourcePropertiesClassification) []regionalService {
+	regions := make([]regionalService, 0)
+	switch p := properties.(type) {
+	case *armcosmos.SQLDedicatedGatewayServiceResourceProperties:
+		for _, l := range p.Locations {
+			if l != nil {
+				regions = append(regions, regionalService{stringValue(l.Location), enumValue(l.Status), stringValue(l.SQLDedicatedGatewayEndpoint)})
+			}
+		}
+	case *armcosmos.GraphAPIComputeServiceResourceProperties:
+		for _, l := range p.Locations {
+			if l != nil {
+				regions = append(regions, regionalService{stringValue(l.Location), enumValue(l.Status), stringValue(l.GraphAPIComputeEndpoint)})
+			}
+		}
+	case *armcosmos.DataTransferServiceResourceProperties:
+		for _, l := range p.Locations {
+			if l != nil {
+				regions = append(regions, regionalService{stringValue(l.Location), enumValue(l.Status), ""})
+			}
+		}
+	case *armcosmos.MaterializedViewsBuilderServiceResourceProperties:
+		for _, l := range p.Locations {
+			if l != nil {
+				regions = append(regions, regionalService{stringValue(l.Location), enumValue(l.Status), ""})
+			}
+		}
+	}
+	return regions
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func enumValue[T ~string](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func int32Value(v *int32) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+func timeValue(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.UTC().Format(time.DateTime)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// runRoleAssignmentsCommand lists the SQL role assignments granted to one principal at any scope in the account.
+func runRoleAssignmentsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("role-assignments")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: role-assignments [<principal object id> | me]")
+		fmt.Fprintln(fs.Output(), "Lists the Cosmos DB SQL role assignments granted to the principal (default: me, the signed-in principal).")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	principalID := fs.Arg(0)
+	if principalID == "" || strings.EqualFold(principalID, "me") {
+		id, err := getCurrentPrincipalObjectID(ctx)
+		if err != nil {
+			log.Fatalf("failed to get current user principal ID: %v", err)
+		}
+		principalID = id
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role assignment client: %v", err)
+	}
+
+	assignments, err := listSQLRoleAssignments(ctx, sqlClient)
+	if err != nil {
+		log.Fatalf("failed to list SQL role assignments: %v", err)
+	}
+	roleNames, err := getSQLRoleDefinitionNames(ctx, sqlClient)
+	if err != nil {
+		log.Fatalf("failed to list SQL role definitions: %v", err)
+	}
+
+	matched := make([]*armcosmos.SQLRoleAssignmentGetResults, 0)
+	for _, a := range assignments {
+		if strings.EqualFold(stringValue(a.Properties.PrincipalID), principalID) {
+			matched = append(matched, a)
+		}
+	}
+
+	if len(matched) == 0 {
+		fmt.Printf("No SQL role assignments on account %s for principal %s.\n", accountName, principalID)
+		return
+	}
+
+	fmt.Printf("SQL role assignments on account %s for principal %s:\n", accountName, principalID)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE\tSCOPE\tASSIGNMENT")
+	for _, a := range matched {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", sqlRoleName(roleNames, stringValue(a.Properties.RoleDefinitionID)), relativeSQLScope(stringValue(a.Properties.Scope)), stringValue(a.Name))
+	}
+	_ = tw.Flush()
+}
+
+// listSQLRoleAssignments returns every SQL role assignment on the account, at any scope.
+func listSQLRoleAssignments(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient) ([]*armcosmos.SQLRoleAssignmentGetResults, error) {
+	assignments := make([]*armcosmos.SQLRoleAssignmentGetResults, 0)
+	pager := sqlClient.NewListSQLRoleAssignmentsPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page.Value {
+			if a != nil && a.Properties != nil {
+				assignments = append(assignments, a)
+			}
+		}
+	}
+	return assignments, nil
+}
+
+// getSQLRoleDefinitionNames maps the account's SQL role definition IDs (built-in and custom) to their role names.
+func getSQLRoleDefinitionNames(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient) (map[string]string, error) {
+	names := map[string]string{}
+	pager := sqlClient.NewListSQLRoleDefinitionsPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page.Value {
+			if d != nil && d.ID != nil && d.Properties != nil && d.Properties.RoleName != nil {
+				names[strings.ToLower(*d.ID)] = *d.Properties.RoleName
+			}
+		}
+	}
+	return names, nil
+}
+
+// sqlRoleName returns the role name for a role definition ID, or the ID's last segment when it isn't known.
+func sqlRoleName(names map[string]string, roleDefinitionID string) string {
+	if name, ok := names[strings.ToLower(roleDefinitionID)]; ok {
+		return name
+	}
+	return roleDefinitionID[strings.LastIndex(roleDefinitionID, "/")+1:]
+}
+
+// relativeSQLScope shortens an assignment scope to its path within the account ("/" for the whole account).
+func relativeSQLScope(scope string) string {
+	return relativeScope(scope, getAssignableScope(Account))
+}
+
+// runOrphanedRoleAssignmentsCommand reports (or with -delete, removes) SQL role assignments whose principals no longer
+// exist in Entra ID.
+func runOrphanedRoleAssignmentsCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("orphaned-role-assignments")
+	deleteOrphans := fs.Bool("delete", false, "Delete the orphaned role assignments instead of only reporting them")
+	_ = fs.Parse(args)
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role assignment client: %v", err)
+	}
+
+	orphans, err := findOrphanedSQLRoleAssignments(ctx, sqlClient)
+	if err != nil {
+		log.Fatalf("failed to find orphaned SQL role assignments: %v", err)
+	}
+	if len(orphans) == 0 {
+		fmt.Printf("No orphaned SQL role assignments on account %s.\n", accountName)
+		return
+	}
+
+	roleNames, err := getSQLRoleDefinitionNames(ctx, sqlClient)
+	if err != nil {
+		log.Fatalf("failed to list SQL role definitions: %v", err)
+	}
+
+	fmt.Printf("Found %d SQL role assignment(s) on account %s whose principal no longer exists in Entra ID:\n", len(orphans), accountName)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PRINCIPAL\tROLE\tSCOPE\tASSIGNMENT")
+	for _, a := range orphans {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", stringValue(a.Properties.PrincipalID), sqlRoleName(roleNames, stringValue(a.Properties.RoleDefinitionID)), relativeSQLScope(stringValue(a.Properties.Scope)), stringValue(a.Name))
+	}
+	_ = tw.Flush()
+
+	if !*deleteOrphans {
+		fmt.Println("Run with -delete to remove them.")
+		return
+	}
+
+	for _, a := range orphans {
+		pollerResp, err := sqlClient.BeginDeleteSQLRoleAssignment(ctx, stringValue(a.Name), resourceGroupName, accountName, nil)
+		if err != nil {
+			log.Fatalf("failed to delete role assignment %s: %v", stringValue(a.Name), err)
+		}
+		if _, err := pollUntilDone(ctx, pollerResp); err != nil {
+			log.Fatalf("failed to poll the result: %v", err)
+		}
+		fmt.Printf("Deleted orphaned SQL role assignment: %s\n", stringValue(a.ID))
+	}
+}
+
+// findOrphanedSQLRoleAssignments returns the account's SQL role assignments whose principal IDs are not found in
+// Entra ID. The signed-in principal is looked up alongside them; if it isn't found either, the directory lookup is
+// not trustworthy (for example, missing Graph permissions) and an error is returned instead of a list of everything.
+func findOrphanedSQLRoleAssignments(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient) ([]*armcosmos.SQLRoleAssignmentGetResults, error) {
+	assignments, err := listSQLRoleAssignments(ctx, sqlClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SQL role assignments: %w", err)
+	}
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+
+	self, err := getCurrentPrincipalObjectID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user principal ID: %w", err)
+	}
+
+	seen := map[string]bool{strings.ToLower(self): true}
+	principalIDs := []string{self}
+	for _, a := range assignments {
+		id := strings.ToLower(stringValue(a.Properties.PrincipalID))
+		if id != "" && !seen[id] {
+			seen[id] = true
+			principalIDs = append(principalIDs, id)
+		}
+	}
+
+	graph, err := newGraphClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Microsoft Graph client: %w", err)
+	}
+	existing, err := graph.existingPrincipals(ctx, principalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up principals in Entra ID: %w", err)
+	}
+	if _, ok := existing[strings.ToLower(self)]; !ok {
+		return nil, fmt.Errorf("the signed-in principal %s was not found in Entra ID; the identity may lack permission to read directory objects", self)
+	}
+
+	orphans := make([]*armcosmos.SQLRoleAssignmentGetResults, 0)
+	for _, a := range assignments {
+		if _, ok := existing[strings.ToLower(stringValue(a.Properties.PrincipalID))]; !ok {
+			orphans = append(orphans, a)
+		}
+	}
+	return orphans, nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// sqlRBACExport is the JSON file written by sql-rbac export and read by sql-rbac import.
+type sqlRBACExport struct {
+	Account         string                  `json:"account"`
+	ExportedAt      time.Time               `json:"exportedAt"`
+	RoleDefinitions []sqlRBACRoleDefinition `json:"roleDefinitions"`
+	RoleAssignments []sqlRBACRoleAssignment `json:"roleAssignments"`
+}
+
+// sqlRBACRoleDefinition is a custom SQL role definition. Built-in roles exist in every account and aren't exported.
+type sqlRBACRoleDefinition struct {
+	Name             string              `json:"name"`
+	RoleName         string              `json:"roleName"`
+	AssignableScopes []string            `json:"assignableScopes"`
+	Permissions      []sqlRBACPermission `json:"permissions"`
+}
+
+type sqlRBACPermission struct {
+	DataActions    []string `json:"dataActions,omitempty"`
+	NotDataActions []string `json:"notDataActions,omitempty"`
+}
+
+// sqlRBACRoleAssignment is one SQL role assignment. RoleDefinitionID and Scope are full resource IDs in the exported
+// account.
+type sqlRBACRoleAssignment struct {
+	Name             string `json:"name"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	Scope            string `json:"scope"`
+	PrincipalID      string `json:"principalId"`
+}
+
+// scopeMappings is the repeatable -map-scope flag: old=new prefix replacements applied to every scope and role
+// definition ID in an import.
+type scopeMappings [][2]string
+
+func (m *scopeMappings) String() string {
+	parts := make([]string, 0, len(*m))
+	for _, pair := range *m {
+		parts = append(parts, pair[0]+"="+pair[1])
+	}
+	return strings.Join(parts, ",")
+}
+
+func (m *scopeMappings) Set(value string) error {
+	old, replacement, ok := strings.Cut(value, "=")
+	if !ok || old == "" {
+		return fmt.Errorf("expected <old prefix>=<new prefix>, for example /dbs/orders=/dbs/orders-v2")
+	}
+	*m = append(*m, [2]string{old, replacement})
+	return nil
+}
+
+// apply replaces the first matching prefix (compared case-insensitively, as ARM does).
+func (m scopeMappings) apply(value string) string {
+	for _, pair := range m {
+		if len(value) >= len(pair[0]) && strings.EqualFold(value[:len(pair[0])], pair[0]) {
+			return pair[1] + value[len(pair[0]):]
+		}
+	}
+	return value
+}
+
+// runSQLRBACCommand backs up an account's SQL role definitions and assignments to JSON, or applies such a backup to
+// the same or another account.
+func runSQLRBACCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("sql-rbac")
+	file := fs.String("file", "", "JSON file to write (export; default stdout) or read (import)")
+	targetAccount := fs.String("account", accountName, "Account to import into")
+	targetGroup := fs.String("resource-group", resourceGroupName, "Resource group of the account to import into")
+	yes := fs.Bool("yes", false, "Apply the import; without it, import only prints what it would change")
+	var mappings scopeMappings
+	fs.Var(&mappings, "map-scope", "Replace a scope prefix on import, as <old>=<new> relative to the account (repeatable), for example /dbs/orders=/dbs/orders-v2")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sql-rbac [-file <json>] export")
+		fmt.Fprintln(fs.Output(), "       sql-rbac -file <json> [-account <name>] [-resource-group <name>] [-map-scope <old>=<new>]... [-yes] import")
+		fmt.Fprintln(fs.Output(), "Exports the custom SQL role definitions and every SQL role assignment of the account, or re-applies an export.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create role assignment client: %v", err)
+	}
+	switch fs.Arg(0) {
+	case "export":
+		exportSQLRBAC(ctx, sqlClient, *file)
+	case "import":
+		if *file == "" {
+			fs.Usage()
+			os.Exit(2)
+		}
+		importSQLRBAC(ctx, sqlClient, *file, *targetGroup, *targetAccount, mappings, *yes)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// exportSQLRBAC writes the account's custom role definitions and all its role assignments as JSON.
+func exportSQLRBAC(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, file string) {
+	export, err := readSQLRBAC(ctx, sqlClient, resourceGroupName, accountName)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to encode SQL RBAC export: %v", err)
+	}
+	if file == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("failed to write %s: %v", file, err)
+	}
+	fmt.Printf("Exported %d custom role definition(s) and %d role assignment(s) of %s to %s\n", len(export.RoleDefinitions), len(export.RoleAssignments), accountName, file)
+}
+
+// readSQLRBAC reads an account's custom role definitions and all its role assignments.
+func readSQLRBAC(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, group string, account string) (sqlRBACExport, error) {
+	export := sqlRBACExport{Account: sqlAccountID(group, account), ExportedAt: time.Now().UTC()}
+
+	definitions := sqlClient.NewListSQLRoleDefinitionsPager(group, account, nil)
+	for definitions.More() {
+		page, err := definitions.NextPage(ctx)
+		if err != nil {
+			return export, fmt.Errorf("failed to list SQL role definitions of %s: %w", account, err)
+		}
+		for _, d := range page.Value {
+			if d == nil || d.Properties == nil || d.Properties.Type == nil || *d.Properties.Type != armcosmos.RoleDefinitionTypeCustomRole {
+				continue
+			}
+			definition := sqlRBACRoleDefinition{Name: stringValue(d.Name), RoleName: stringValue(d.Properties.RoleName)}
+			for _, scope := range d.Properties.AssignableScopes {
+				definition.AssignableScopes = append(definition.AssignableScopes, stringValue(scope))
+			}
+			for _, p := range d.Properties.Permissions {
+				if p == nil {
+					continue
+				}
+				permission := sqlRBACPermission{}
+				for _, a := range p.DataActions {
+					permission.DataActions = append(permission.DataActions, stringValue(a))
+				}
+				for _, a := range p.NotDataActions {
+					permission.NotDataActions = append(permission.NotDataActions, stringValue(a))
+				}
+				definition.Permissions = append(definition.Permissions, permission)
+			}
+			export.RoleDefinitions = append(export.RoleDefinitions, definition)
+		}
+	}
+
+	assignments := sqlClient.NewListSQLRoleAssignmentsPager(group, account, nil)
+	for assignments.More() {
+		page, err := assignments.NextPage(ctx)
+		if err != nil {
+			return export, fmt.Errorf("failed to list SQL role assignments of %s: %w", account, err)
+		}
+		for _, a := range page.Value {
+			if a == nil || a.Properties == nil {
+				continue
+			}
+			export.RoleAssignments = append(export.RoleAssignments, sqlRBACRoleAssignment{
+				Name:             stringValue(a.Name),
+				RoleDefinitionID: stringValue(a.Properties.RoleDefinitionID),
+				Scope:            stringValue(a.Properties.Scope),
+				PrincipalID:      stringValue(a.Properties.PrincipalID),
+			})
+		}
+	}
+	return export, nil
+}
+
+// importSQLRBAC applies an export file to the target account.
+func importSQLRBAC(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, file string, group string, account string, mappings scopeMappings, yes bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", file, err)
+	}
+	var export sqlRBACExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		log.Fatalf("failed to parse %s: %v", file, err)
+	}
+	if export.Account == "" {
+		log.Fatalf("%s has no account; was it written by sql-rbac export?", file)
+	}
+	applySQLRBAC(ctx, sqlClient, export, group, account, mappings, yes)
+}
+
+// applySQLRBAC creates the exported role definitions and assignments in the target account. Scopes and role
+// definition IDs are moved from the exported account to the target, then through the -map-scope prefixes. A custom
+// role whose name already exists in the target is updated in place, and an assignment of the same role to the same
+// principal at the same scope is left alone, so it can be re-run.
+func applySQLRBAC(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, export sqlRBACExport, group string, account string, mappings scopeMappings, yes bool) {
+	target := sqlAccountID(group, account)
+	remap := func(id string) string {
+		id = scopeMappings{{export.Account, target}}.apply(id)
+		if rest, ok := strings.CutPrefix(id, target); ok {
+			return target + mappings.apply(rest)
+		}
+		return id
+	}
+
+	existingDefinitions := map[string]string{}
+	roleNames := map[string]string{}
+	definitions := sqlClient.NewListSQLRoleDefinitionsPager(group, account, nil)
+	for definitions.More() {
+		page, err := definitions.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list SQL role definitions of %s: %v", account, err)
+		}
+		for _, d := range page.Value {
+			if d != nil && d.Properties != nil && d.Properties.RoleName != nil {
+				existingDefinitions[strings.ToLower(*d.Properties.RoleName)] = stringValue(d.Name)
+				roleNames[strings.ToLower(stringValue(d.ID))] = *d.Properties.RoleName
+			}
+		}
+	}
+	existingAssignments := map[string]bool{}
+	assignments := sqlClient.NewListSQLRoleAssignmentsPager(group, account, nil)
+	for assignments.More() {
+		page, err := assignments.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list SQL role assignments of %s: %v", account, err)
+		}
+		for _, a := range page.Value {
+			if a != nil && a.Properties != nil {
+				existingAssignments[sqlRoleAssignmentKey(stringValue(a.Properties.RoleDefinitionID), stringValue(a.Properties.Scope), stringValue(a.Properties.PrincipalID))] = true
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tROLE\tSCOPE\tPRINCIPAL\tACTION")
+
+	// Role definitions first: assignments refer to them, possibly under the target's existing ID for the same name.
+	definitionIDs := map[string]string{}
+	for _, d := range export.RoleDefinitions {
+		name, action := d.Name, "create"
+		if existing, ok := existingDefinitions[strings.ToLower(d.RoleName)]; ok {
+			name, action = existing, "update"
+		}
+		definitionIDs[strings.ToLower(export.Account+"/sqlRoleDefinitions/"+d.Name)] = target + "/sqlRoleDefinitions/" + name
+		roleNames[strings.ToLower(target+"/sqlRoleDefinitions/"+name)] = d.RoleName
+		scopes := make([]string, 0, len(d.AssignableScopes))
+		for _, scope := range d.AssignableScopes {
+			scopes = append(scopes, remap(scope))
+		}
+		fmt.Fprintf(tw, "definition\t%s\t%s\t-\t%s\n", d.RoleName, strings.Join(scopes, ", "), action)
+		if !yes {
+			continue
+		}
+
+		params := armcosmos.SQLRoleDefinitionCreateUpdateParameters{Properties: &armcosmos.SQLRoleDefinitionResource{
+			RoleName:         to.Ptr(d.RoleName),
+			Type:             to.Ptr(armcosmos.RoleDefinitionTypeCustomRole),
+			AssignableScopes: to.SliceOfPtrs(scopes...),
+		}}
+		for _, p := range d.Permissions {
+			params.Properties.Permissions = append(params.Properties.Permissions, &armcosmos.Permission{
+				DataActions:    to.SliceOfPtrs(p.DataActions...),
+				NotDataActions: to.SliceOfPtrs(p.NotDataActions...),
+			})
+		}
+		poller, err := sqlClient.BeginCreateUpdateSQLRoleDefinition(ctx, name, group, account, params, nil)
+		if err != nil {
+			log.Fatalf("failed to create or update role definition %s: %v", d.RoleName, err)
+		}
+		if _, err := pollUntilDone(ctx, poller); err != nil {
+			log.Fatalf("failed to create or update role definition %s: %v", d.RoleName, err)
+		}
+	}
+
+	created, skipped := 0, 0
+	for _, a := range export.RoleAssignments {
+		roleDefinitionID, ok := definitionIDs[strings.ToLower(a.RoleDefinitionID)]
+		if !ok {
+			roleDefinitionID = remap(a.RoleDefinitionID)
+		}
+		scope := remap(a.Scope)
+		role := sqlRoleName(roleNames, roleDefinitionID)
+		if existingAssignments[sqlRoleAssignmentKey(roleDefinitionID, scope, a.PrincipalID)] {
+			fmt.Fprintf(tw, "assignment\t%s\t%s\t%s\texists\n", role, relativeScope(scope, target), a.PrincipalID)
+			skipped++
+			continue
+		}
+		fmt.Fprintf(tw, "assignment\t%s\t%s\t%s\tcreate\n", role, relativeScope(scope, target), a.PrincipalID)
+		created++
+		if !yes {
+			continue
+		}
+
+		// Reuse the exported name when importing into the same account, so a re-run updates instead of duplicating.
+		name := a.Name
+		if !strings.EqualFold(export.Account, target) {
+			name = uuid5Name(fmt.Sprintf("%s|%s|%s", scope, roleDefinitionID, a.PrincipalID))
+		}
+		params := armcosmos.SQLRoleAssignmentCreateUpdateParameters{Properties: &armcosmos.SQLRoleAssignmentResource{
+			RoleDefinitionID: to.Ptr(roleDefinitionID),
+			Scope:            to.Ptr(scope),
+			PrincipalID:      to.Ptr(a.PrincipalID),
+		}}
+		poller, err := sqlClient.BeginCreateUpdateSQLRoleAssignment(ctx, name, group, account, params, nil)
+		if err != nil {
+			log.Fatalf("failed to create role assignment for %s at %s: %v", a.PrincipalID, scope, err)
+		}
+		if _, err := pollUntilDone(ctx, poller); err != nil {
+			log.Fatalf("failed to create role assignment for %s at %s: %v", a.PrincipalID, scope, err)
+		}
+	}
+	_ = tw.Flush()
+
+	if !yes {
+		fmt.Printf("Would apply %d role definition(s) and create %d role assignment(s) in %s (%d already exist). Run with -yes to apply.\n", len(export.RoleDefinitions), created, account, skipped)
+		return
+	}
+	fmt.Printf("Applied %d role definition(s) and created %d role assignment(s) in %s (%d already existed).\n", len(export.RoleDefinitions), created, account, skipped)
+}
+
+// sqlAccountID is the resource ID of an account in the configured subscription, the root of its SQL RBAC scopes.
+func sqlAccountID(group string, account string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.DocumentDB/databaseAccounts/%s", subscriptionID, group, account)
+}
+
+// sqlRoleAssignmentKey identifies an assignment by what it grants, ignoring its name.
+func sqlRoleAssignmentKey(roleDefinitionID string, scope string, principalID string) string {
+	return strings.ToLower(roleDefinitionID + "|" + scope + "|" + principalID)
+}
+
+// relativeScope shortens a scope to its path within the account ("/" for the whole account).
+func relativeScope(scope string, account string) string {
+	if rest, ok := strings.CutPrefix(strings.ToLower(scope), strings.ToLower(account)); ok {
+		if rest == "" {
+			return "/"
+		}
+		return scope[len(scope)-len(rest):]
+	}
+	return scope
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
+)
+
+// statusRow is one line of the status table.
+type statusRow struct {
+	kind       string
+	name       string
+	state      string
+	throughput string
+	details    string
+}
+
+// runStatusCommand prints the provisioning state, throughput mode, and key settings of the account and everything
+// the sample manages in it, without changing anything.
+func runStatusCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("status")
+	_ = fs.Parse(args)
+
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db account client: %v", err)
+	}
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql client: %v", err)
+	}
+
+	account, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			fmt.Printf("Account %s does not exist in resource group %s.\n", accountName, resourceGroupName)
+			return
+		}
+		log.Fatalf("failed to get cosmos db account: %v", err)
+	}
+
+	rows := accountStatusRows(account.DatabaseAccountGetResults)
+	serverless := isServerless(account.DatabaseAccountGetResults)
+
+	databasePager := sqlClient.NewListSQLDatabasesPager(resourceGroupName, accountName, nil)
+	for databasePager.More() {
+		page, err := databasePager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list databases: %v", err)
+		}
+		for _, db := range page.Value {
+			if db == nil || db.Name == nil {
+				continue
+			}
+			rows = append(rows, statusRow{kind: "Database", name: *db.Name, state: "Exists", throughput: databaseThroughputStatus(ctx, sqlClient, *db.Name, serverless)})
+			rows = append(rows, containerStatusRows(ctx, sqlClient, *db.Name, serverless)...)
+		}
+	}
+
+	rows = append(rows, roleStatusRows(ctx, sqlClient)...)
+	rows = append(rows, lockStatusRow(ctx))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tNAME\tSTATE\tTHROUGHPUT\tDETAILS")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.kind, r.name, orDash(r.state), orDash(r.throughput), r.details)
+	}
+	_ = tw.Flush()
+}
+
+// accountStatusRows returns the account row and one row per region.
+func accountStatusRows(account armcosmos.DatabaseAccountGetResults) []statusRow {
+	p := account.Properties
+	if p == nil {
+		return []statusRow{{kind: "Account", name: accountName}}
+	}
+
+	details := make([]string, 0, 4)
+	if p.ConsistencyPolicy != nil && p.ConsistencyPolicy.DefaultConsistencyLevel != nil {
+		details = append(details, "consistency="+string(*p.ConsistencyPolicy.DefaultConsistencyLevel))
+	}
+	if p.DisableLocalAuth != nil && *p.DisableLocalAuth {
+		details = append(details, "localAuth=disabled")
+	} else {
+		details = append(details, "localAuth=enabled")
+	}
+	details = append(details, "publicNetworkAccess="+enumValue(p.PublicNetworkAccess))
+	if _, ok := p.BackupPolicy.(*armcosmos.ContinuousModeBackupPolicy); ok {
+		details = append(details, "backup=Continuous")
+	} else {
+		details = append(details, "backup=Periodic")
+	}
+
+	throughput := "provisioned"
+	if isServerless(account) {
+		throughput = "serverless"
+	}
+	rows := []statusRow{{kind: "Account", name: accountName, state: stringValue(p.ProvisioningState), throughput: throughput, details: strings.Join(details, " ")}}
+
+	writeRegions := map[string]bool{}
+	for _, l := range p.WriteLocations {
+		if l != nil {
+			writeRegions[stringValue(l.LocationName)] = true
+		}
+	}
+	for _, l := range p.Locations {
+		if l == nil {
+			continue
+		}
+		role := "read"
+		if writeRegions[stringValue(l.LocationName)] {
+			role = "write"
+		}
+		rows = append(rows, statusRow{
+			kind:    "Region",
+			name:    stringValue(l.LocationName),
+			state:   stringValue(l.ProvisioningState),
+			details: fmt.Sprintf("role=%s failoverPriority=%s zoneRedundant=%t", role, int32Value(l.FailoverPriority), l.IsZoneRedundant != nil && *l.IsZoneRedundant),
+		})
+	}
+	return rows
+}
+
+// containerStatusRows returns one row per container in the database.
+func containerStatusRows(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, database string, serverless bool) []statusRow {
+	rows := make([]statusRow, 0)
+	pager := sqlClient.NewListSQLContainersPager(resourceGroupName, accountName, database, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list containers in %s: %v", database, err)
+		}
+		for _, c := range page.Value {
+			if c == nil || c.Name == nil {
+				continue
+			}
+			details := make([]string, 0, 2)
+			if c.Properties != nil && c.Properties.Resource != nil {
+				r := c.Properties.Resource
+				if r.PartitionKey != nil {
+					paths := make([]string, 0, len(r.PartitionKey.Paths))
+					for _, p := range r.PartitionKey.Paths {
+						paths = append(paths, stringValue(p))
+					}
+					details = append(details, "partitionKey="+strings.Join(paths, ","))
+				}
+				if r.DefaultTTL != nil {
+					details = append(details, fmt.Sprintf("defaultTtl=%d", *r.DefaultTTL))
+				}
+			}
+			rows = append(rows, statusRow{
+				kind:       "Container",
+				name:       database + "/" + *c.Name,
+				state:      "Exists",
+				throughput: containerThroughputStatus(ctx, sqlClient, database, *c.Name, serverless),
+				details:    strings.Join(details, " "),
+			})
+		}
+	}
+	return rows
+}
+
+// roleStatusRows returns one row per custom SQL role definition and per SQL role assignment.
+func roleStatusRows(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient) []statusRow {
+	rows := make([]statusRow, 0)
+	roleNames, err := getSQLRoleDefinitionNames(ctx, sqlClient)
+	if err != nil {
+		log.Printf("Could not list SQL role definitions: %v", err)
+	}
+
+	definitions := sqlClient.NewListSQLRoleDefinitionsPager(resourceGroupName, accountName, nil)
+	for definitions.More() {
+		page, err := definitions.NextPage(ctx)
+		if err != nil {
+			log.Printf("Could not list SQL role definitions: %v", err)
+			break
+		}
+		for _, d := range page.Value {
+			if d == nil || d.Properties == nil || d.Properties.Type == nil || *d.Properties.Type != armcosmos.RoleDefinitionTypeCustomRole {
+				continue
+			}
+			rows = append(rows, statusRow{kind: "SQLRoleDefinition", name: stringValue(d.Properties.RoleName), state: "Exists", details: "id=" + stringValue(d.Name)})
+		}
+	}
+
+	assignments, err := listSQLRoleAssignments(ctx, sqlClient)
+	if err != nil {
+		log.Printf("Could not list SQL role assignments: %v", err)
+		return rows
+	}
+	for _, a := range assignments {
+		rows = append(rows, statusRow{
+			kind:    "SQLRoleAssignment",
+			name:    sqlRoleName(roleNames, stringValue(a.Properties.RoleDefinitionID)),
+			state:   "Exists",
+			details: fmt.Sprintf("principal=%s scope=%s", stringValue(a.Properties.PrincipalID), relativeSQLScope(stringValue(a.Properties.Scope))),
+		})
+	}
+	return rows
+}
+
+// lockStatusRow reports whether the sample's management lock is on the account.
+func lockStatusRow(ctx context.Context) statusRow {
+	row := statusRow{kind: "Lock", name: accountLockName}
+	locksClient, err := armlocks.NewManagementLocksClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create management locks client: %v", err)
+	}
+	lock, err := locksClient.GetByScope(ctx, getAssignableScope(Account), accountLockName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			row.state = "NotFound"
+		} else {
+			row.state = "Unknown"
+			row.details = err.Error()
+		}
+		return row
+	}
+	row.state = "Exists"
+	if lock.Properties != nil {
+		row.details = "level=" + enumValue(lock.Properties.Level)
+	}
+	return row
+}
+
+func databaseThroughputStatus(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, database string, serverless bool) string {
+	if serverless {
+		return "serverless"
+	}
+	resp, err := sqlClient.GetSQLDatabaseThroughput(ctx, resourceGroupName, accountName, database, nil)
+	if err != nil {
+		return throughputErrorStatus(err, "none (per container)")
+	}
+	return describeThroughputSettings(resp.Properties)
+}
+
+func containerThroughputStatus(ctx context.Context, sqlClient *armcosmos.SQLResourcesClient, database string, container string, serverless bool) string {
+	if serverless {
+		return "serverless"
+	}
+	resp, err := sqlClient.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, database, container, nil)
+	if err != nil {
+		return throughputErrorStatus(err, "shared (database)")
+	}
+	return describeThroughputSettings(resp.Properties)
+}
+
+// throughputErrorStatus maps a 404 from a throughput read to notFound, and other errors to "unknown".
+func throughputErrorStatus(err error, notFound string) string {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return notFound
+	}
+	return "unknown"
+}
+
+// describeThroughputSettings formats throughput settings as "autoscale max N", or "manual N", marking pending scale
+// operations.
+func describeThroughputSettings(p *armcosmos.ThroughputSettingsGetProperties) string {
+	if p == nil || p.Resource == nil {
+		return ""
+	}
+	r := p.Resource
+	s := ""
+	switch {
+	case r.AutoscaleSettings != nil && r.AutoscaleSettings.MaxThroughput != nil:
+		s = fmt.Sprintf("autoscale max %d", *r.AutoscaleSettings.MaxThroughput)
+	case r.Throughput != nil:
+		s = fmt.Sprintf("manual %d", *r.Throughput)
+	}
+	if strings.EqualFold(stringValue(r.OfferReplacePending), "true") {
+		s += " (scaling)"
+	}
+	return s
+}
+
+// isServerless reports whether the account has the EnableServerless capability.
+func isServerless(account armcosmos.DatabaseAccountGetResults) bool {
+	if account.Properties == nil {
+		return false
+	}
+	for _, c := range account.Properties.Capabilities {
+		if c != nil && strings.EqualFold(stringValue(c.Name), "EnableServerless") {
+			return true
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// runSummary is the machine-readable result of a full run, written with -output json.
+type runSummary struct {
+	RunID           string                  `json:"runId"`
+	SubscriptionID  string                  `json:"subscriptionId"`
+	ResourceGroup   string                  `json:"resourceGroup"`
+	Account         string                  `json:"account"`
+	StartedAt       time.Time               `json:"startedAt"`
+	CompletedAt     time.Time               `json:"completedAt"`
+	Resources       []summaryResource       `json:"resources"`
+	RoleAssignments []summaryRoleAssignment `json:"roleAssignments"`
+	Endpoints       *summaryEndpoints       `json:"endpoints,omitempty"`
+	Throughput      *summaryThroughput      `json:"throughput,omitempty"`
+	Restore         *summaryRestore         `json:"restore,omitempty"`
+	Throttling      summaryThrottling       `json:"throttling"`
+}
+
+type summaryResource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type summaryRoleAssignment struct {
+	Kind             string `json:"kind"`
+	ID               string `json:"id,omitempty"`
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	PrincipalID      string `json:"principalId"`
+	Scope            string `json:"scope"`
+}
+
+type summaryEndpoints struct {
+	Document         string                    `json:"document,omitempty"`
+	Write            []summaryRegionalEndpoint `json:"write,omitempty"`
+	Read             []summaryRegionalEndpoint `json:"read,omitempty"`
+	DedicatedGateway string                    `json:"dedicatedGateway,omitempty"`
+}
+
+type summaryRegionalEndpoint struct {
+	Region   string `json:"region"`
+	Endpoint string `json:"endpoint"`
+}
+
+type summaryThroughput struct {
+	Database               string `json:"database"`
+	Container              string `json:"container,omitempty"`
+	AutoscaleMaxThroughput *int32 `json:"autoscaleMaxThroughput,omitempty"`
+	ManualThroughput       *int32 `json:"manualThroughput,omitempty"`
+}
+
+type summaryRestore struct {
+	InstanceID           string     `json:"instanceId"`
+	BackupMode           string     `json:"backupMode"`
+	OldestRestorableTime *time.Time `json:"oldestRestorableTime,omitempty"`
+}
+
+type summaryThrottling struct {
+	ThrottledRequests int     `json:"throttledRequests"`
+	RetryAfterSeconds float64 `json:"retryAfterSeconds"`
+}
+
+var (
+	summary = runSummary{StartedAt: time.Now().UTC()}
+
+	// summaryOut receives the JSON summary. With -output json and no -output-file it is the original stdout, and
+	// os.Stdout is pointed at stderr so the human-readable progress doesn't mix with the JSON.
+	summaryOut io.Writer
+)
+
+// configureOutput validates the -output flags and, for JSON on stdout, moves regular output to stderr.
+func configureOutput() {
+	switch *outputFormat {
+	case "text":
+		return
+	case "json":
+	default:
+		log.Fatalf("invalid -output %q (expected text or json)", *outputFormat)
+	}
+
+	if *outputFile != "" {
+		return
+	}
+	summaryOut = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// recordResource adds a created or updated ARM resource to the run summary.
+func recordResource(resourceType string, id *string) {
+	if id == nil {
+		return
+	}
+	summary.Resources = append(summary.Resources, summaryResource{Type: resourceType, ID: *id})
+}
+
+// recordRoleAssignment adds an Azure RBAC or Cosmos DB SQL RBAC role assignment to the run summary.
+func recordRoleAssignment(kind string, id string, roleDefinitionID string, principalID string, scope string) {
+	summary.RoleAssignments = append(summary.RoleAssignments, summaryRoleAssignment{
+		Kind:             kind,
+		ID:               id,
+		RoleDefinitionID: roleDefinitionID,
+		PrincipalID:      principalID,
+		Scope:            scope,
+	})
+}
+
+// writeRunSummary prints ARM throttling statistics and writes the run summary as JSON when -output json is set.
+func writeRunSummary() {
+	printThrottlingSummary()
+	if *outputFormat != "json" {
+		return
+	}
+
+	summary.SubscriptionID = subscriptionID
+	summary.ResourceGroup = resourceGroupName
+	summary.Account = accountName
+	summary.CompletedAt = time.Now().UTC()
+	throttled, waited := armThrottling.snapshot()
+	summary.Throttling = summaryThrottling{ThrottledRequests: throttled, RetryAfterSeconds: waited.Seconds()}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		log.Fatalf("failed to encode run summary: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *outputFile != "" {
+		if err := os.WriteFile(*outputFile, data, 0o644); err != nil {
+			log.Fatalf("failed to write run summary: %v", err)
+		}
+		fmt.Printf("Wrote run summary: %s\n", *outputFile)
+		return
+	}
+	if _, err := summaryOut.Write(data); err != nil {
+		log.Fatalf("failed to write run summary: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// standardTagNames are the tags every sample resource carries. owner defaults to the signed-in identity; the others
+// come from the Tags setting.
+var standardTagNames = []string{"owner", "environment", "costCenter"}
+
+var (
+	// configuredTags are the Tags setting: the standard tags and any others to set on every resource.
+	configuredTags map[string]string
+	// requiredTags must have a non-empty value before the sample creates anything.
+	requiredTags []string
+)
+
+// loadTagSettings reads Tags and RequiredTags. Tag names follow ARM's rules, and the run ID tag is reserved.
+func loadTagSettings() error {
+	configuredTags = map[string]string{}
+	for name, value := range viper.GetStringMapString("Tags") {
+		// Viper lower-cases keys, so map the standard tags back to their documented casing.
+		for _, standard := range standardTagNames {
+			if strings.EqualFold(name, standard) {
+				name = standard
+			}
+		}
+		if err := validateTag(name, value); err != nil {
+			return err
+		}
+		// Empty values are placeholders (as in config.json.sample) rather than tags to set.
+		if value = strings.TrimSpace(value); value != "" {
+			configuredTags[name] = value
+		}
+	}
+
+	requiredTags = nil
+	for _, raw := range viper.GetStringSlice("RequiredTags") {
+		// Match the lower-cased names of Tags.
+		name := strings.ToLower(strings.TrimSpace(raw))
+		for _, standard := range standardTagNames {
+			if strings.EqualFold(name, standard) {
+				name = standard
+			}
+		}
+		if name == "" || strings.EqualFold(name, runIDTagName) {
+			return fmt.Errorf("RequiredTags entry %q is not a tag the sample can require", raw)
+		}
+		// owner can still come from the signed-in identity; it is checked when the tags are built.
+		if name != "owner" && configuredTags[name] == "" {
+			return fmt.Errorf("tag %q is required (RequiredTags) but has no value in Tags", name)
+		}
+		requiredTags = append(requiredTags, name)
+	}
+	return nil
+}
+
+// validateTag checks a tag against ARM's limits, so a bad tag fails before any resource is created rather than on
+// whichever create happens to send it first.
+func validateTag(name string, value string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("Tags has an empty tag name")
+	case strings.EqualFold(name, runIDTagName):
+		return fmt.Errorf("tag %q is set from the run ID (-run-id) and can't be configured in Tags", runIDTagName)
+	case len(name) > 512:
+		return fmt.Errorf("tag name %q is longer than 512 characters", name)
+	case strings.ContainsAny(name, `<>%&\?/`):
+		return fmt.Errorf("tag name %q contains one of the characters < > %% & \\ ? /", name)
+	case len(value) > 256:
+		return fmt.Errorf("the value of tag %q is longer than 256 characters", name)
+	}
+	return nil
+}
+
+// buildResourceTags returns the configured tags plus owner and the run ID, or an error when a required tag is empty.
+func buildResourceTags(owner string) (map[string]string, error) {
+	tags := make(map[string]string, len(configuredTags)+2)
+	for name, value := range configuredTags {
+		tags[name] = value
+	}
+	if tags["owner"] == "" {
+		tags["owner"] = owner
+	}
+	tags[runIDTagName] = runID
+
+	// ARM allows at most 50 tags per resource.
+	if len(tags) > 50 {
+		return nil, fmt.Errorf("%d tags configured; Azure resources allow at most 50", len(tags))
+	}
+	var missing []string
+	for _, name := range requiredTags {
+		if tags[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("required tag(s) %s have no value; set them in Tags", strings.Join(missing, ", "))
+	}
+	return tags, nil
+}
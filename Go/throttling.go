@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	// ARM can ask callers to wait several minutes when a subscription exceeds its request quota. The SDK default
+	// (60s) gives up on longer Retry-After values, so allow waits of up to five minutes and a few more attempts.
+	armMaxRetries    = 6
+	armMaxRetryDelay = 5 * time.Minute
+)
+
+// throttlingStats counts ARM 429 responses seen across every management client in the run.
+type throttlingStats struct {
+	mu              sync.Mutex
+	throttled       int
+	totalRetryAfter time.Duration
+	byOperation     map[string]int
+}
+
+var armThrottling = &throttlingStats{byOperation: map[string]int{}}
+
+// throttlingPolicy logs each throttled ARM request and records it. It runs once per attempt, so the SDK retry
+// policy (which honors Retry-After) does the waiting.
+type throttlingPolicy struct{}
+
+func (throttlingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	retryAfter := parseRetryAfter(resp.Header)
+	operation := req.Raw().Method + " " + req.Raw().URL.Path
+	armThrottling.record(operation, retryAfter)
+	log.Printf("ARM throttled request (429): %s; retry after %s (remaining reads=%s, writes=%s)",
+		operation,
+		retryAfter,
+		headerOrUnknown(resp.Header, "x-ms-ratelimit-remaining-subscription-reads"),
+		headerOrUnknown(resp.Header, "x-ms-ratelimit-remaining-subscription-writes"),
+	)
+	return resp, err
+}
+
+func (s *throttlingStats) record(operation string, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled++
+	s.totalRetryAfter += retryAfter
+	s.byOperation[operation]++
+}
+
+// snapshot returns the throttled request count and total Retry-After wait so far.
+func (s *throttlingStats) snapshot() (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.throttled, s.totalRetryAfter
+}
+
+// printThrottlingSummary prints how often ARM throttled the run, if at all.
+func printThrottlingSummary() {
+	throttled, waited := armThrottling.snapshot()
+	if throttled == 0 {
+		return
+	}
+	fmt.Printf("ARM throttling: %d request(s) returned 429; waited %s in total for Retry-After.\n", throttled, waited)
+	armThrottling.mu.Lock()
+	defer armThrottling.mu.Unlock()
+	for operation, count := range armThrottling.byOperation {
+		fmt.Printf("  %dx %s\n", count, operation)
+	}
+}
+
+// describeThrottlingError adds context to an error caused by ARM throttling that outlasted the retries.
+func describeThrottlingError(err error) error {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("ARM kept throttling the request (429) after %d retries; wait a few minutes or reduce concurrent management operations on the subscription: %w", armMaxRetries, err)
+	}
+	return err
+}
+
+// parseRetryAfter reads the delay from retry-after-ms, x-ms-retry-after-ms, or Retry-After (seconds or HTTP date).
+func parseRetryAfter(h http.Header) time.Duration {
+	for _, name := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if ms, err := strconv.Atoi(h.Get(name)); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	v := h.Get("Retry-After")
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return time.Until(at).Round(time.Second)
+	}
+	return 0
+}
+
+func headerOrUnknown(h http.Header, name string) string {
+	if v := h.Get(name); v != "" {
+		return v
+	}
+	return "?"
+}
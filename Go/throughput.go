@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/AzureCosmosDB/management-sdk-samples/Go/to"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+const (
+	minAutoscaleMaxThroughput = 1000
+	minManualThroughput       = 400
+)
+
+// validateMaxAutoScaleThroughput checks the MaxAutoScaleThroughput setting against the autoscale bounds.
+func validateMaxAutoScaleThroughput(v int) error {
+	if v < minAutoscaleMaxThroughput || v > maxContainerThroughput {
+		return fmt.Errorf("MaxAutoScaleThroughput must be between %d and %d RU/s (got %d)", minAutoscaleMaxThroughput, maxContainerThroughput, v)
+	}
+	if v%1000 != 0 {
+		return fmt.Errorf("MaxAutoScaleThroughput must be a multiple of 1000 (got %d)", v)
+	}
+	return nil
+}
+
+// checkScaleDown refuses a throughput update that lowers the current value unless -allow-scale-down is set. Lowering
+// throughput is easy to do by accident (for example a negative delta), can throttle a busy workload, and can't go below
+// the minimum Cosmos DB derives from the container's storage and past throughput.
+func checkScaleDown(kind string, current int64, requested int64) error {
+	if requested >= current {
+		return nil
+	}
+	fmt.Printf("Requested %s (%d RU/s) is lower than the current value (%d RU/s).\n", kind, requested, current)
+	if !*allowScaleDown {
+		return fmt.Errorf("refusing to lower %s from %d to %d RU/s; re-run with -allow-scale-down to confirm", kind, current, requested)
+	}
+	return nil
+}
+
+// validateThroughputUpdate checks a new autoscale max or manual throughput against the current settings before it is
+// submitted, so the user gets a clear error instead of a BadRequest from ARM. Cosmos DB only allows lowering
+// throughput to a tenth of the current value (and not below the storage-based minimum it reports).
+func validateThroughputUpdate(current *armcosmos.ThroughputSettingsGetPropertiesResource, currentValue int64, newValue int64, autoscale bool) error {
+	kind, floor, step := "manual throughput", int64(minManualThroughput), int64(100)
+	if autoscale {
+		kind, floor, step = "autoscale max throughput", int64(minAutoscaleMaxThroughput), int64(1000)
+	}
+
+	if newValue < floor {
+		return fmt.Errorf("%s of %d RU/s is below the minimum of %d RU/s", kind, newValue, floor)
+	}
+	if newValue%step != 0 {
+		return fmt.Errorf("%s must be a multiple of %d RU/s (got %d)", kind, step, newValue)
+	}
+	if currentValue > 0 && newValue*10 < currentValue {
+		return fmt.Errorf("%s can't be lowered from %d to %d RU/s: Cosmos DB only allows scaling down to a tenth of the current value (%d RU/s) in one step", kind, currentValue, newValue, (currentValue+9)/10)
+	}
+	if minimum, err := strconv.ParseInt(stringValue(current.MinimumThroughput), 10, 64); err == nil && newValue < minimum {
+		return fmt.Errorf("%s of %d RU/s is below the container's current minimum of %d RU/s (based on its storage and the highest throughput it has had)", kind, newValue, minimum)
+	}
+	if maximum, err := strconv.ParseInt(stringValue(current.SoftAllowedMaximumThroughput), 10, 64); err == nil && newValue > maximum {
+		return fmt.Errorf("%s of %d RU/s is above the allowed maximum of %d RU/s; request a limit increase to go higher", kind, newValue, maximum)
+	}
+	return nil
+}
+
+// throughputTarget is a database, or a container in it, with dedicated throughput.
+type throughputTarget struct {
+	database  string
+	container string
+}
+
+// String names the target for messages, for example "container orders/items" or "database orders".
+func (t throughputTarget) String() string {
+	if t.container == "" {
+		return "database " + t.database
+	}
+	return "container " + t.database + "/" + t.container
+}
+
+// path is the database name, or database/container, as listed in the inventory and the throughput budget.
+func (t throughputTarget) path() string {
+	if t.container == "" {
+		return t.database
+	}
+	return t.database + "/" + t.container
+}
+
+func (t throughputTarget) get(ctx context.Context, client *armcosmos.SQLResourcesClient) (armcosmos.ThroughputSettingsGetResults, error) {
+	if t.container == "" {
+		resp, err := client.GetSQLDatabaseThroughput(ctx, resourceGroupName, accountName, t.database, nil)
+		return resp.ThroughputSettingsGetResults, err
+	}
+	resp, err := client.GetSQLContainerThroughput(ctx, resourceGroupName, accountName, t.database, t.container, nil)
+	return resp.ThroughputSettingsGetResults, err
+}
+
+// update submits the new throughput settings, conditional on etag (the ETag they were read with), and waits for the
+// operation to finish. If-Match goes on the Begin* call only; polling uses ctx unchanged.
+func (t throughputTarget) update(ctx context.Context, client *armcosmos.SQLResourcesClient, etag *string, params armcosmos.ThroughputSettingsUpdateParameters) (armcosmos.ThroughputSettingsGetResults, error) {
+	if t.container == "" {
+		poller, err := client.BeginUpdateSQLDatabaseThroughput(withIfMatch(ctx, etag), resourceGroupName, accountName, t.database, params, nil)
+		if err != nil {
+			return armcosmos.ThroughputSettingsGetResults{}, err
+		}
+		resp, err := pollUntilDone(ctx, poller)
+		return resp.ThroughputSettingsGetResults, err
+	}
+	poller, err := client.BeginUpdateSQLContainerThroughput(withIfMatch(ctx, etag), resourceGroupName, accountName, t.database, t.container, params, nil)
+	if err != nil {
+		return armcosmos.ThroughputSettingsGetResults{}, err
+	}
+	resp, err := pollUntilDone(ctx, poller)
+	return resp.ThroughputSettingsGetResults, err
+}
+
+// throughputChange is one throughput update: the mode and value before and after, and the settings read back once
+// it finished (nil for a dry run).
+type throughputChange struct {
+	target    throughputTarget
+	kind      string
+	autoscale bool
+	from      int64
+	to        int64
+	id        string
+	applied   *armcosmos.ThroughputSettingsGetPropertiesResource
+}
+
+// setThroughput reads the target's throughput, asks newValue for the autoscale max or manual RU/s to set (current is
+// the autoscale max when autoscale is true), and applies it with the update call that matches the throughput mode.
+// With dryRun it stops after printing the change it would make.
+func setThroughput(ctx context.Context, target throughputTarget, dryRun bool, newValue func(current int64, autoscale bool) int64) {
+	if *createOnly && !dryRun {
+		fmt.Println("Skipping the throughput update (-create-only never changes existing resources).")
+		return
+	}
+
+	log.Printf("Starting throughput update (this can take a couple minutes): account=%s, %s", accountName, target)
+
+	throughputClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create throughput client: %v", err)
+	}
+
+	ledger, err := newThroughputLedger(ctx, throughputClient)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	change, err := applyThroughput(ctx, throughputClient, target, dryRun, ledger, newValue)
+	if err == nil && change.to == change.from {
+		fmt.Printf("The %s %s is already %d RU/s; nothing to do.\n", target, change.kind, change.from)
+		return
+	}
+	if dryRun && change.kind != "" {
+		fmt.Printf("Would change the %s %s from %d to %d RU/s.\n", target, change.kind, change.from, change.to)
+	}
+	var budgetErr *throughputBudgetError
+	if errors.As(err, &budgetErr) {
+		fmt.Print(ledger.breakdown())
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if dryRun {
+		return
+	}
+	fmt.Printf("Updated %s %s from %d to %d: %s\n", target, change.kind, change.from, change.to, change.id)
+
+	var appliedAutoscaleMax any
+	var appliedManual any
+	summary.Throughput = &summaryThroughput{Database: target.database, Container: target.container}
+	if change.applied != nil {
+		if change.applied.AutoscaleSettings != nil && change.applied.AutoscaleSettings.MaxThroughput != nil {
+			appliedAutoscaleMax = *change.applied.AutoscaleSettings.MaxThroughput
+			summary.Throughput.AutoscaleMaxThroughput = change.applied.AutoscaleSettings.MaxThroughput
+		}
+		if change.applied.Throughput != nil {
+			appliedManual = *change.applied.Throughput
+			summary.Throughput.ManualThroughput = change.applied.Throughput
+		}
+	}
+	fmt.Printf("Applied throughput settings: autoscaleMax=%v, manual=%v\n", appliedAutoscaleMax, appliedManual)
+}
+
+// applyThroughput reads the target's throughput, checks the value newValue asks for, and (unless dryRun) applies it
+// and reads the result back. It makes no update when the value doesn't change, and none that ledger's budget doesn't
+// allow. The returned change has the mode and both values once they are known, even on error.
+func applyThroughput(ctx context.Context, client *armcosmos.SQLResourcesClient, target throughputTarget, dryRun bool, ledger *throughputLedger, newValue func(current int64, autoscale bool) int64) (throughputChange, error) {
+	change := throughputChange{target: target}
+	existing, err := target.get(ctx, client)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			if target.container == "" {
+				return change, fmt.Errorf("database throughput settings were not found: the database has no shared throughput (its containers have their own, or the account is serverless); scale a container instead: %w", err)
+			}
+			return change, fmt.Errorf("container throughput settings were not found: the container uses shared database throughput or serverless, so it has no dedicated throughput resource to update; scale the database instead: %w", err)
+		}
+		return change, fmt.Errorf("failed to read existing %s throughput settings: %w", target, err)
+	}
+
+	existingResource := (*armcosmos.ThroughputSettingsGetPropertiesResource)(nil)
+	if existing.Properties != nil {
+		existingResource = existing.Properties.Resource
+	}
+	if existingResource == nil {
+		return change, fmt.Errorf("the %s throughput settings did not include a resource payload; it likely uses shared database throughput or serverless", target)
+	}
+
+	change.kind = "manual throughput"
+	if existingResource.AutoscaleSettings != nil && existingResource.AutoscaleSettings.MaxThroughput != nil {
+		change.kind, change.autoscale, change.from = "autoscale max throughput", true, int64(*existingResource.AutoscaleSettings.MaxThroughput)
+	} else if existingResource.Throughput != nil {
+		change.from = int64(*existingResource.Throughput)
+	}
+	change.to = newValue(change.from, change.autoscale)
+	if change.to == change.from {
+		return change, nil
+	}
+
+	if !dryRun {
+		if err := checkScaleDown(change.kind, change.from, change.to); err != nil {
+			return change, err
+		}
+	}
+	if err := validateThroughputUpdate(existingResource, change.from, change.to, change.autoscale); err != nil {
+		return change, fmt.Errorf("invalid throughput update: %w", err)
+	}
+	if err := ledger.reserve(target.path(), change.to, change.autoscale); err != nil {
+		return change, err
+	}
+	if dryRun {
+		return change, nil
+	}
+
+	throughput := armcosmos.ThroughputSettingsUpdateParameters{
+		Location: &location,
+		Properties: &armcosmos.ThroughputSettingsUpdateProperties{
+			Resource: &armcosmos.ThroughputSettingsResource{},
+		},
+	}
+	if change.autoscale {
+		throughput.Properties.Resource.AutoscaleSettings = &armcosmos.AutoscaleSettingsResource{MaxThroughput: to.Ptr(int32(change.to))}
+	} else {
+		throughput.Properties.Resource.Throughput = to.Ptr(int32(change.to))
+	}
+
+	// If-Match makes the update fail instead of overwriting a throughput change made since the read above.
+	resp, err := target.update(ctx, client, existingResource.Etag, throughput)
+	if err != nil {
+		_ = ledger.reserve(target.path(), change.from, change.autoscale)
+		return change, fmt.Errorf("failed to update throughput: %w", describeConcurrencyError(err, "The "+target.String()+" throughput"))
+	}
+	change.id = stringValue(resp.ID)
+
+	applied, err := target.get(ctx, client)
+	if err != nil {
+		return change, fmt.Errorf("failed to read applied %s throughput settings: %w", target, err)
+	}
+	if applied.Properties != nil {
+		change.applied = applied.Properties.Resource
+	}
+	return change, nil
+}
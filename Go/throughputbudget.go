@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+	"github.com/spf13/viper"
+)
+
+// throughputBudget is the ThroughputBudget setting: the most RU/s (manual RU/s plus autoscale max, as provisioned in
+// each region) the account's databases and containers may have in total. 0 means no budget.
+var throughputBudget int
+
+// loadThroughputBudgetSettings reads ThroughputBudget.
+func loadThroughputBudgetSettings() error {
+	throughputBudget = viper.GetInt("ThroughputBudget")
+	if throughputBudget < 0 {
+		return fmt.Errorf("ThroughputBudget must be 0 (no budget) or a positive number of RU/s (got %d)", throughputBudget)
+	}
+	if throughputBudget > 0 && throughputBudget < minManualThroughput {
+		return fmt.Errorf("ThroughputBudget must be at least %d RU/s, the smallest throughput a resource can have (got %d)", minManualThroughput, throughputBudget)
+	}
+	return nil
+}
+
+// throughputAllocation is one database or container with dedicated throughput.
+type throughputAllocation struct {
+	ru        int64
+	autoscale bool
+}
+
+// throughputLedger tracks the account's throughput allocations against ThroughputBudget while a command creates or
+// scales resources, so several changes in one run (including concurrent ones) are checked together. A nil ledger
+// means no budget is set and allows everything.
+type throughputLedger struct {
+	mu          sync.Mutex
+	allocations map[string]throughputAllocation
+}
+
+// throughputBudgetError is returned for a change that would take the account over ThroughputBudget.
+type throughputBudgetError struct {
+	resource  string
+	requested int64
+	total     int64
+}
+
+func (e *throughputBudgetError) Error() string {
+	return fmt.Sprintf("%s at %d RU/s would bring the account's provisioned throughput to %d RU/s, over the ThroughputBudget of %d RU/s", e.resource, e.requested, e.total, throughputBudget)
+}
+
+// newThroughputLedger reads the current throughput of every database and container in the account. It returns nil
+// when ThroughputBudget isn't set.
+func newThroughputLedger(ctx context.Context, client *armcosmos.SQLResourcesClient) (*throughputLedger, error) {
+	if throughputBudget == 0 {
+		return nil, nil
+	}
+	resources, err := listSQLInventory(ctx, client, resourceGroupName, accountName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the account's throughput for ThroughputBudget: %w", err)
+	}
+	l := &throughputLedger{allocations: map[string]throughputAllocation{}}
+	for _, r := range resources {
+		if ru, autoscale, _, ok := provisionedThroughput(r.throughput); ok {
+			l.allocations[r.name] = throughputAllocation{ru: int64(ru), autoscale: autoscale}
+		}
+	}
+	return l, nil
+}
+
+// reserve records that the database or container at path (database or database/container) will have ru RU/s, unless
+// that takes the account over the budget. Lowering or keeping a resource's throughput is always allowed.
+func (l *throughputLedger) reserve(path string, ru int64, autoscale bool) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	total := ru
+	for name, a := range l.allocations {
+		if name != path {
+			total += a.ru
+		}
+	}
+	if ru > l.allocations[path].ru && total > int64(throughputBudget) {
+		return &throughputBudgetError{resource: path, requested: ru, total: total}
+	}
+	l.allocations[path] = throughputAllocation{ru: ru, autoscale: autoscale}
+	return nil
+}
+
+// reserveNew reserves the throughput of a database or container about to be created, and stops with the current
+// allocations when it would take the account over the budget. Resources without their own throughput (ru 0) pass.
+func (l *throughputLedger) reserveNew(path string, ru int64, autoscale bool) {
+	if ru == 0 {
+		return
+	}
+	if err := l.reserve(path, ru, autoscale); err != nil {
+		fmt.Print(l.breakdown())
+		log.Fatalf("refusing to create %s: %v", path, err)
+	}
+}
+
+// breakdown lists the current allocations, largest first, and their total against the budget.
+func (l *throughputLedger) breakdown() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.allocations))
+	var total int64
+	for name, a := range l.allocations {
+		names = append(names, name)
+		total += a.ru
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := l.allocations[names[i]], l.allocations[names[j]]
+		if a.ru != b.ru {
+			return a.ru > b.ru
+		}
+		return names[i] < names[j]
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Current throughput allocations in %s (ThroughputBudget %d RU/s):\n", accountName, throughputBudget)
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  RESOURCE\tMODE\tRU/S")
+	for _, name := range names {
+		a := l.allocations[name]
+		mode := "manual"
+		if a.autoscale {
+			mode = "autoscale max"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%d\n", name, mode, a.ru)
+	}
+	fmt.Fprintf(tw, "  TOTAL\t\t%d\n", total)
+	_ = tw.Flush()
+	return sb.String()
+}
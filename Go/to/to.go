@@ -1,27 +1,17 @@
+// Package to contains small generic helpers for building the pointer-heavy
+// request models used by the Azure SDK for Go.
 package to
 
-import "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos"
-
-func PublicNetworkAccessPtr(p armcosmos.PublicNetworkAccess) *armcosmos.PublicNetworkAccess {
-	return &p
-}
-
-func StringPtr(s string) *string {
-	return &s
-}
-
-func Int32Ptr(i int32) *int32 {
-	return &i
-}
-
-func BoolPtr(b bool) *bool {
-	return &b
+// Ptr returns a pointer to the provided value.
+func Ptr[T any](v T) *T {
+	return &v
 }
 
-func StringPtrSlice(slice []string) []*string {
-	ptrSlice := make([]*string, len(slice))
-	for i, v := range slice {
-		ptrSlice[i] = StringPtr(v)
+// SliceOfPtrs returns a slice of pointers to copies of the provided values.
+func SliceOfPtrs[T any](values ...T) []*T {
+	ptrs := make([]*T, len(values))
+	for i := range values {
+		ptrs[i] = Ptr(values[i])
 	}
-	return ptrSlice
+	return ptrs
 }
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/tracing"
+	"github.com/Azure/azure-sdk-for-go/sdk/tracing/azotel"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultTracingServiceName = "cosmos-management-sample"
+	// tracingFlushInterval is how often buffered spans are exported, so a run that stops on an error loses little.
+	tracingFlushInterval = 5 * time.Second
+)
+
+var (
+	// tracingEndpoint is the OTLP/HTTP traces URL; "" when tracing is off.
+	tracingEndpoint string
+	// tracerProvider exports the run's spans once startRunTracing has run; nil when tracing is off.
+	tracerProvider *sdktrace.TracerProvider
+	runSpan        trace.Span
+	exportWarning  sync.Once
+)
+
+// loadTracingSettings reads OtlpEndpoint, falling back to the standard OTEL_EXPORTER_OTLP_TRACES_ENDPOINT and
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variables. Tracing is off when none is set.
+func loadTracingSettings() error {
+	tracingEndpoint = ""
+	endpoint := ""
+	// Like the OpenTelemetry SDKs, a base endpoint gets the signal path appended; the traces variable is used as is.
+	switch {
+	case strings.TrimSpace(viper.GetString("OtlpEndpoint")) != "":
+		endpoint = strings.TrimRight(strings.TrimSpace(viper.GetString("OtlpEndpoint")), "/") + "/v1/traces"
+	case strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")) != "":
+		endpoint = strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	case strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")) != "":
+		endpoint = strings.TrimRight(strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")), "/") + "/v1/traces"
+	}
+	if endpoint == "" {
+		return nil
+	}
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("OTLP endpoint %q must be an http or https URL", endpoint)
+	}
+	tracingEndpoint = endpoint
+	return nil
+}
+
+// tracingProvider returns the azcore tracing provider for SDK clients, so every client method (for example
+// DatabaseAccountsClient.BeginCreateOrUpdate) and each of its HTTP attempts gets a span. It is the zero (no-op)
+// provider when tracing is off.
+func tracingProvider() tracing.Provider {
+	if tracerProvider == nil {
+		return tracing.Provider{}
+	}
+	return azotel.NewTracingProvider(tracerProvider, nil)
+}
+
+// tracingPolicy adds what azcore's HTTP spans leave out to the span of the client method: the ARM resource ID and
+// the correlation and routing IDs ARM returns, which are what Azure support asks for. It also sends a W3C traceparent
+// header, which links the request to the span in ARM's own telemetry.
+type tracingPolicy struct{}
+
+func (tracingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	span := trace.SpanFromContext(raw.Context())
+	if !span.IsRecording() {
+		return req.Next()
+	}
+	if id := armResourceIDFromPath(raw.URL.Path); id != "" {
+		span.SetAttributes(attribute.String("az.resource_id", id))
+	}
+	propagation.TraceContext{}.Inject(raw.Context(), propagation.HeaderCarrier(raw.Header))
+
+	resp, err := req.Next()
+	if err != nil {
+		return resp, err
+	}
+	for header, key := range map[string]string{
+		"x-ms-correlation-request-id": "az.correlation_id",
+		"x-ms-routing-request-id":     "az.routing_request_id",
+	} {
+		if v := resp.Header.Get(header); v != "" {
+			span.SetAttributes(attribute.String(key, v))
+		}
+	}
+	return resp, err
+}
+
+// armResourceIDFromPath returns the resource (or resource group or subscription) an ARM request path targets,
+// without a trailing action such as listKeys; "" for non-ARM paths.
+func armResourceIDFromPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || !strings.EqualFold(segments[0], "subscriptions") {
+		return ""
+	}
+	providers := -1
+	for i, s := range segments {
+		if strings.EqualFold(s, "providers") {
+			providers = i
+		}
+	}
+	// Before the provider namespace segments come in type/name pairs; after it too, so an odd count means a
+	// trailing action.
+	keep := len(segments)
+	switch {
+	case providers == -1 && keep%2 == 1:
+		keep--
+	case providers != -1 && (keep-providers-2)%2 == 1:
+		keep--
+	}
+	return "/" + strings.Join(segments[:keep], "/")
+}
+
+// startRunTracing creates the OpenTelemetry tracer provider with an OTLP/HTTP exporter and starts the span that
+// parents every call of the run. It returns ctx unchanged when tracing is off.
+func startRunTracing(ctx context.Context, name string) context.Context {
+	if tracingEndpoint == "" {
+		return ctx
+	}
+	// The exporter also reads OTEL_EXPORTER_OTLP_HEADERS and the other standard OTLP environment variables.
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(tracingEndpoint))
+	if err != nil {
+		log.Printf("Could not create the OTLP exporter for %s; tracing is off: %v", tracingEndpoint, err)
+		return ctx
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", defaultTracingServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		log.Printf("Could not read the OpenTelemetry resource attributes: %v", err)
+	}
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(tracingFlushInterval)),
+		sdktrace.WithResource(res),
+	)
+	// Tracing never fails the run; a failed export is logged once.
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		exportWarning.Do(func() { log.Printf("Could not export spans to %s: %v", tracingEndpoint, err) })
+	}))
+
+	attributes := []attribute.KeyValue{
+		attribute.String("cosmos.sample.run_id", runID),
+		attribute.String("az.subscription_id", subscriptionID),
+	}
+	if accountName != "" {
+		attributes = append(attributes, attribute.String("az.resource_id", getAssignableScope(Account)))
+	}
+	ctx, runSpan = tracerProvider.Tracer(armRestModuleName).Start(ctx, name, trace.WithAttributes(attributes...))
+	log.Printf("Tracing to %s (trace ID %s)", tracingEndpoint, runSpan.SpanContext().TraceID())
+	return ctx
+}
+
+// stopRunTracing ends the run's span and exports every remaining span.
+func stopRunTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	runSpan.End()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = tracerProvider.Shutdown(ctx)
+}
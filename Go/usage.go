@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// containerUsage holds the capacity figures reported for one container.
+type containerUsage struct {
+	name          string
+	dataSize      *float64
+	indexSize     *float64
+	documentCount *float64
+}
+
+// runUsageCommand reports data size, index size, and document count for the containers in the configured database.
+func runUsageCommand(ctx context.Context, args []string) {
+	fs := newCommandFlagSet("usage")
+	container := fs.String("container", "", "Only report this container (default: every container in the database)")
+	_ = fs.Parse(args)
+
+	sqlClient, err := armcosmos.NewSQLResourcesClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db sql resources client: %v", err)
+	}
+	collectionClient, err := armcosmos.NewCollectionClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		log.Fatalf("failed to create cosmos db collection client: %v", err)
+	}
+
+	database, err := sqlClient.GetSQLDatabase(ctx, resourceGroupName, accountName, databaseName, nil)
+	if err != nil {
+		log.Fatalf("failed to get cosmos db database: %v", err)
+	}
+	if database.Properties == nil || database.Properties.Resource == nil || database.Properties.Resource.Rid == nil {
+		log.Fatalf("database %s did not return a resource id (_rid)", databaseName)
+	}
+	databaseRid := *database.Properties.Resource.Rid
+
+	usages := make([]containerUsage, 0)
+	pager := sqlClient.NewListSQLContainersPager(resourceGroupName, accountName, databaseName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Fatalf("failed to list containers: %v", err)
+		}
+		for _, c := range page.Value {
+			if c == nil || c.Properties == nil || c.Properties.Resource == nil || c.Properties.Resource.ID == nil || c.Properties.Resource.Rid == nil {
+				continue
+			}
+			if *container != "" && *c.Properties.Resource.ID != *container {
+				continue
+			}
+
+			usage, err := getContainerUsage(ctx, collectionClient, databaseRid, *c.Properties.Resource.Rid)
+			if err != nil {
+				log.Fatalf("failed to read usage for container %s: %v", *c.Properties.Resource.ID, err)
+			}
+			usage.name = *c.Properties.Resource.ID
+			usages = append(usages, usage)
+		}
+	}
+
+	if len(usages) == 0 {
+		fmt.Printf("No containers found in database %s.\n", databaseName)
+		return
+	}
+
+	fmt.Printf("Capacity usage for database %s\n", databaseName)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTAINER\tDATA SIZE\tINDEX SIZE\tDOCUMENTS")
+	for _, u := range usages {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", u.name, formatBytesPtr(u.dataSize), formatBytesPtr(u.indexSize), formatCountPtr(u.documentCount))
+	}
+	_ = tw.Flush()
+}
+
+// getContainerUsage reads a container's usages, then fills any gaps from the latest collection metrics.
+func getContainerUsage(ctx context.Context, collectionClient *armcosmos.CollectionClient, databaseRid string, collectionRid string) (containerUsage, error) {
+	values := map[string]float64{}
+
+	usagesPager := collectionClient.NewListUsagesPager(resourceGroupName, accountName, databaseRid, collectionRid, nil)
+	for usagesPager.More() {
+		page, err := usagesPager.NextPage(ctx)
+		if err != nil {
+			return containerUsage{}, fmt.Errorf("failed to list collection usages: %w", err)
+		}
+		for _, u := range page.Value {
+			if u == nil || u.Name == nil || u.Name.Value == nil || u.CurrentValue == nil {
+				continue
+			}
+			values[*u.Name.Value] = float64(*u.CurrentValue)
+		}
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-time.Hour)
+	filter := fmt.Sprintf(
+		"(name.value eq 'Data Size' or name.value eq 'Index Size' or name.value eq 'Document Count') and timeGrain eq duration'PT5M' and startTime eq '%s' and endTime eq '%s'",
+		start.Format(time.RFC3339),
+		end.Format(time.RFC3339),
+	)
+	metricsPager := collectionClient.NewListMetricsPager(resourceGroupName, accountName, databaseRid, collectionRid, filter, nil)
+	for metricsPager.More() {
+		page, err := metricsPager.NextPage(ctx)
+		if err != nil {
+			return containerUsage{}, fmt.Errorf("failed to list collection metrics: %w", err)
+		}
+		for _, m := range page.Value {
+			if m == nil || m.Name == nil || m.Name.Value == nil {
+				continue
+			}
+			// Metric values are time-ordered; keep the most recent sample.
+			for _, v := range m.MetricValues {
+				if v != nil && v.Total != nil {
+					values[*m.Name.Value] = *v.Total
+				}
+			}
+		}
+	}
+
+	usage := containerUsage{}
+	if v, ok := values["Data Size"]; ok {
+		usage.dataSize = &v
+	} else if v, ok := values["Storage"]; ok {
+		usage.dataSize = &v
+	}
+	if v, ok := values["Index Size"]; ok {
+		usage.indexSize = &v
+	}
+	if v, ok := values["Document Count"]; ok {
+		usage.documentCount = &v
+	}
+	return usage, nil
+}
+
+// formatBytesPtr formats a byte count using binary units, or "n/a" when unknown.
+func formatBytesPtr(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return formatBytes(*v)
+}
+
+// formatBytes formats a byte count using binary units.
+func formatBytes(v float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+// formatCountPtr formats a count, or "n/a" when unknown.
+func formatCountPtr(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f", *v)
+}
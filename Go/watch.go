@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v3"
+)
+
+// defaultWatchInterval is how often -watch polls the account when PollFrequency isn't set.
+const defaultWatchInterval = 10 * time.Second
+
+// watchAccountUntilSteady polls the account until it and every region report Succeeded (or Online), printing each
+// state change. The account LRO completes once the write region is ready, but regions added in the same update can
+// keep provisioning for a while afterwards. The wait is bounded by OperationTimeout when it is set.
+func watchAccountUntilSteady(ctx context.Context) (armcosmos.DatabaseAccountGetResults, error) {
+	accountClient, err := armcosmos.NewDatabaseAccountsClient(subscriptionID, credential, armClientOptions())
+	if err != nil {
+		return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("failed to create cosmos db account client: %w", err)
+	}
+
+	if operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
+	}
+	interval := defaultWatchInterval
+	if pollFrequency > 0 {
+		interval = pollFrequency
+	}
+
+	fmt.Printf("Watching account %s until all regions report Succeeded...\n", accountName)
+	states := map[string]string{}
+	start := time.Now()
+	for {
+		resp, err := accountClient.Get(ctx, resourceGroupName, accountName, nil)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("account did not reach a steady state within OperationTimeout (%s): %w", operationTimeout, err)
+			}
+			return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("failed to get cosmos db account: %w", err)
+		}
+
+		steady := true
+		for _, r := range accountRegionStates(resp.DatabaseAccountGetResults) {
+			if previous, ok := states[r.name]; !ok || previous != r.state {
+				if ok {
+					fmt.Printf("  [%s] %s: %s -> %s\n", time.Since(start).Round(time.Second), r.name, orDash(previous), orDash(r.state))
+				} else {
+					fmt.Printf("  [%s] %s: %s\n", time.Since(start).Round(time.Second), r.name, orDash(r.state))
+				}
+				states[r.name] = r.state
+			}
+			if !isSteadyState(r.state) {
+				steady = false
+			}
+		}
+		if steady {
+			fmt.Printf("Account %s is in a steady state in all regions (%s).\n", accountName, time.Since(start).Round(time.Second))
+			return resp.DatabaseAccountGetResults, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return armcosmos.DatabaseAccountGetResults{}, fmt.Errorf("account did not reach a steady state within OperationTimeout (%s)", operationTimeout)
+			}
+			return armcosmos.DatabaseAccountGetResults{}, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// regionState is the provisioning state of the account itself or one of its regions.
+type regionState struct {
+	name  string
+	state string
+}
+
+// accountRegionStates returns the account's own provisioning state followed by the state of each region.
+func accountRegionStates(account armcosmos.DatabaseAccountGetResults) []regionState {
+	if account.Properties == nil {
+		return []regionState{{name: "account"}}
+	}
+	states := []regionState{{name: "account", state: stringValue(account.Properties.ProvisioningState)}}
+	for _, l := range account.Properties.Locations {
+		if l == nil {
+			continue
+		}
+		states = append(states, regionState{name: stringValue(l.LocationName), state: stringValue(l.ProvisioningState)})
+	}
+	return states
+}
+
+func isSteadyState(state string) bool {
+	return strings.EqualFold(state, "Succeeded") || strings.EqualFold(state, "Online")
+}